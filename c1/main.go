@@ -1,22 +1,9 @@
 package main
 
-//
-// func main() {
-//
-// 	fmt.Println("hello world")
-//
-// }
+import "fmt"
 
-func aaa() (done func(), err error) {
-	return func() { print("aaa: done") }, nil
-}
+func main() {
 
-func bbb() (done func(), _ error) {
-	done, err := aaa()
-	return func() { print("bbb: surprise!"); done() }, err
-}
+	fmt.Println("hello world")
 
-func main() {
-	done, _ := bbb()
-	done()
-}
\ No newline at end of file
+}
@@ -34,7 +34,7 @@ func TestC1(t *testing.T) {
 switch 语句用于基于不同条件执行不同动作，每一个 case 分支都是唯一的，
 从上直下逐一测试，直到匹配为止。 Golang switch 分支表达式可以是任意类型，
 不限于常量。可省略 break，默认自动终止。
-
+语法（伪代码）：
 switch var1 {
     case val1:
         ...
@@ -44,7 +44,7 @@ switch var1 {
         ...
 }
 
-switch 语句还可以被用于 type-switch 来判断某个 interface 变量中实际存储的变量类型。
+switch 语句还可以被用于 type-switch 来判断某个 interface 变量中实际存储的变量类型，语法（伪代码）：
 switch x.(type){
 	case type:
 	   	statement(s)
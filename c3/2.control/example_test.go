@@ -0,0 +1,81 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package control
+
+import (
+	"fmt"
+)
+
+// for 循环的三种写法
+//
+// 循环语句 for
+//
+// Golang for支持三种循环方式，包括类似 while 的语法。
+//
+//		for init; condition; post { }
+//	    for condition { }
+//	    for { }
+//
+//	    init： 一般为赋值表达式，给控制变量赋初值；
+//	    condition： 关系表达式或逻辑表达式，循环控制条件；
+//	    post： 一般为赋值表达式，给控制变量增量或减量。
+//
+//	    for语句执行过程如下：
+//	    ① 先对表达式 init 赋初值；
+//	    ② 判别赋值表达式 init 是否满足给定 condition 条件，若其值为真，满足循环条件，
+//			则执行循环体内语句，然后执行 post，进入第二次循环，再判别 condition；
+//			否则判断 condition 的值为假，不满足条件，就终止for循环，执行循环体外语句。
+func Example_c3() {
+	/* for 循环 */
+	for i := 0; i < 10; i++ {
+		fmt.Printf("a 的值为: %d\n", i)
+	}
+
+	var b int = 15
+	var a int
+	for a < b {
+		a++
+		fmt.Printf("a 的值为: %d\n", a)
+	}
+
+	n := 5
+	for {
+		if n < 1 {
+			break
+		}
+		fmt.Println(n)
+		n--
+	}
+
+	// Output:
+	// a 的值为: 0
+	// a 的值为: 1
+	// a 的值为: 2
+	// a 的值为: 3
+	// a 的值为: 4
+	// a 的值为: 5
+	// a 的值为: 6
+	// a 的值为: 7
+	// a 的值为: 8
+	// a 的值为: 9
+	// a 的值为: 1
+	// a 的值为: 2
+	// a 的值为: 3
+	// a 的值为: 4
+	// a 的值为: 5
+	// a 的值为: 6
+	// a 的值为: 7
+	// a 的值为: 8
+	// a 的值为: 9
+	// a 的值为: 10
+	// a 的值为: 11
+	// a 的值为: 12
+	// a 的值为: 13
+	// a 的值为: 14
+	// a 的值为: 15
+	// 5
+	// 4
+	// 3
+	// 2
+	// 1
+}
@@ -0,0 +1,13 @@
+package control
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c3/2.control",
+		lesson.Lesson{Name: "TestC1", Title: "if 语句", Tags: []string{"control"}},
+		lesson.Lesson{Name: "TestC2", Title: "switch、type switch 和 fallthrough", Tags: []string{"control", lesson.TagPuzzle}},
+		lesson.Lesson{Name: "TestC3", Title: "for 循环的三种写法", Tags: []string{"control"}},
+		lesson.Lesson{Name: "TestC4", Title: "range 迭代", Tags: []string{"control", "map", lesson.TagUnordered}},
+		lesson.Lesson{Name: "TestC5", Title: "标签、continue 和 goto", Tags: []string{"control", lesson.TagPuzzle}},
+	)
+}
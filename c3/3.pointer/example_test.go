@@ -0,0 +1,59 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package pointer
+
+import (
+	"fmt"
+)
+
+// 指针取值
+//
+// 在对普通变量使用 & 操作符取地址后会获得这个变量的指针，然后可以对指针使用 *操作，也就是指针取值
+func Example_p2() {
+	// 指针取值
+	a := 10
+	b := &a // 取变量a的地址，将指针保存到b中
+	fmt.Printf("type of b:%T\n", b)
+
+	c := *b // 指针取值（根据指针去内存取值）
+
+	fmt.Printf("type of c:%T\n", c)
+	fmt.Printf("value of c:%v\n", c)
+
+	// Output:
+	// type of b:*int
+	// type of c:int
+	// value of c:10
+}
+
+// 指针作为函数参数
+//
+// 取地址操作符 & 和取值操作符 * 是一对互补操作符，& 取出地址，* 根据地址取出地址指向的值。
+//
+//		1.对变量进行取地址（&）操作，可以获得这个变量的指针变量。
+//	    2.指针变量的值是指针地址。
+//	    3.对指针变量进行取值（*）操作，可以获得指针变量指向的原变量的值。
+//
+// 指针在函数上的应用
+func Example_p3() {
+	a := 10
+	modify1(a)
+	fmt.Println(a) // 10
+	modify2(&a)
+	fmt.Println(a) // 100
+
+	// Output:
+	// 10
+	// 100
+}
+
+// 先 new 再赋值
+func Example_p7() {
+	var a *int
+	a = new(int)
+	*a = 10
+	fmt.Println(*a)
+
+	// Output:
+	// 10
+}
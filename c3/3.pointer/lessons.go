@@ -0,0 +1,17 @@
+package pointer
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c3/3.pointer",
+		lesson.Lesson{Name: "TestP1", Title: "取变量的地址", Tags: []string{"pointer", lesson.TagAddress}},
+		lesson.Lesson{Name: "TestP2", Title: "指针取值", Tags: []string{"pointer"}},
+		lesson.Lesson{Name: "TestP3", Title: "指针作为函数参数", Tags: []string{"pointer", "function"}},
+		lesson.Lesson{Name: "TestP4", Title: "空指针", Tags: []string{"pointer"}},
+		lesson.Lesson{Name: "TestP5", Title: "给 nil 指针赋值引发 panic", Tags: []string{"pointer"}, Expect: lesson.Panics},
+		lesson.Lesson{Name: "TestP6", Title: "new 函数", Tags: []string{"pointer"}},
+		lesson.Lesson{Name: "TestP7", Title: "先 new 再赋值", Tags: []string{"pointer"}},
+		lesson.Lesson{Name: "TestP8", Title: "指向指针的指针", Tags: []string{"pointer"}},
+		lesson.Lesson{Name: "TestP9", Title: "练习：交换两个指针指向的值", Tags: []string{"pointer"}, Exercise: true},
+	)
+}
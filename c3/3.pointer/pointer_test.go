@@ -3,6 +3,8 @@ package pointer
 import (
 	"fmt"
 	"testing"
+
+	"study/internal/demo"
 )
 
 /*
@@ -87,9 +89,11 @@ func TestP4(t *testing.T) {
 
 // 执行下面的代码会引发panic，为什么呢？
 func TestP5(t *testing.T) {
-	var a *int
-	*a = 10
-	fmt.Println(*a)
+	demo.Run(t, func() {
+		var a *int
+		*a = 10
+		fmt.Println(*a)
+	}, demo.Panic("invalid memory address or nil pointer dereference"))
 }
 
 /*
@@ -0,0 +1,86 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package arr
+
+import (
+	"fmt"
+)
+
+// 数组的定义和初始化
+//
+// 数组的特性
+//  1. 数组：是同一种数据类型的固定长度的序列。
+//  2. 数组定义：var a [len]int，比如：var a [5]int，数组长度必须是常量，且是类型的组成部分。一旦定义，长度不能变。
+//  3. 长度是数组类型的一部分，因此，var a[5] int和var a[10]int是不同的类型。
+//  4. 数组可以通过下标进行访问，下标是从0开始，最后一个元素下标是：len-1
+//     for i := 0; i < len(a); i++ {
+//     }
+//     for index, v := range a {
+//     }
+//  5. 访问越界，如果下标在数组合法范围之外，则触发访问越界，会panic
+//  6. 数组是值类型，赋值和传参会复制整个数组，而不是指针。因此改变副本的值，不会改变本身的值。
+//     7.支持 "=="、"!=" 操作符，因为内存总是被初始化过的。
+//     8.指针数组 [n]*T，数组指针 *[n]T。
+//     9.[0]T也是一个数组，但是它占用的内存为0
+//
+// 全局：
+func Example_a1() {
+	// 局部：
+	a := [3]int{1, 2}           // 未初始化元素值为 0。
+	b := [...]int{1, 2, 3, 4}   // 通过初始化值确定数组长度。
+	c := [5]int{2: 100, 4: 200} // 使用索引号初始化元素。
+	d := [...]struct {
+		name string
+		age  uint8
+	}{
+		{"user1", 10}, // 可省略元素类型。
+		{"user2", 20}, // 别忘了最后一行的逗号。
+	}
+
+	fmt.Println(arr0, arr1, arr2, str)
+	fmt.Println(a, b, c, d)
+
+	// Output:
+	// [1 2 3 0 0] [1 2 3 4 5] [1 2 3 4 5 6] [   hello world tom]
+	// [1 2 0] [1 2 3 4] [0 0 100 0 200] [{user1 10} {user2 20}]
+}
+
+// 多维数组
+func Example_a2() {
+	a := [2][3]int{{1, 2, 3}, {4, 5, 6}}
+	b := [...][2]int{{1, 1}, {2, 2}, {3, 3}} // 第 2 纬度不能用 "..."。 把它看作 [...]T 这个T必须是确定的类型。所以 第2维 不能被推导
+
+	fmt.Println(arr10, arr11)
+	fmt.Println(a, b)
+
+	// Output:
+	// [[0 0 0] [0 0 0] [0 0 0] [0 0 0] [0 0 0]] [[1 2 3] [7 8 9]]
+	// [[1 2 3] [4 5 6]] [[1 1] [2 2] [3 3]]
+}
+
+// 用数组指针传参
+//
+// 数组拷贝和传参
+func Example_a6() {
+	var arr1 [5]int
+	printArr(&arr1)
+	fmt.Println(arr1)
+
+	arr2 := [...]int{2, 4, 6, 8, 10}
+	printArr(&arr2)
+	fmt.Println(arr2)
+
+	// Output:
+	// 0 10
+	// 1 0
+	// 2 0
+	// 3 0
+	// 4 0
+	// [10 0 0 0 0]
+	// 0 10
+	// 1 4
+	// 2 6
+	// 3 8
+	// 4 10
+	// [10 4 6 8 10]
+}
@@ -0,0 +1,15 @@
+package arr
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c3/4.arr",
+		lesson.Lesson{Name: "Test_A1", Title: "数组的定义和初始化", Tags: []string{"array"}},
+		lesson.Lesson{Name: "Test_A2", Title: "多维数组", Tags: []string{"array"}},
+		lesson.Lesson{Name: "Test_A3", Title: "数组是值类型，传参会复制", Tags: []string{"array", "pointer", lesson.TagAddress}},
+		lesson.Lesson{Name: "Test_A4", Title: "数组的 len 和 cap", Tags: []string{"array"}},
+		lesson.Lesson{Name: "Test_A5", Title: "遍历多维数组", Tags: []string{"array"}},
+		lesson.Lesson{Name: "Test_A6", Title: "用数组指针传参", Tags: []string{"array", "pointer"}},
+		lesson.Lesson{Name: "Test_A7", Title: "练习：找出和为给定值的两个元素下标", Tags: []string{"array"}, Exercise: true},
+	)
+}
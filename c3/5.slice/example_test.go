@@ -0,0 +1,244 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package slice
+
+import (
+	"fmt"
+)
+
+// 声明切片
+//
+// 1. 切片：切片是数组的一个引用，因此切片是引用类型。但自身是结构体，值拷贝传递。
+// 2. 切片的长度可以改变，因此，可以认为切片是一个可变的数组。
+// 3. 切片遍历方式和数组一样，可以用len()求长度。表示可用元素数量，读写操作不能超过该限制。
+// 4. cap可以求出slice最大扩张容量，不能超出数组限制。0 <= len(slice) <= len(array)，其中array是slice引用的数组。
+// 5. 切片的定义：var 变量名 []类型，比如 var str []string  var arr []int。
+// 6. 如果 slice == nil，那么 len、cap 结果都等于 0。
+func Example_s1() {
+	// 1.声明切片
+	var s1 []int
+	if s1 == nil {
+		fmt.Println("是空")
+	} else {
+		fmt.Println("不是空")
+	}
+
+	// 2.:=
+	s2 := []int{}
+
+	// 3.make()
+	var s3 []int = make([]int, 0)
+	fmt.Println(s1, s2, s3)
+
+	// 4.初始化赋值
+	var s4 []int = make([]int, 0, 0)
+	fmt.Println(s4)
+	s5 := []int{1, 2, 3}
+	fmt.Println(s5)
+
+	// 5.从数组切片
+	arr := [5]int{1, 2, 3, 4, 5}
+	var s6 []int
+
+	// 前包后不包
+	s6 = arr[1:4]
+	fmt.Println(s6)
+
+	// Output:
+	// 是空
+	// [] [] []
+	// []
+	// [1 2 3]
+	// [2 3 4]
+}
+
+// 从数组创建切片
+//
+// 通过数组来初始化切片
+func Example_s2() {
+	fmt.Printf("全局变量：arr %v\n", arr)
+	fmt.Printf("全局变量：slice0 %v\n", slice0)
+	fmt.Printf("全局变量：slice1 %v\n", slice1)
+	fmt.Printf("全局变量：slice2 %v\n", slice2)
+	fmt.Printf("全局变量：slice3 %v\n", slice3)
+	fmt.Printf("全局变量：slice4 %v\n", slice4)
+	fmt.Printf("-----------------------------------\n")
+	arr2 := [...]int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}
+	slice5 := arr2[2:8]
+	slice6 := arr2[0:6]          // 可以简写为 slice := arr2[:end]
+	slice7 := arr2[5:10]         // 可以简写为 slice := arr2[start:]
+	slice8 := arr2[0:len(arr2)]  // slice := arr2[:]
+	slice9 := arr2[:len(arr2)-1] // 去掉切片的最后一个元素
+	fmt.Printf("局部变量： arr2 %v\n", arr2)
+	fmt.Printf("局部变量： slice5 %v\n", slice5)
+	fmt.Printf("局部变量： slice6 %v\n", slice6)
+	fmt.Printf("局部变量： slice7 %v\n", slice7)
+	fmt.Printf("局部变量： slice8 %v\n", slice8)
+	fmt.Printf("局部变量： slice9 %v\n", slice9)
+
+	// Output:
+	// 全局变量：arr [0 1 2 3 4 5 6 7 8 9]
+	// 全局变量：slice0 [2 3 4 5 6 7]
+	// 全局变量：slice1 [0 1 2 3 4 5]
+	// 全局变量：slice2 [5 6 7 8 9]
+	// 全局变量：slice3 [0 1 2 3 4 5 6 7 8 9]
+	// 全局变量：slice4 [0 1 2 3 4 5 6 7 8]
+	// -----------------------------------
+	// 局部变量： arr2 [9 8 7 6 5 4 3 2 1 0]
+	// 局部变量： slice5 [7 6 5 4 3 2]
+	// 局部变量： slice6 [9 8 7 6 5 4]
+	// 局部变量： slice7 [4 3 2 1 0]
+	// 局部变量： slice8 [9 8 7 6 5 4 3 2 1 0]
+	// 局部变量： slice9 [9 8 7 6 5 4 3 2 1]
+}
+
+// 用 make 创建切片
+//
+// 通过make来创建切片
+func Example_s3() {
+	slice3 := make([]int, 10)
+	slice4 := make([]int, 10)
+	slice5 := make([]int, 0, 20)
+	fmt.Printf("make局部slice3 ：%v\n", slice3)
+	fmt.Printf("make局部slice4 ：%v\n", slice4)
+	fmt.Printf("make局部slice5 ：%v\n", slice5)
+
+	// Output:
+	// make局部slice3 ：[0 0 0 0 0 0 0 0 0 0]
+	// make局部slice4 ：[0 0 0 0 0 0 0 0 0 0]
+	// make局部slice5 ：[]
+}
+
+// 切片读写的是底层数组
+//
+// 读写操作实际目标是底层数组，只需注意索引号的差别。
+func Example_s4() {
+	data := [...]int{0, 1, 2, 3, 4, 5}
+
+	s := data[2:4]
+	s[0] += 100
+	s[1] += 200
+
+	fmt.Println(s)
+	fmt.Println(data)
+
+	// Output:
+	// [102 203]
+	// [0 1 102 203 4 5]
+}
+
+// 直接创建切片
+//
+// 可直接创建 slice 对象，自动分配底层数组。
+func Example_s5() {
+	s1 := []int{0, 1, 2, 3, 8: 100} // 通过初始化表达式构造，可使用索引号。
+	fmt.Println(s1, len(s1), cap(s1))
+
+	s2 := make([]int, 6, 8) // 使用 make 创建，指定 len 和 cap 值。
+	fmt.Println(s2, len(s2), cap(s2))
+
+	s3 := make([]int, 6) // 省略 cap，相当于 cap = len。
+	fmt.Println(s3, len(s3), cap(s3))
+
+	// Output:
+	// [0 1 2 3 0 0 0 0 100] 9 9
+	// [0 0 0 0 0 0] 6 8
+	// [0 0 0 0 0 0] 6 6
+}
+
+// 用 append 追加元素
+//
+// 用 append 内置函数操作切片（切片追加）
+func Example_s7() {
+	var a = []int{1, 2, 3}
+	fmt.Printf("slice a : %v\n", a)
+
+	var b = []int{4, 5, 6}
+	fmt.Printf("slice b : %v\n", b)
+
+	c := append(a, b...)
+	fmt.Printf("slice c : %v\n", c)
+
+	d := append(c, 7)
+	fmt.Printf("slice d : %v\n", d)
+
+	e := append(d, 8, 9, 10)
+	fmt.Printf("slice e : %v\n", e)
+
+	// Output:
+	// slice a : [1 2 3]
+	// slice b : [4 5 6]
+	// slice c : [1 2 3 4 5 6]
+	// slice d : [1 2 3 4 5 6 7]
+	// slice e : [1 2 3 4 5 6 7 8 9 10]
+}
+
+// 用 copy 拷贝切片
+//
+// 切片拷贝
+func Example_s10() {
+	s1 := []int{1, 2, 3, 4, 5}
+	fmt.Printf("slice s1 : %v\n", s1)
+
+	s2 := make([]int, 10)
+	fmt.Printf("slice s2 : %v\n", s2)
+
+	copy(s2, s1)
+	fmt.Printf("copied slice s1 : %v\n", s1)
+	fmt.Printf("copied slice s2 : %v\n", s2)
+
+	s3 := []int{1, 2, 3}
+	fmt.Printf("slice s3 : %v\n", s3)
+	s3 = s3[0:0]
+	fmt.Printf("slice s3 : %v ,len = %v\n", s3, cap(s3))
+
+	s3 = append(s3, s2...)
+	fmt.Printf("appended slice s3 : %v\n", s3)
+
+	s3 = append(s3, 4, 5, 6)
+	fmt.Printf("last slice s3 : %v\n", s3)
+
+	// Output:
+	// slice s1 : [1 2 3 4 5]
+	// slice s2 : [0 0 0 0 0 0 0 0 0 0]
+	// copied slice s1 : [1 2 3 4 5]
+	// copied slice s2 : [1 2 3 4 5 0 0 0 0 0]
+	// slice s3 : [1 2 3]
+	// slice s3 : [] ,len = 3
+	// appended slice s3 : [1 2 3 4 5 0 0 0 0 0]
+	// last slice s3 : [1 2 3 4 5 0 0 0 0 0 4 5 6]
+}
+
+// 字符串和切片（string and slice）
+func Example_s11() {
+	str := "Hello world"
+	s1 := str[0:5]
+	fmt.Println(s1)
+
+	s2 := str[6:]
+	fmt.Println(s2)
+
+	// string本身是不可变的，因此要改变string中字符。需要如下操作：
+	s := []byte(str) // 中文字符需要用[]rune(str)
+	s[6] = 'G'
+	s = s[:8]
+	s = append(s, '!')
+	str = string(s)
+	fmt.Println(str)
+
+	// 含有中文字符串
+	str2 := "你好，世界！hello world！"
+	s3 := []rune(str2)
+	s3[3] = '够'
+	s3[4] = '浪'
+	s3[12] = 'g'
+	s3 = s3[:4]
+	str2 = string(s3)
+	fmt.Println(str2)
+
+	// Output:
+	// Hello
+	// world
+	// Hello Go!
+	// 你好，够
+}
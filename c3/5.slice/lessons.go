@@ -0,0 +1,20 @@
+package slice
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c3/5.slice",
+		lesson.Lesson{Name: "Test_S1", Title: "声明切片", Tags: []string{"slice"}},
+		lesson.Lesson{Name: "Test_S2", Title: "从数组创建切片", Tags: []string{"slice", "array"}},
+		lesson.Lesson{Name: "Test_S3", Title: "用 make 创建切片", Tags: []string{"slice"}},
+		lesson.Lesson{Name: "Test_S4", Title: "切片读写的是底层数组", Tags: []string{"slice", "array"}},
+		lesson.Lesson{Name: "Test_S5", Title: "直接创建切片", Tags: []string{"slice"}},
+		lesson.Lesson{Name: "Test_S6", Title: "切片引用底层数组", Tags: []string{"slice", "array", lesson.TagAddress}},
+		lesson.Lesson{Name: "Test_S7", Title: "用 append 追加元素", Tags: []string{"slice", "append"}},
+		lesson.Lesson{Name: "Test_S8", Title: "未超出 cap 时 append 共用底层数组", Tags: []string{"slice", "append", "cap", lesson.TagAddress}},
+		lesson.Lesson{Name: "Test_S9", Title: "超出 cap 时 append 重新分配底层数组", Tags: []string{"slice", "append", "cap", lesson.TagAddress}},
+		lesson.Lesson{Name: "Test_S10", Title: "用 copy 拷贝切片", Tags: []string{"slice"}},
+		lesson.Lesson{Name: "Test_S11", Title: "字符串和切片", Tags: []string{"slice", "string"}},
+		lesson.Lesson{Name: "Test_S12", Title: "练习：原地去除相邻的重复字符串", Tags: []string{"slice"}, Exercise: true},
+	)
+}
@@ -0,0 +1,54 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package function
+
+// 闭包
+//
+// GO 函数的特点：
+//
+//   - 无需声明原型。
+//
+//   - 支持不定 变参。
+//
+//   - 支持多返回值。
+//
+//   - 支持命名返回参数。
+//
+//   - 支持匿名函数和闭包。
+//
+//   - 函数也是一种类型，一个函数可以赋值给变量。
+//
+//   - 不支持 嵌套 (nested) 一个包不能有两个名字一样的函数。
+//
+//   - 不支持 重载 (overload)
+//
+//   - 不支持 默认参数 (default parameter)。
+//
+// 当两个或多个连续的函数命名参数是同一类型，则除了最后一个类型之外，其他都可以省略。
+//
+// 多返回值用括号, 可以命名返回参数
+//
+// 不定参数
+//
+// 命名返回参数可看做与形参类似的局部变量，最后由 return 隐式返回。
+//
+// 匿名函数
+//
+// 闭包
+func Example_f7() {
+	c := a()
+	c()
+	c()
+	c()
+
+	c = a()
+	c()
+	c()
+
+	// Output:
+	// 1
+	// 2
+	// 3
+	// 1
+	// 2
+}
@@ -0,0 +1,12 @@
+package function
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c4/1.function",
+		lesson.Lesson{Name: "Test_F7", Title: "闭包", Tags: []string{"function", "closure"}},
+		lesson.Lesson{Name: "TestF8", Title: "defer 的执行顺序和闭包取值", Tags: []string{"function", "defer", "closure", lesson.TagPuzzle}},
+		lesson.Lesson{Name: "TestF9", Title: "defer 的参数在注册时求值", Tags: []string{"function", "defer", "closure", lesson.TagPuzzle}},
+		lesson.Lesson{Name: "TestF10", Title: "练习：defer 关闭文件的陷阱", Tags: []string{"function", "defer"}, Exercise: true},
+	)
+}
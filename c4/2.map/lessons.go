@@ -0,0 +1,11 @@
+package _map
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c4/2.map",
+		lesson.Lesson{Name: "TestM1", Title: "map 的基本使用", Tags: []string{"map", lesson.TagUnordered}},
+		lesson.Lesson{Name: "TestM2", Title: "按指定顺序遍历 map", Tags: []string{"map", "sort", lesson.TagUnordered}},
+		lesson.Lesson{Name: "TestM3", Title: "map 不支持并发读写", Tags: []string{"map", "goroutine"}, Expect: lesson.Fatal},
+	)
+}
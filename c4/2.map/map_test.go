@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+
+	"study/internal/demo"
 )
 
 /*
@@ -98,23 +100,26 @@ func TestM2 (t *testing.T) {
 }
 
 // map 并不支持并发的读写
+// 运行时检测到并发读写会直接 fatal error（不能 recover），没检测到时一直阻塞在 select {}
 func TestM3(t *testing.T) {
-	m := make(map[int]int)
-
-	go func() {
-		for {
-			m[0] = 1
-		}
-	}()
-
-	go func() {
-		for {
-			_ = m[1]
-			// m[1] = 2
-		}
-	}()
-
-	select {}
+	demo.Run(t, func() {
+		m := make(map[int]int)
+
+		go func() {
+			for {
+				m[0] = 1
+			}
+		}()
+
+		go func() {
+			for {
+				_ = m[1]
+				// m[1] = 2
+			}
+		}()
+
+		select {}
+	}, demo.Fatal("concurrent map"), demo.Hang)
 }
 
 
@@ -0,0 +1,81 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package strcut
+
+import (
+	"fmt"
+)
+
+// 结构体的定义和初始化
+//
+// 结构体的定义（伪代码）：
+//
+//		type 类型名 struct {
+//	        字段名 字段类型
+//	        字段名 字段类型
+//	        …
+//	    }
+//	    1.类型名：标识自定义结构体的名称，在同一个包内不能重复。
+//	    2.字段名：表示结构体字段名。结构体中的字段名必须唯一。
+//	    3.字段类型：表示结构体字段的具体类型。
+func Example_s1() {
+	var p = person{
+		name: "",
+		city: "",
+		age:  0,
+	}
+
+	// 结构体初始化 = 上面
+	p = person{}
+	_ = p.name
+
+	// 创建指针并赋值,使用 & 对结构体进行取地址操作相当于对该结构体类型进行了一次 new 实例化操作。
+	p1 := &person{}
+	_ = p1.name
+
+	// 通过 new 函数新建 得到的是结构体的地址。
+	p1 = new(person)
+	_ = p1.name
+
+	// 当某些字段没有初始值的时候，该字段可以不写。此时，
+	// 没有指定初始值的字段的值就是该字段类型的零值。
+	p2 := &person{
+		city: "北京",
+	}
+	fmt.Printf("p2=%#v\n", p2) // p2=&main.person{name:"", city:"北京", age:0}
+
+	// 初始化结构体的时候可以简写，也就是初始化的时候不写键，直接写值。
+	p3 := &person{
+		"xqw",
+		"北京",
+		18,
+	}
+	fmt.Printf("p3=%#v\n", p3) // p3=&main.person{name:"xqw", city:"北京", age:18}
+	/*
+			1.必须初始化结构体的所有字段。
+		    2.初始值的填充顺序必须与字段在结构体中的声明顺序一致。
+		    3.该方式不能和键值初始化方式混用。
+	*/
+
+	// Output:
+	// p2=&strcut.person{name:"", city:"北京", age:0}
+	// p3=&strcut.person{name:"xqw", city:"北京", age:18}
+}
+
+// 嵌入结构体的同名字段
+//
+// 同名字段的情况
+func Example_s3() {
+	var s Student
+	// 给自己字段赋值了
+	s.name = "5lmh"
+	fmt.Println(s)
+
+	// 若给父类同名字段赋值，如下
+	s.person.name = "abc"
+	fmt.Println(s)
+
+	// Output:
+	// {{  0} 5lmh}
+	// {{abc  0} 5lmh}
+}
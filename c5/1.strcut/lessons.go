@@ -0,0 +1,11 @@
+package strcut
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c5/1.strcut",
+		lesson.Lesson{Name: "TestS1", Title: "结构体的定义和初始化", Tags: []string{"struct"}},
+		lesson.Lesson{Name: "TestS2", Title: "匿名字段和嵌入结构体", Tags: []string{"struct", "embedding"}},
+		lesson.Lesson{Name: "TestS3", Title: "嵌入结构体的同名字段", Tags: []string{"struct", "embedding"}},
+	)
+}
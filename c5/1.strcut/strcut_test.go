@@ -6,7 +6,7 @@ import (
 )
 
 /*
-结构体的定义：
+结构体的定义（伪代码）：
 	type 类型名 struct {
         字段名 字段类型
         字段名 字段类型
@@ -0,0 +1,12 @@
+package method
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c5/2.method",
+		lesson.Lesson{Name: "TestM1", Title: "方法和接收者", Tags: []string{"method"}},
+		lesson.Lesson{Name: "TestM2", Title: "指针类型的接收者", Tags: []string{"method", "pointer"}},
+		lesson.Lesson{Name: "TestM3", Title: "用嵌入结构体实现“继承”", Tags: []string{"method", "struct", "embedding"}},
+		lesson.Lesson{Name: "TestM4", Title: "结构体标签和 json", Tags: []string{"struct", "tag", "json"}},
+	)
+}
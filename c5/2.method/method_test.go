@@ -10,7 +10,7 @@ import (
 	Go语言中的方法（Method）是一种作用于特定类型变量的函数。
 	这种特定类型变量叫做接收者（Receiver）。
 	接收者的概念就类似于其他语言中的this或者 self。
-
+	方法的定义格式（伪代码）：
 	func (接收者变量 接收者类型) 方法名(参数列表) (返回参数) {
         函数体
     }
@@ -36,7 +36,7 @@ import (
     一个类型可实现多个接口。
     接口命名习惯以 er 结尾。
 
-
+    接口的定义格式（伪代码）：
     type 接口类型名 interface{
         方法名1( 参数列表1 ) 返回值列表1
         方法名2( 参数列表2 ) 返回值列表2
@@ -0,0 +1,15 @@
+package _interface
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c5/3.interface",
+		lesson.Lesson{Name: "TestI1", Title: "实现接口", Tags: []string{"interface"}},
+		lesson.Lesson{Name: "TestI2", Title: "值接收者和指针接收者实现接口", Tags: []string{"interface", "method"}},
+		lesson.Lesson{Name: "TestI3", Title: "接口嵌套", Tags: []string{"interface"}},
+		lesson.Lesson{Name: "TestI4", Title: "空接口", Tags: []string{"interface"}},
+		lesson.Lesson{Name: "TestI5", Title: "空接口作为 map 的值", Tags: []string{"interface", "map"}},
+		lesson.Lesson{Name: "TestI6", Title: "接口值的动态类型和动态值", Tags: []string{"interface"}},
+		lesson.Lesson{Name: "TestI7", Title: "类型断言和 type switch", Tags: []string{"interface", "control"}},
+	)
+}
@@ -0,0 +1,10 @@
+package __goroutine
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c6/1.goroutine",
+		lesson.Lesson{Name: "TestG1", Title: "普通的函数调用", Tags: []string{"goroutine"}},
+		lesson.Lesson{Name: "TestG2", Title: "用 go 关键字启动 goroutine", Tags: []string{"goroutine", lesson.TagRacy}},
+	)
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"study/internal/clock"
+	"study/internal/demo"
 )
 
 /*
@@ -66,10 +69,12 @@ func TestC2(t *testing.T) {
 
 // 无缓冲的通道又称为阻塞的通道。
 func TestC3(t *testing.T) {
-	ch := make(chan int)
-	ch <- 10 	// 写入通道，一直等待接受者接收
-	// <- ch	// 获取通道的值，一直等待发送者发送
-	fmt.Println("发送成功")
+	demo.Run(t, func() {
+		ch := make(chan int)
+		ch <- 10 	// 写入通道，一直等待接受者接收
+		// <- ch	// 获取通道的值，一直等待发送者发送
+		fmt.Println("发送成功")
+	}, demo.Deadlock)
 }
 
 // 只要通道的容量大于零，那么该通道就是有缓冲的通道，通道的容量表示通道中能存放元素的数量。
@@ -81,7 +86,7 @@ func TestC4(t *testing.T) {
 	fmt.Println("发送成功 10")
 
 	go func() {
-		time.Sleep(2 * time.Second)
+		clock.Sleep(2 * time.Second) // 和 time.Sleep 相同，默认用虚拟时间，STUDY_CLOCK=real 时真的等待
 		<- ch
 	}()
 
@@ -0,0 +1,308 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package __channel
+
+import (
+	"fmt"
+	"study/internal/clock"
+	"time"
+)
+
+// 发送、接收和关闭
+//
+// 通道有发送（send）、接收(receive）和关闭（close）三种操作。
+//
+// 发送和接收都使用 <- 符号。
+func Example_c2() {
+	ch := make(chan int)
+	go func() {
+		fmt.Println("send ", 1)
+		ch <- 1
+	}()
+
+	a := <-ch
+	fmt.Println("receive ", a)
+
+	close(ch)
+
+	// Output:
+	// send  1
+	// receive  1
+}
+
+// 有缓冲的通道
+//
+// 只要通道的容量大于零，那么该通道就是有缓冲的通道，通道的容量表示通道中能存放元素的数量。
+// 当通道的缓冲区被放满了，又会被阻塞，直到有接受者拿走其中的值。
+func Example_c4() {
+	ch := make(chan int, 1) // 创建一个容量为1的有缓冲区通道
+
+	ch <- 10
+	fmt.Println("发送成功 10")
+
+	go func() {
+		clock.Sleep(2 * time.Second) // 和 time.Sleep 相同，默认用虚拟时间，STUDY_CLOCK=real 时真的等待
+		<-ch
+	}()
+
+	ch <- 11
+	fmt.Println("发送成功 11")
+
+	// Output:
+	// 发送成功 10
+	// 发送成功 11
+}
+
+// 判断通道是否关闭
+//
+// 判断通道是否已经关闭的操作
+func Example_c5() {
+	ch1 := make(chan int)
+	ch2 := make(chan int)
+
+	// 开启goroutine将0~100的数发送到ch1中
+	go func() {
+		for i := 0; i < 100; i++ {
+			ch1 <- i
+		}
+		close(ch1)
+	}()
+
+	// 开启goroutine从ch1中接收值，并将该值的平方发送到ch2中
+	go func() {
+		for {
+			i, ok := <-ch1 // 通道关闭后再取值ok=false
+			if !ok {
+				break
+			}
+			ch2 <- i * i
+		}
+		close(ch2)
+	}()
+
+	// 在主goroutine中从ch2中接收值打印
+	for i := range ch2 { // 通道关闭后会退出for range循环
+		fmt.Println(i)
+	}
+
+	// Output:
+	// 0
+	// 1
+	// 4
+	// 9
+	// 16
+	// 25
+	// 36
+	// 49
+	// 64
+	// 81
+	// 100
+	// 121
+	// 144
+	// 169
+	// 196
+	// 225
+	// 256
+	// 289
+	// 324
+	// 361
+	// 400
+	// 441
+	// 484
+	// 529
+	// 576
+	// 625
+	// 676
+	// 729
+	// 784
+	// 841
+	// 900
+	// 961
+	// 1024
+	// 1089
+	// 1156
+	// 1225
+	// 1296
+	// 1369
+	// 1444
+	// 1521
+	// 1600
+	// 1681
+	// 1764
+	// 1849
+	// 1936
+	// 2025
+	// 2116
+	// 2209
+	// 2304
+	// 2401
+	// 2500
+	// 2601
+	// 2704
+	// 2809
+	// 2916
+	// 3025
+	// 3136
+	// 3249
+	// 3364
+	// 3481
+	// 3600
+	// 3721
+	// 3844
+	// 3969
+	// 4096
+	// 4225
+	// 4356
+	// 4489
+	// 4624
+	// 4761
+	// 4900
+	// 5041
+	// 5184
+	// 5329
+	// 5476
+	// 5625
+	// 5776
+	// 5929
+	// 6084
+	// 6241
+	// 6400
+	// 6561
+	// 6724
+	// 6889
+	// 7056
+	// 7225
+	// 7396
+	// 7569
+	// 7744
+	// 7921
+	// 8100
+	// 8281
+	// 8464
+	// 8649
+	// 8836
+	// 9025
+	// 9216
+	// 9409
+	// 9604
+	// 9801
+}
+
+// 单向通道
+// 有的时候我们会将通道作为参数在多个任务函数间传递，很多时候我们在不同的任务函数中使用通道都会对其进行限制，
+// 比如限制通道在函数中只能发送或只能接收。
+//  1. chan<- int 是一个只能发送的通道，可以发送但是不能接收；
+//  2. <-chan int 是一个只能接收的通道，可以接收但是不能发送。
+//
+// 在函数传参及任何赋值操作中将双向通道转换为单向通道是可以的，但反过来是不可以的。
+func Example_c6() {
+	ch1 := make(chan int)
+	ch2 := make(chan int)
+
+	go counter(ch1)
+	go squarer(ch2, ch1)
+
+	printer(ch2)
+
+	// Output:
+	// 0
+	// 1
+	// 4
+	// 9
+	// 16
+	// 25
+	// 36
+	// 49
+	// 64
+	// 81
+	// 100
+	// 121
+	// 144
+	// 169
+	// 196
+	// 225
+	// 256
+	// 289
+	// 324
+	// 361
+	// 400
+	// 441
+	// 484
+	// 529
+	// 576
+	// 625
+	// 676
+	// 729
+	// 784
+	// 841
+	// 900
+	// 961
+	// 1024
+	// 1089
+	// 1156
+	// 1225
+	// 1296
+	// 1369
+	// 1444
+	// 1521
+	// 1600
+	// 1681
+	// 1764
+	// 1849
+	// 1936
+	// 2025
+	// 2116
+	// 2209
+	// 2304
+	// 2401
+	// 2500
+	// 2601
+	// 2704
+	// 2809
+	// 2916
+	// 3025
+	// 3136
+	// 3249
+	// 3364
+	// 3481
+	// 3600
+	// 3721
+	// 3844
+	// 3969
+	// 4096
+	// 4225
+	// 4356
+	// 4489
+	// 4624
+	// 4761
+	// 4900
+	// 5041
+	// 5184
+	// 5329
+	// 5476
+	// 5625
+	// 5776
+	// 5929
+	// 6084
+	// 6241
+	// 6400
+	// 6561
+	// 6724
+	// 6889
+	// 7056
+	// 7225
+	// 7396
+	// 7569
+	// 7744
+	// 7921
+	// 8100
+	// 8281
+	// 8464
+	// 8649
+	// 8836
+	// 9025
+	// 9216
+	// 9409
+	// 9604
+	// 9801
+}
@@ -0,0 +1,14 @@
+package __channel
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c6/2.channel",
+		lesson.Lesson{Name: "TestC1", Title: "声明和创建通道", Tags: []string{"channel"}},
+		lesson.Lesson{Name: "TestC2", Title: "发送、接收和关闭", Tags: []string{"channel", "goroutine"}},
+		lesson.Lesson{Name: "TestC3", Title: "无缓冲通道会阻塞", Tags: []string{"channel"}, Expect: lesson.Blocks},
+		lesson.Lesson{Name: "TestC4", Title: "有缓冲的通道", Tags: []string{"channel", "goroutine"}},
+		lesson.Lesson{Name: "TestC5", Title: "判断通道是否关闭", Tags: []string{"channel", "goroutine"}},
+		lesson.Lesson{Name: "TestC6", Title: "单向通道", Tags: []string{"channel", "goroutine"}},
+	)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"study/internal/clock"
 )
 
 /*
@@ -52,11 +54,13 @@ func TestC1(t *testing.T) {
 }
 
 func test1(ch chan string) {
-	time.Sleep(time.Second * 1)
+	// clock.Sleep 和 time.Sleep 用法相同，默认用虚拟时间，不用真的等；
+	// 想真的等待时用 STUDY_CLOCK=real 运行
+	clock.Sleep(time.Second * 1)
 	ch <- "test1"
 }
 func test2(ch chan string) {
-	time.Sleep(time.Second * 2)
+	clock.Sleep(time.Second * 2)
 	ch <- "test2"
 }
 
@@ -0,0 +1,102 @@
+// Code generated by "study examples -w"; DO NOT EDIT.
+
+package __concurrencyControl
+
+import (
+	"fmt"
+	"study/internal/clock"
+	"sync"
+	"time"
+)
+
+// select 多路复用
+//
+// select多路复用
+//
+// select的使用类似于switch语句，它有一系列case分支和一个默认的分支。
+// 每个case会对应一个通道的通信（接收或发送）过程。select会一直等待，
+// 直到某个case的通信操作完成时，就会执行case分支对应的语句。
+//
+//	select {
+//	    case <-chan1:
+//	       // 如果chan1成功读到数据，则进行该case处理语句
+//	    case chan2 <- 1:
+//	       // 如果成功向chan2写入数据，则进行该case处理语句
+//	    default:
+//	       // 如果上面都没有成功，则进入default处理流程
+//	}
+//
+// 执行步骤：
+// 1. 所有channel表达式都会被求值、所有被发送的表达式都会被求值。求值顺序：自上而下、从左到右.
+// 结果是选择一个发送或接收的channel，无论选择哪一个case进行操作，表达式都会被执行。
+// RecvStmt 左侧短变量声明或赋值未被评估。
+// 2. 如果有一个或多个IO操作可以完成，则Go运行时系统会随机的选择一个执行，
+// 否则的话，如果有default分支，则执行default分支语句，
+// 如果连default都没有，则select语句会一直阻塞，直到至少有一个IO操作可以进行.
+// 3. 除非所选择的情况是默认情况，否则执行相应的通信操作。
+// 4. 如果所选case是具有短变量声明或赋值的RecvStmt，则评估左侧表达式并分配接收值（或多个值）。
+// 5. 执行所选case中的语句
+//
+// select可以同时监听一个或多个channel，直到其中一个channel ready
+func Example_c1() {
+	// 2个管道
+	output1 := make(chan string)
+	output2 := make(chan string)
+	// 跑2个子协程，写数据
+	go test1(output1)
+	go test2(output2)
+	// 用select监控
+	select {
+	case s1 := <-output1:
+		fmt.Println("s1=", s1)
+	case s2 := <-output2:
+		fmt.Println("s2=", s2)
+	}
+
+	// Output:
+	// s1= test1
+}
+
+// select 中表达式的求值顺序
+//
+// 所有channel表达式都会被求值、所有被发送的表达式都会被求值。求值顺序：自上而下、从左到右.
+func Example_c3() {
+	select {
+	case getChan(0) <- getNumber(2):
+		fmt.Println("1th case is selected.")
+	case getChan(1) <- getNumber(3):
+		fmt.Println("2th case is selected.")
+	default:
+		fmt.Println("default!.")
+	}
+
+	// Output:
+	// chs[0]
+	// numbers[2]
+	// chs[1]
+	// numbers[3]
+	// default!.
+}
+
+// sync.WaitGroup
+// sync.WaitGroup 内部维护着一个计数器，计数器的值可以增加和减少。
+//
+// (wg *WaitGroup) Add(delta int)	计数器+delta
+// (wg *WaitGroup) Done()	计数器-1
+// (wg *WaitGroup) Wait()	阻塞直到计数器变为 0
+func Example_s1() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		fmt.Println("hello")
+		clock.Sleep(time.Second) // 虚拟时间，见 control_test.go 中的 test1
+		wg.Done()
+	}()
+
+	wg.Wait()
+	fmt.Println("main goroutine done!")
+
+	// Output:
+	// hello
+	// main goroutine done!
+}
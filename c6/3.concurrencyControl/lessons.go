@@ -0,0 +1,13 @@
+package __concurrencyControl
+
+import "study/internal/lesson"
+
+func init() {
+	lesson.Register("c6/3.concurrencyControl",
+		lesson.Lesson{Name: "TestC1", Title: "select 多路复用", Tags: []string{"select", "channel"}},
+		lesson.Lesson{Name: "TestC2", Title: "多个通道同时就绪时随机选择", Tags: []string{"select", "channel", lesson.TagRacy}},
+		lesson.Lesson{Name: "TestC3", Title: "select 中表达式的求值顺序", Tags: []string{"select", "channel", lesson.TagPuzzle}},
+		lesson.Lesson{Name: "TestS1", Title: "sync.WaitGroup", Tags: []string{"sync", "goroutine"}},
+		lesson.Lesson{Name: "TestS2", Title: "sync.Once", Tags: []string{"sync"}},
+	)
+}
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"study/internal/clock"
 )
 
 /*
@@ -21,7 +23,7 @@ func TestS1(t *testing.T) {
 	wg.Add(1)
 	go func() {
 		fmt.Println("hello")
-		time.Sleep(time.Second)
+		clock.Sleep(time.Second) // 虚拟时间，见 control_test.go 中的 test1
 		wg.Done()
 	}()
 
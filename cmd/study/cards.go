@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"study/internal/flashcard"
+	"study/internal/progress"
+)
+
+func init() {
+	register("cards", &command{
+		usage: "cards [-format tsv|csv] [-o file] [package|chapter...]",
+		short: "export the rules in the numbered lists of the comments as Anki flashcards",
+		run:   runCards,
+	})
+	register("review", &command{
+		usage: "review [-n count] [-new count] [package|chapter...]",
+		short: "review the flashcards that are due, scheduled with SM-2",
+		run:   runReview,
+	})
+}
+
+// loadCards 返回 dirs 中的课程包或章节的卡片，dirs 为空时返回全部卡片。
+func loadCards(dirs []string) ([]*flashcard.Card, error) {
+	c, err := loadCourse()
+	if err != nil {
+		return nil, err
+	}
+	cards, err := flashcard.All(c)
+	if err != nil || len(dirs) == 0 {
+		return cards, err
+	}
+	var selected []*flashcard.Card
+	for _, dir := range dirs {
+		dir = strings.Trim(dir, "/")
+		n := len(selected)
+		for _, card := range cards {
+			if card.Package == dir || strings.HasPrefix(card.Package, dir+"/") {
+				selected = append(selected, card)
+			}
+		}
+		if len(selected) == n {
+			return nil, fmt.Errorf("no flashcards in %s", dir)
+		}
+	}
+	return selected, nil
+}
+
+func runCards(args []string) error {
+	fs := newFlagSet("cards")
+	format := fs.String("format", "tsv", "write `tsv` or csv")
+	out := fs.String("o", "", "write to `file` instead of standard output")
+	fs.Parse(args)
+
+	cards, err := loadCards(fs.Args())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return flashcard.Write(os.Stdout, cards, *format)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := flashcard.Write(f, cards, *format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d cards to %s, import it in Anki with File > Import\n", len(cards), *out)
+	return nil
+}
+
+func runReview(args []string) error {
+	fs := newFlagSet("review")
+	limit := fs.Int("n", 20, "review at most `count` cards")
+	newLimit := fs.Int("new", 5, "include at most `count` cards never reviewed before")
+	fs.Parse(args)
+
+	cards, err := loadCards(fs.Args())
+	if err != nil {
+		return err
+	}
+	dir, err := progress.Dir()
+	if err != nil {
+		return err
+	}
+	learner, err := progress.Learner()
+	if err != nil {
+		return err
+	}
+	path, err := flashcard.StatePath(dir, learner)
+	if err != nil {
+		return err
+	}
+	state, err := flashcard.LoadState(path)
+	if err != nil {
+		return err
+	}
+	if state.Learner == "" {
+		state.Learner = learner
+	}
+
+	due := state.Due(cards, time.Now(), *newLimit)
+	if len(due) > *limit {
+		due = due[:*limit]
+	}
+	if len(due) == 0 {
+		fmt.Println("Nothing to review.")
+		printNext(state)
+		return nil
+	}
+	in := bufio.NewScanner(os.Stdin)
+	reviewed := 0
+	for i, card := range due {
+		fmt.Printf("\n[%d/%d] %s\n  %s\n", i+1, len(due), card.Package, card.Question)
+		fmt.Print("Press Enter to show the answer, q to stop: ")
+		if !in.Scan() || strings.TrimSpace(in.Text()) == "q" {
+			break
+		}
+		fmt.Printf("  %d. %s\n", card.Number, strings.ReplaceAll(card.Answer, "\n", "\n     "))
+		fmt.Printf("  (%s:%d)\n", card.File, card.Line)
+
+		quality, ok := askQuality(in)
+		if !ok {
+			break
+		}
+		r := state.Answer(card.ID, quality, time.Now())
+		if err := state.Save(path); err != nil {
+			return err
+		}
+		reviewed++
+		fmt.Printf("  next review in %d day(s)\n", r.Interval)
+	}
+	fmt.Printf("\nReviewed %d card(s).\n", reviewed)
+	printNext(state)
+	return nil
+}
+
+// askQuality 读入 0～5 的答题质量，输入 q 或读完时返回 false。
+func askQuality(in *bufio.Scanner) (int, bool) {
+	for {
+		fmt.Printf("How well did you remember? %d (forgot) to %d (perfect), q to stop: ", flashcard.MinQuality, flashcard.MaxQuality)
+		if !in.Scan() {
+			return 0, false
+		}
+		s := strings.TrimSpace(in.Text())
+		if s == "q" {
+			return 0, false
+		}
+		if q, err := strconv.Atoi(s); err == nil && q >= flashcard.MinQuality && q <= flashcard.MaxQuality {
+			return q, true
+		}
+	}
+}
+
+func printNext(s *flashcard.State) {
+	if next := s.Next(); !next.IsZero() {
+		fmt.Printf("Next review: %s\n", next.Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"study/internal/course"
+	"study/internal/i18n"
+	_ "study/internal/lesson/all" // 登记所有示例
+	"study/internal/progress"
+)
+
+// loadCourse 定位仓库根目录并读取课程。
+func loadCourse() (*course.Course, error) {
+	root, err := course.FindRoot(".")
+	if err != nil {
+		return nil, err
+	}
+	return course.Load(root)
+}
+
+// lessonArgs 解析 <包目录> <示例名> 两个参数。
+func lessonArgs(c *course.Course, args []string) (*course.Lesson, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("want <package> <lesson>, got %d arguments", len(args))
+	}
+	return c.Lesson(args[0], args[1])
+}
+
+// langFlag 给命令加上 -lang 参数，默认使用环境变量 STUDY_LANG，没有设置时使用中文。
+func langFlag(fs *flag.FlagSet) *string {
+	lang := os.Getenv("STUDY_LANG")
+	if lang == "" {
+		lang = i18n.Source
+	}
+	return fs.String("lang", lang, "show the explanations in `language` (zh, en)")
+}
+
+// track 更新当前学员的进度文件。记录失败只给出警告，不影响命令本身。
+func track(f func(p *progress.Progress, now time.Time)) {
+	t, err := progress.NewTracker()
+	if err == nil {
+		err = t.Update(f)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "study: cannot record progress: %v\n", err)
+	}
+}
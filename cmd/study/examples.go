@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"study/internal/course"
+	"study/internal/example"
+)
+
+func init() {
+	register("examples", &command{
+		usage: "examples [-w] [package...]",
+		short: "convert lessons with deterministic output into Example functions",
+		run:   runExamples,
+	})
+}
+
+func runExamples(args []string) error {
+	fs := newFlagSet("examples")
+	write := fs.Bool("w", false, "write the Example functions to "+example.File+" in each package")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	var pkgs []*course.Package
+	for _, dir := range fs.Args() {
+		p := c.Package(dir)
+		if p == nil {
+			return fmt.Errorf("no package %s", dir)
+		}
+		pkgs = append(pkgs, p)
+	}
+	if len(pkgs) == 0 {
+		for _, ch := range c.Chapters {
+			pkgs = append(pkgs, ch.Packages...)
+		}
+	}
+
+	ctx := context.Background()
+	var total, converted int
+	for _, p := range pkgs {
+		cs, err := example.Convert(ctx, c.Root, p)
+		if err != nil {
+			return err
+		}
+		if len(cs) == 0 {
+			continue
+		}
+		fmt.Println(p.Dir)
+		for _, cv := range cs {
+			total++
+			if cv.OK() {
+				converted++
+				fmt.Printf("    %-10s ok       %s\n", cv.Lesson.Name, cv.Name)
+			} else {
+				fmt.Printf("    %-10s skipped  %s\n", cv.Lesson.Name, cv.Reason)
+			}
+		}
+		if !*write {
+			continue
+		}
+		if err := writeExamples(c.Root, p, cs); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%d of %d lessons can be Examples\n", converted, total)
+	return nil
+}
+
+// writeExamples 写入或更新包的 example_test.go，没有可以转换的示例时删除它。
+func writeExamples(root string, p *course.Package, cs []*example.Conversion) error {
+	src, err := example.Generate(root, p, cs)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(root, filepath.FromSlash(p.Dir), example.File)
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if old != nil && !bytes.HasPrefix(old, []byte(example.Header)) {
+		return fmt.Errorf("%s was not generated by study examples, not overwriting it", path)
+	}
+	switch {
+	case src == nil && old != nil:
+		fmt.Printf("    removed %s\n", example.File)
+		return os.Remove(path)
+	case src == nil || bytes.Equal(src, old):
+		return nil
+	}
+	fmt.Printf("    wrote %s\n", example.File)
+	return os.WriteFile(path, src, 0o644)
+}
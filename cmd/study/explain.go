@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/explain"
+	"study/internal/i18n"
+)
+
+func init() {
+	register("explain", &command{
+		usage: "explain [-lang lang] <file:line>",
+		short: "find the comments, numbered rules and related lessons that explain a source line",
+		run:   runExplain,
+	})
+}
+
+func runExplain(args []string) error {
+	fs := newFlagSet("explain")
+	lang := langFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("want one file:line argument")
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	file, line, err := fileLine(c, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+	e, err := explain.Explain(c, file, line)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s:%d", e.File, e.Line)
+	switch {
+	case e.Lesson != nil:
+		fmt.Printf("  in %s  %s", e.Lesson.Name, t.Title(e.Lesson))
+	case e.Func != "":
+		fmt.Printf("  in %s", e.Func)
+	}
+	fmt.Println()
+	if e.Code != "" {
+		fmt.Printf("    %s\n", e.Code)
+	}
+	if len(e.Concepts) > 0 {
+		var names []string
+		for _, c := range e.Concepts {
+			names = append(names, c.Name)
+		}
+		fmt.Printf("uses: %s\n", strings.Join(names, ", "))
+	}
+
+	if len(e.Inline) > 0 {
+		fmt.Println("\nComments on the line:")
+		for _, cm := range e.Inline {
+			fmt.Printf("  %d: %s\n", cm.Line, indent(cm.Text, "      "))
+		}
+	}
+
+	texts, err := noteTexts(c, e, t)
+	if err != nil {
+		return err
+	}
+	for i, n := range e.Notes {
+		fmt.Printf("\nExplanation (%s:%d):\n", n.File, n.Line)
+		fmt.Printf("  %s\n", indent(texts[i], "  "))
+	}
+
+	for _, r := range e.Rules {
+		where := "rules"
+		if r.Matched {
+			where = "see also"
+		}
+		fmt.Printf("\n%s: %s (%s:%d)\n", where, r.Card.Topic, r.Card.File, r.Card.Line)
+		fmt.Printf("  %d. %s\n", r.Card.Number, indent(r.Card.Answer, "     "))
+	}
+
+	if len(e.Related) > 0 {
+		fmt.Println("\nRelated lessons:")
+		for _, r := range e.Related {
+			fmt.Printf("  %-32s %s  (%s)\n", r.Lesson.Path(), t.Title(r.Lesson), strings.Join(r.Concepts, ", "))
+		}
+	}
+	return nil
+}
+
+// fileLine 解析 文件:行号。文件相对仓库根目录，也可以是相对当前目录的路径。
+func fileLine(c *course.Course, arg string) (string, int, error) {
+	i := strings.LastIndexByte(arg, ':')
+	if i < 0 {
+		return "", 0, fmt.Errorf("want file:line, got %q", arg)
+	}
+	line, err := strconv.Atoi(arg[i+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("bad line number in %q", arg)
+	}
+	file := arg[:i]
+	if _, err := os.Stat(filepath.Join(c.Root, file)); err != nil {
+		if abs, err := filepath.Abs(file); err == nil {
+			if rel, err := filepath.Rel(c.Root, abs); err == nil {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(file), line, nil
+}
+
+// noteTexts 返回说明注释的文字，-lang 不是中文时用译文；没有译文的显示中文，并在 stderr 上提示。
+func noteTexts(c *course.Course, e *explain.Explanation, t *i18n.Texts) ([]string, error) {
+	texts := make([]string, len(e.Notes))
+	for i, n := range e.Notes {
+		texts[i] = n.Text
+	}
+	if t.Lang == i18n.Source || len(e.Notes) == 0 {
+		return texts, nil
+	}
+	notes, err := course.Notes(c.Root, e.Package)
+	if err != nil {
+		return nil, err
+	}
+	keys := i18n.NoteKeys(e.Package, notes)
+	for i, n := range e.Notes {
+		for j, m := range notes {
+			if m.File != n.File || m.Line != n.Line {
+				continue
+			}
+			text, ok := t.Lookup(keys[j], n.Text)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "study: no up-to-date %s translation for %s\n", t.Lang, keys[j])
+			}
+			texts[i] = text
+		}
+	}
+	return texts, nil
+}
+
+// indent 在 text 除第一行以外的非空行前加上 prefix。
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/i18n"
+	"study/internal/notebook"
+)
+
+func init() {
+	register("export", &command{
+		usage: "export [-format ipynb] [-lang lang] [-o dir] <package|chapter>...",
+		short: "export lesson packages as Jupyter notebooks",
+		run:   runExport,
+	})
+}
+
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", "ipynb", "output `format`, only ipynb for now")
+	out := fs.String("o", ".", "write the files to `dir`")
+	lang := langFlag(fs)
+	fs.Parse(args)
+	if *format != "ipynb" {
+		return fmt.Errorf("unknown format %q, want ipynb", *format)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("want <package|chapter>...")
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	pkgs, err := packagesOf(c, fs.Args())
+	if err != nil {
+		return err
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	for _, p := range pkgs {
+		nb, err := notebook.Export(c, p, t)
+		if err != nil {
+			return err
+		}
+		data, err := nb.Encode()
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(*out, notebook.FileName(p))
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s (%d cells)\n", name, len(nb.Cells))
+	}
+	return nil
+}
+
+// packagesOf 返回 args 中的课程包，参数也可以是章节，例如 c4。
+func packagesOf(c *course.Course, args []string) ([]*course.Package, error) {
+	var pkgs []*course.Package
+	for _, arg := range args {
+		arg = strings.Trim(arg, "/")
+		if p := c.Package(arg); p != nil {
+			pkgs = append(pkgs, p)
+			continue
+		}
+		found := false
+		for _, ch := range c.Chapters {
+			if ch.Name == arg {
+				pkgs = append(pkgs, ch.Packages...)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no package or chapter %s", arg)
+		}
+	}
+	return pkgs, nil
+}
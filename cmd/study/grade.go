@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"study/internal/grade"
+	"study/internal/progress"
+)
+
+func init() {
+	register("grade", &command{
+		usage: "grade [-file learner.go] [exercise ...]",
+		short: "grade the exercises against hidden test cases",
+		run:   runGrade,
+	})
+}
+
+func runGrade(args []string) error {
+	fs := newFlagSet("grade")
+	file := fs.String("file", "", "grade the implementation in `file` instead of the lesson file")
+	verbose := fs.Bool("v", false, "also show passing cases")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+
+	exercises := grade.Exercises
+	if fs.NArg() > 0 {
+		exercises = nil
+		for _, id := range fs.Args() {
+			e := grade.Lookup(id)
+			if e == nil {
+				return fmt.Errorf("no exercise %q", id)
+			}
+			exercises = append(exercises, e)
+		}
+	}
+	if *file != "" && len(exercises) != 1 {
+		return fmt.Errorf("-file needs exactly one exercise")
+	}
+
+	total := 0
+	for _, e := range exercises {
+		r, err := grade.Grade(context.Background(), c.Root, e, *file)
+		if err != nil {
+			return err
+		}
+		score := r.Score()
+		track(func(p *progress.Progress, now time.Time) { p.Attempt(e.ID, score, now) })
+		printReport(r, *verbose)
+		total += r.Score()
+	}
+	if len(exercises) > 1 {
+		fmt.Printf("\ntotal %d/%d\n", total, 100*len(exercises))
+	}
+	return nil
+}
+
+func printReport(r *grade.Report, verbose bool) {
+	e := r.Exercise
+	fmt.Println(scoreLine(r))
+	if r.BuildErr != "" {
+		fmt.Printf("    build failed, check the signature %s\n", e.Sig)
+		for _, line := range strings.Split(strings.TrimSpace(r.BuildErr), "\n") {
+			fmt.Printf("    | %s\n", line)
+		}
+		return
+	}
+	for _, c := range r.Cases {
+		if c.Pass {
+			if verbose {
+				fmt.Printf("    ok   %s\n", c.Name)
+			}
+			continue
+		}
+		fmt.Printf("    FAIL %s: %s\n", c.Name, c.Input)
+		fmt.Printf("         got  %s\n", show(c.Got))
+		fmt.Printf("         want %s\n", show(c.Want))
+		if c.Explain != "" {
+			fmt.Printf("         hint %s\n", c.Explain)
+		}
+	}
+}
+
+func scoreLine(r *grade.Report) string {
+	e := r.Exercise
+	return fmt.Sprintf("%-24s %3d/100  %d/%d cases  %s", e.ID, r.Score(), r.Passed(), len(r.Cases), e.Sig)
+}
+
+func show(s string) string {
+	if s == "" {
+		return "(nothing)"
+	}
+	return strings.ReplaceAll(s, "\n", "\n              ")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"study/internal/i18n"
+	"study/internal/textdiff"
+)
+
+func init() {
+	register("i18n", &command{
+		usage: "i18n extract | i18n check [lang]",
+		short: "extract the Chinese explanations into i18n/zh.json, or check a translation against them",
+		run:   runI18n,
+	})
+}
+
+func runI18n(args []string) error {
+	fs := newFlagSet("i18n")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	src, err := i18n.Extract(c)
+	if err != nil {
+		return err
+	}
+	switch {
+	case fs.NArg() == 1 && fs.Arg(0) == "extract":
+		if err := i18n.WriteSource(c.Root, src); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %d entries to %s\n", len(src), i18n.Path(c.Root, i18n.Source))
+		return nil
+	case fs.NArg() >= 1 && fs.NArg() <= 2 && fs.Arg(0) == "check":
+	default:
+		fs.Usage()
+		return errors.New("want extract or check")
+	}
+
+	lang := "en"
+	if fs.NArg() == 2 {
+		lang = fs.Arg(1)
+	}
+	tr, err := i18n.LoadTranslations(c.Root, lang)
+	if err != nil {
+		return err
+	}
+	s := i18n.Check(src, tr)
+	for _, key := range s.Stale {
+		fmt.Printf("stale    %s\n", key)
+		for _, l := range textdiff.Lines(tr[key].Source, src[key]) {
+			if l.Op != textdiff.Equal {
+				fmt.Printf("         %c%s\n", l.Op, l.Text)
+			}
+		}
+	}
+	for _, key := range s.Missing {
+		fmt.Printf("missing  %s  %s\n", key, firstLine(src[key]))
+	}
+	for _, key := range s.Orphan {
+		fmt.Printf("orphan   %s\n", key)
+	}
+	if !s.OK() {
+		return fmt.Errorf("%s: %d stale, %d missing, %d orphan", lang, len(s.Stale), len(s.Missing), len(s.Orphan))
+	}
+	fmt.Printf("%s: all %d entries are up to date\n", lang, len(src))
+	return nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
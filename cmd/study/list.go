@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"study/internal/course"
+	"study/internal/lesson"
+)
+
+func init() {
+	register("list", &command{
+		usage: "list [-json] [-tag tag] [-exercises] [chapter]",
+		short: "list chapters and their lessons",
+		run:   runList,
+	})
+}
+
+func runList(args []string) error {
+	fs := newFlagSet("list")
+	asJSON := fs.Bool("json", false, "print the lesson registry as JSON")
+	tag := fs.String("tag", "", "only list lessons with `tag`")
+	exercises := fs.Bool("exercises", false, "only list exercises")
+	fs.Parse(args)
+
+	keep := func(l *course.Lesson) bool {
+		if *tag == "" && !*exercises {
+			return true
+		}
+		if l.Meta == nil {
+			return false
+		}
+		return (*tag == "" || l.Meta.HasTag(*tag)) && (!*exercises || l.Meta.Exercise)
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		var ls []lesson.Lesson
+		for _, l := range c.Lessons() {
+			if l.Meta != nil && keep(l) && (fs.NArg() == 0 || l.Meta.Chapter == fs.Arg(0)) {
+				ls = append(ls, *l.Meta)
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ls)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, ch := range c.Chapters {
+		if fs.NArg() > 0 && fs.Arg(0) != ch.Name {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", ch.Name, ch.Title)
+		for _, a := range ch.Assets {
+			fmt.Fprintf(w, "  %s\t\n", a)
+		}
+		for _, p := range ch.Packages {
+			fmt.Fprintf(w, "  %s\t(package %s)\n", p.Dir, p.Name)
+			for _, l := range p.Lessons {
+				if keep(l) {
+					fmt.Fprintf(w, "    %s\t%s%s\n", l.Name, l.Title, marks(l))
+				}
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// marks 返回示例标题后面的标记，例如 [练习]、[panics]。
+func marks(l *course.Lesson) string {
+	if l.Meta == nil {
+		return ""
+	}
+	var s string
+	if l.Meta.Exercise {
+		s += "  [练习]"
+	}
+	if l.Meta.Expect != lesson.Prints {
+		s += "  [" + l.Meta.Expect.String() + "]"
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+// study 是课程的命令行工具：列出章节和示例、运行单个示例、查看示例的说明注释。
+//
+//	study list
+//	study run c6/2.channel TestC5
+//	study show c3/5.slice Test_S9
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command 一个子命令。
+type command struct {
+	usage string
+	short string
+	run   func(args []string) error
+}
+
+var commands = map[string]*command{}
+
+func register(name string, c *command) {
+	commands[name] = c
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	c, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "study: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+	if err := c.run(flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "study %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: study <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].short)
+	}
+}
+
+// newFlagSet 为子命令创建 FlagSet，-h 时打印 usage。
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("study "+name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: study %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/pptx"
+)
+
+func init() {
+	register("pptx", &command{
+		usage: "pptx [-json] [chapter]",
+		short: "print the outline of the chapter slides and the lessons each slide covers",
+		run:   runPptx,
+	})
+}
+
+func runPptx(args []string) error {
+	fs := newFlagSet("pptx")
+	asJSON := fs.Bool("json", false, "print the slides as JSON")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	decks, err := pptx.Load(c)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		var keep []*pptx.Deck
+		for _, d := range decks {
+			if strings.HasPrefix(d.Path, strings.TrimSuffix(fs.Arg(0), "/")+"/") {
+				keep = append(keep, d)
+			}
+		}
+		if len(keep) == 0 {
+			return fmt.Errorf("no slides in %s", fs.Arg(0))
+		}
+		decks = keep
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(decks)
+	}
+
+	links := pptx.Match(decks, c.Lessons())
+	for i, d := range decks {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s\n", d.Path)
+		for _, s := range d.Slides {
+			fmt.Printf("\n## slide %d  %s\n", s.Number, s.Title)
+			for _, b := range s.Body {
+				if b.Code {
+					fmt.Printf("```go\n%s\n```\n", b.Text)
+					continue
+				}
+				fmt.Printf("%s- %s\n", strings.Repeat("  ", b.Level), b.Text)
+			}
+			if len(s.Images) > 0 {
+				fmt.Printf("(%d images)\n", len(s.Images))
+			}
+			if ls := slideLessons(links, s); len(ls) > 0 {
+				fmt.Printf("lessons: %s\n", strings.Join(ls, " "))
+			}
+		}
+	}
+	return nil
+}
+
+func slideLessons(links []pptx.Link, s *pptx.Slide) []string {
+	var ids []string
+	for _, k := range links {
+		if k.Slide == s {
+			ids = append(ids, k.Lesson.Path())
+		}
+	}
+	return ids
+}
+
+// slideRefs 返回示例对应的幻灯片，例如 “c6/6.Go并发及控制.pptx slide 7”，最多三页。
+// 读取讲义出错时不影响命令本身，返回空。
+func slideRefs(c *course.Course, l *course.Lesson) []string {
+	decks, err := pptx.Load(c)
+	if err != nil {
+		return nil
+	}
+	var refs []string
+	for _, k := range pptx.For(pptx.Match(decks, c.Lessons()), l) {
+		if len(refs) == 3 {
+			break
+		}
+		refs = append(refs, fmt.Sprintf("%s slide %d", k.Deck.Path, k.Slide.Number))
+	}
+	return refs
+}
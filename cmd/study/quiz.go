@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/golden"
+	"study/internal/lesson"
+	"study/internal/progress"
+	"study/internal/quiz"
+	"study/internal/runner"
+)
+
+func init() {
+	register("quiz", &command{
+		usage: "quiz [-timeout d] [package [lesson]]",
+		short: "predict a lesson's output, then compare it with the real one",
+		run:   runQuiz,
+	})
+}
+
+func runQuiz(args []string) error {
+	fs := newFlagSet("quiz")
+	timeout := fs.Duration("timeout", 30*time.Second, "kill the lesson after `d`")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+
+	// 不带参数时出 puzzle 标签的题；指定包时出包里所有能猜的题，并列出跳过的示例。
+	var ls []*course.Lesson
+	switch fs.NArg() {
+	case 0:
+		for _, l := range c.Lessons() {
+			if l.Meta != nil && l.Meta.HasTag(lesson.TagPuzzle) {
+				ls = append(ls, l)
+			}
+		}
+	case 1:
+		p := c.Package(fs.Arg(0))
+		if p == nil {
+			return fmt.Errorf("no lesson package %q", fs.Arg(0))
+		}
+		for _, l := range p.Lessons {
+			if reason := skip(l); reason != "" {
+				fmt.Printf("-- skip %s: %s\n", l.Path(), reason)
+				continue
+			}
+			ls = append(ls, l)
+		}
+	default:
+		l, err := lessonArgs(c, fs.Args())
+		if err != nil {
+			return err
+		}
+		if reason := skip(l); reason != "" {
+			return fmt.Errorf("%s: %s", l.Path(), reason)
+		}
+		ls = append(ls, l)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	right := 0
+	for i, l := range ls {
+		ok, err := ask(c.Root, l, in, *timeout)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if ok {
+			right++
+		}
+		if i < len(ls)-1 {
+			fmt.Println()
+		}
+	}
+	if len(ls) > 1 {
+		fmt.Printf("\n%d/%d correct\n", right, len(ls))
+	}
+	return nil
+}
+
+func skip(l *course.Lesson) string {
+	if l.Meta == nil {
+		return "not registered"
+	}
+	return quiz.Skip(*l.Meta)
+}
+
+// ask 出一道题。学员输入 EOF 而没有写任何答案时返回 io.EOF，结束测验。
+func ask(root string, l *course.Lesson, in *bufio.Scanner, timeout time.Duration) (bool, error) {
+	code, err := quiz.Code(root, l)
+	if err != nil {
+		return false, err
+	}
+	opt := golden.OptionsFor(*l.Meta)
+
+	fmt.Printf("== %s  %s\n\n%s\n\n", l.Path(), l.Title, code)
+	if opt.Unordered {
+		fmt.Println("(map 的遍历顺序是随机的，比较时不计行的顺序)")
+	}
+	fmt.Println("运行后会输出什么？输入预期的输出，单独一行 . 结束：")
+	answer, err := readAnswer(in)
+	if err != nil {
+		return false, err
+	}
+
+	track(func(p *progress.Progress, now time.Time) { p.Run(l.Path(), now) })
+	res, err := runner.Run(context.Background(), root, l, runner.Options{Timeout: timeout, Combined: true})
+	if err != nil {
+		return false, err
+	}
+	if res.Failed() {
+		return false, fmt.Errorf("%s: exit status %d", l.Path(), res.ExitCode)
+	}
+
+	r := quiz.Check(answer, res.Stdout, opt)
+	if r.Correct {
+		fmt.Printf("-- 正确 (%d 行)\n", r.Total)
+	} else {
+		fmt.Printf("-- 答对 %d/%d 行  (- 你的答案  + 实际输出)\n", r.Right, r.Total)
+		for _, line := range r.Lines {
+			fmt.Printf("%c %s\n", line.Op, line.Text)
+		}
+	}
+
+	notes, err := quiz.Notes(root, l)
+	if err != nil {
+		return false, err
+	}
+	if l.Comment != "" || len(notes) > 0 {
+		fmt.Println("-- 说明")
+	}
+	if l.Comment != "" {
+		fmt.Println(l.Comment)
+	}
+	for _, n := range notes {
+		fmt.Printf("%s:%d: %s\n", l.File, n.Line, strings.ReplaceAll(n.Text, "\n", "\n    "))
+	}
+	return r.Correct, nil
+}
+
+func readAnswer(in *bufio.Scanner) (string, error) {
+	var b strings.Builder
+	for {
+		if !in.Scan() {
+			if err := in.Err(); err != nil {
+				return "", err
+			}
+			if b.Len() == 0 {
+				return "", io.EOF
+			}
+			return b.String(), nil
+		}
+		if in.Text() == "." {
+			return b.String(), nil
+		}
+		b.WriteString(in.Text() + "\n")
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"study/internal/repl"
+)
+
+func init() {
+	register("repl", &command{
+		usage: "repl <package>",
+		short: "type Go statements against the types of a lesson package",
+		run:   runRepl,
+	})
+}
+
+const replHelp = `Type Go statements, expressions or declarations, for example
+    p := Person{name: "小王子", age: 25}
+    p.SetAge(30)
+    p.age
+Expressions and new variables are shown as Go syntax with their type. An unfinished line
+(an open { or () continues on the next line.
+    :list    show the statements so far
+    :reset   forget them
+    :types   list the types of the package
+    :quit    leave (or Ctrl-D)`
+
+func runRepl(args []string) error {
+	fs := newFlagSet("repl")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("want <package>")
+	}
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	p := c.Package(fs.Arg(0))
+	if p == nil {
+		return fmt.Errorf("no package %s", fs.Arg(0))
+	}
+	s, err := repl.Start(c.Root, p)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Ctrl-C 只打断正在运行的语句
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	fmt.Printf("study repl %s, types: %s\n", p.Dir, strings.Join(s.Types(), " "))
+	fmt.Println("Type :help for help.")
+	in := bufio.NewScanner(os.Stdin)
+	var input []string
+	for {
+		if len(input) == 0 {
+			fmt.Print(">>> ")
+		} else {
+			fmt.Print("... ")
+		}
+		if !in.Scan() {
+			fmt.Println()
+			return in.Err()
+		}
+		line := in.Text()
+		if len(input) == 0 {
+			switch strings.TrimSpace(line) {
+			case ":quit", ":q":
+				return nil
+			case ":help":
+				fmt.Println(replHelp)
+				continue
+			case ":list":
+				for _, st := range s.History() {
+					fmt.Println(st)
+				}
+				continue
+			case ":reset":
+				s.Reset()
+				continue
+			case ":types":
+				fmt.Println(strings.Join(s.Types(), " "))
+				continue
+			}
+		}
+		input = append(input, line)
+		text := strings.Join(input, "\n")
+		if !repl.Complete(text) {
+			continue
+		}
+		input = nil
+
+		runCtx, stop := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-interrupt:
+				stop()
+			case <-runCtx.Done():
+			}
+		}()
+		r, err := s.Eval(runCtx, text)
+		stop()
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+		fmt.Print(r.Output)
+		if r.Err != "" {
+			fmt.Printf("error: %s\n", r.Err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"study/internal/flashcard"
+	"study/internal/lesson"
+	"study/internal/progress"
+)
+
+func init() {
+	register("report", &command{
+		usage: "report [-o dir] [progress.json ...]",
+		short: "merge learners' progress into a CSV and HTML report",
+		run:   runReport,
+	})
+}
+
+func runReport(args []string) error {
+	fs := newFlagSet("report")
+	out := fs.String("o", ".", "write report.csv and report.html to `dir`")
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		dir, err := progress.Dir()
+		if err != nil {
+			return err
+		}
+		all, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		for _, f := range all {
+			if !strings.HasSuffix(f, flashcard.StateSuffix) { // 卡片的复习记录
+				files = append(files, f)
+			}
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no progress files in %s", dir)
+		}
+	}
+
+	var ps []*progress.Progress
+	for _, file := range files {
+		p, err := progress.Load(file)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if p.Learner == "" {
+			p.Learner = trimExt(filepath.Base(file))
+		}
+		ps = append(ps, p)
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	titles := map[string]string{}
+	for _, ch := range c.Chapters {
+		titles[ch.Name] = ch.Title
+	}
+	r := progress.NewReport(ps, lesson.All(), titles, time.Now())
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	for _, w := range []struct {
+		name  string
+		write func(f *os.File) error
+	}{
+		{"report.csv", func(f *os.File) error { return r.WriteCSV(f) }},
+		{"report.html", func(f *os.File) error { return r.WriteHTML(f) }},
+	} {
+		path := filepath.Join(*out, w.name)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = w.write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+func trimExt(name string) string {
+	return name[:len(name)-len(filepath.Ext(name))]
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"study/internal/clock"
+	"study/internal/i18n"
+	"study/internal/progress"
+	"study/internal/runner"
+)
+
+func init() {
+	register("run", &command{
+		usage: "run [-timeout d] [-clock fake|real] [-lang lang] <package> <lesson>",
+		short: "run one lesson and show its output",
+		run:   runRun,
+	})
+}
+
+func runRun(args []string) error {
+	fs := newFlagSet("run")
+	timeout := fs.Duration("timeout", 30*time.Second, "kill the lesson after `d`")
+	clk := fs.String("clock", clockDefault(), "run clock.Sleep and friends on `fake` (virtual) or real time")
+	lang := langFlag(fs)
+	fs.Parse(args)
+	if *clk != "fake" && *clk != "real" {
+		return fmt.Errorf("unknown clock %q, want fake or real", *clk)
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	l, err := lessonArgs(c, fs.Args())
+	if err != nil {
+		return err
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+
+	track(func(p *progress.Progress, now time.Time) { p.Run(l.Path(), now) })
+
+	fmt.Printf("== %s  %s\n", l.Path(), t.Title(l))
+	res, err := runner.Run(context.Background(), c.Root, l, runner.Options{Timeout: *timeout, Env: []string{clock.Env + "=" + *clk}})
+	if err != nil {
+		return err
+	}
+	if len(res.Stdout) > 0 {
+		fmt.Println("-- stdout")
+		os.Stdout.Write(res.Stdout)
+	}
+	if len(res.Stderr) > 0 {
+		fmt.Println("-- stderr")
+		os.Stdout.Write(res.Stderr)
+	}
+	switch {
+	case res.TimedOut:
+		return fmt.Errorf("timed out after %v", *timeout)
+	case res.ExitCode != 0:
+		return fmt.Errorf("exit status %d", res.ExitCode)
+	}
+	fmt.Printf("-- ok (%v)\n", res.Duration.Round(time.Millisecond))
+	return nil
+}
+
+// clockDefault 返回 -clock 的默认值，取环境变量 STUDY_CLOCK。
+func clockDefault() string {
+	if os.Getenv(clock.Env) == "real" {
+		return "real"
+	}
+	return "fake"
+}
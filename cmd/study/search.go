@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"study/internal/search"
+)
+
+func init() {
+	register("search", &command{
+		usage: "search [-n count] <query>",
+		short: "search lessons, comments, map.md and the slides (Chinese and English)",
+		run:   runSearch,
+	})
+}
+
+func runSearch(args []string) error {
+	fs := newFlagSet("search")
+	n := fs.Int("n", 10, "show at most `count` results")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing query")
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	ix, err := search.Build(c)
+	if err != nil {
+		return err
+	}
+	results := ix.Search(strings.Join(fs.Args(), " "), *n)
+	if len(results) == 0 {
+		return fmt.Errorf("nothing matches %q", strings.Join(fs.Args(), " "))
+	}
+	for _, r := range results {
+		fmt.Printf("%s  %s", r.Location, r.Doc.Name)
+		if r.Doc.Title != "" {
+			fmt.Printf("  %s", r.Doc.Title)
+		}
+		fmt.Println()
+		if r.Text != r.Doc.Title {
+			fmt.Printf("    %s\n", r.Text)
+		}
+	}
+	return nil
+}
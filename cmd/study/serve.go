@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"study/internal/playground"
+	"study/internal/progress"
+)
+
+func init() {
+	register("serve", &command{
+		usage: "serve [-addr host:port]",
+		short: "start the local web playground",
+		run:   runServe,
+	})
+}
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", "localhost:8080", "listen on `address`")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	s := playground.New(c)
+	if s.Progress, err = progress.NewTracker(); err != nil {
+		fmt.Fprintf(os.Stderr, "study: progress will not be recorded: %v\n", err)
+	}
+	if os.Getuid() != 0 {
+		fmt.Fprintln(os.Stderr, "study: not running as root, submitted code runs as you and can read and write your files")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("serving the lessons on http://%s\n", *addr)
+	return playground.Serve(ctx, *addr, s)
+}
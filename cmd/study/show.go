@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/i18n"
+	"study/internal/progress"
+)
+
+func init() {
+	register("show", &command{
+		usage: "show [-lang lang] <package> <lesson>",
+		short: "print the explanation comment above a lesson",
+		run:   runShow,
+	})
+}
+
+func runShow(args []string) error {
+	fs := newFlagSet("show")
+	lang := langFlag(fs)
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	l, err := lessonArgs(c, fs.Args())
+	if err != nil {
+		return err
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+
+	track(func(p *progress.Progress, now time.Time) { p.View(l.Path(), now) })
+
+	fmt.Printf("%s  %s\n", l.Path(), t.Title(l))
+	fmt.Printf("%s:%d\n", l.File, l.Line)
+	for _, ref := range slideRefs(c, l) {
+		fmt.Printf("slides: %s\n", ref)
+	}
+	fmt.Println()
+	comment := l.Comment
+	if t.Lang != i18n.Source {
+		if comment, err = translatedComment(c, l, t); err != nil {
+			return err
+		}
+	}
+	if comment == "" {
+		fmt.Println("(no comment)")
+		return nil
+	}
+	fmt.Println(comment)
+	return nil
+}
+
+// translatedComment 返回示例上方说明注释的译文。没有译文或译文过期的注释显示中文，并在 stderr 上提示。
+func translatedComment(c *course.Course, l *course.Lesson, t *i18n.Texts) (string, error) {
+	notes, err := course.Notes(c.Root, l.Package)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for i, key := range i18n.NoteKeys(l.Package, notes) {
+		if notes[i].Lesson != l.Name {
+			continue
+		}
+		text, ok := t.Lookup(key, notes[i].Text)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "study: no up-to-date %s translation for %s\n", t.Lang, key)
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"study/internal/i18n"
+	"study/internal/site"
+)
+
+func init() {
+	register("site", &command{
+		usage: "site [-lang lang] [-o dir]",
+		short: "render the whole course into a static HTML site",
+		run:   runSite,
+	})
+}
+
+func runSite(args []string) error {
+	fs := newFlagSet("site")
+	out := fs.String("o", "site", "write the pages to `dir`")
+	lang := langFlag(fs)
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+	pages, err := site.Build(c, *out, t)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote %d pages, open %s\n", len(pages), filepath.Join(*out, "index.html"))
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"study/internal/i18n"
+	"study/internal/slides"
+)
+
+func init() {
+	register("slides", &command{
+		usage: "slides [-lang lang] [-o dir] [-reveal url] <package|chapter>...",
+		short: "generate reveal.js slide decks from lesson packages",
+		run:   runSlides,
+	})
+}
+
+func runSlides(args []string) error {
+	fs := newFlagSet("slides")
+	out := fs.String("o", ".", "write the files to `dir`")
+	reveal := fs.String("reveal", "", "load reveal.js from `url` instead of inlining the vendored copy")
+	lang := langFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("want <package|chapter>...")
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	pkgs, err := packagesOf(c, fs.Args())
+	if err != nil {
+		return err
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	for _, p := range pkgs {
+		deck, err := slides.Build(c, p, t)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		if err := deck.Write(&b, *reveal); err != nil {
+			return err
+		}
+		name := filepath.Join(*out, slides.FileName(p))
+		if err := os.WriteFile(name, b.Bytes(), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s (%d slides)\n", name, len(deck.Slides))
+	}
+	return nil
+}
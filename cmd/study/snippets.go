@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"study/internal/course"
+	"study/internal/snippet"
+)
+
+func init() {
+	register("snippets", &command{
+		usage: "snippets [-v] [package...]",
+		short: "compile-check the Go snippets in comments and Markdown",
+		run:   runSnippets,
+	})
+}
+
+func runSnippets(args []string) error {
+	fs := newFlagSet("snippets")
+	verbose := fs.Bool("v", false, "list every snippet, not only the broken ones")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	var pkgs []*course.Package
+	for _, dir := range fs.Args() {
+		p := c.Package(dir)
+		if p == nil {
+			return fmt.Errorf("no package %s", dir)
+		}
+		pkgs = append(pkgs, p)
+	}
+	if len(pkgs) == 0 {
+		for _, ch := range c.Chapters {
+			pkgs = append(pkgs, ch.Packages...)
+		}
+	}
+
+	checker := snippet.NewChecker()
+	var total, pseudo, broken int
+	for _, p := range pkgs {
+		snippets, err := snippet.Extract(c.Root, p)
+		if err != nil {
+			return err
+		}
+		for _, s := range snippets {
+			total++
+			if s.Pseudo {
+				pseudo++
+				if *verbose {
+					fmt.Printf("%s:%d: pseudo-code, skipped\n", s.File, s.Line)
+				}
+				continue
+			}
+			problems := checker.Check(s)
+			if len(problems) > 0 {
+				broken++
+			} else if *verbose {
+				fmt.Printf("%s:%d: ok\n", s.File, s.Line)
+			}
+			for _, pr := range problems {
+				fmt.Println(pr)
+			}
+		}
+	}
+	fmt.Printf("%d snippets, %d pseudo-code, %d broken\n", total, pseudo, broken)
+	if broken > 0 {
+		return fmt.Errorf("%d broken snippets (fix them, or mark them as pseudo-code with %q on the line above or ```go pseudo)", broken, snippet.PseudoMarker)
+	}
+	return nil
+}
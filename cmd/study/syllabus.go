@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"study/internal/syllabus"
+)
+
+func init() {
+	register("syllabus", &command{
+		usage: "syllabus [-json | -check] [file.xmind]",
+		short: "print the course outline from the mind map, or check it against the lessons",
+		run:   runSyllabus,
+	})
+}
+
+func runSyllabus(args []string) error {
+	fs := newFlagSet("syllabus")
+	asJSON := fs.Bool("json", false, "print the topic tree as JSON")
+	check := fs.Bool("check", false, "list topics without lessons and lessons missing from the outline")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	path := fs.Arg(0)
+	if path == "" {
+		matches, _ := filepath.Glob(filepath.Join(c.Root, "*.xmind"))
+		if len(matches) != 1 {
+			return fmt.Errorf("found %d .xmind files in %s, name one", len(matches), c.Root)
+		}
+		path = matches[0]
+	}
+	sheets, err := syllabus.Read(path)
+	if err != nil {
+		return err
+	}
+
+	if *check {
+		cov, err := syllabus.Check(c, sheets)
+		if err != nil {
+			return err
+		}
+		return cov.WriteReport(os.Stdout)
+	}
+	syllabus.Match(sheets, c.Lessons())
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sheets)
+	}
+	return syllabus.WriteMarkdown(os.Stdout, sheets)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"study/internal/runner"
+	"study/internal/tutor"
+)
+
+func init() {
+	register("tutor", &command{
+		usage: "tutor [-o dir] [-timeout d] <package> <lesson>",
+		short: "trace a lesson statement by statement and draw its memory as an HTML timeline",
+		run:   runTutor,
+	})
+}
+
+func runTutor(args []string) error {
+	fs := newFlagSet("tutor")
+	out := fs.String("o", ".", "write the page to `dir`")
+	timeout := fs.Duration("timeout", 30*time.Second, "kill the lesson after `d`")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	l, err := lessonArgs(c, fs.Args())
+	if err != nil {
+		return err
+	}
+	t, res, err := tutor.Run(context.Background(), c.Root, l, runner.Options{Timeout: *timeout})
+	if err != nil {
+		return err
+	}
+	if len(res.Stderr) > 0 {
+		os.Stderr.Write(res.Stderr)
+	}
+
+	var b bytes.Buffer
+	if err := tutor.Write(&b, c.Root, l, t); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	name := filepath.Join(*out, tutor.FileName(l))
+	if err := os.WriteFile(name, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s (%d steps)\n", name, len(t.Steps))
+	if t.Truncated {
+		fmt.Println("the lesson ran too many statements, only the first ones were recorded")
+	}
+	return nil
+}
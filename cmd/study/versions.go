@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/lesson"
+	"study/internal/runner"
+	"study/internal/textdiff"
+	"study/internal/versions"
+)
+
+func init() {
+	register("versions", &command{
+		usage: "versions [-old v] [-new v] [-all] [-timeout d] [package [lesson...]]",
+		short: "compare lesson output under different go directives (loop variable semantics)",
+		run:   runVersions,
+	})
+}
+
+// sideWidth 并排比较时左边一栏的宽度。
+const sideWidth = 38
+
+func runVersions(args []string) error {
+	fs := newFlagSet("versions")
+	oldVersion := fs.String("old", "", "the old language `version`, default the go directive in go.mod")
+	newVersion := fs.String("new", versions.Loopvar, "the new language `version`")
+	all := fs.Bool("all", false, "run every lesson in the package, not only the flagged ones")
+	timeout := fs.Duration("timeout", 10*time.Second, "kill a lesson after `d`")
+	fs.Parse(args)
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	if *oldVersion == "" {
+		if *oldVersion, err = versions.GoVersion(c.Root); err != nil {
+			return err
+		}
+	}
+	for _, v := range []string{*oldVersion, *newVersion} {
+		if err := versions.Supported(v); err != nil {
+			return err
+		}
+	}
+	if fs.NArg() == 0 {
+		return listVersionFindings(c)
+	}
+
+	p := c.Package(strings.Trim(fs.Arg(0), "/"))
+	if p == nil {
+		return fmt.Errorf("no package %s", fs.Arg(0))
+	}
+	findings, err := versions.Check(c.Root, p)
+	if err != nil {
+		return err
+	}
+	var lessons []*course.Lesson
+	switch {
+	case fs.NArg() > 1:
+		for _, name := range fs.Args()[1:] {
+			l, err := c.Lesson(p.Dir, name)
+			if err != nil {
+				return err
+			}
+			lessons = append(lessons, l)
+		}
+	case *all:
+		for _, l := range p.Lessons {
+			if m := l.Meta; m != nil && (m.Expect == lesson.Blocks || m.Expect == lesson.Fatal) {
+				fmt.Printf("%s: skipped, %s on purpose\n", l.Name, m.Expect)
+				continue
+			}
+			lessons = append(lessons, l)
+		}
+	default:
+		lessons = versions.Lessons(findings)
+		if len(lessons) == 0 {
+			fmt.Printf("No lesson in %s depends on the language version; use -all to run them all.\n", p.Dir)
+			return nil
+		}
+	}
+
+	fmt.Printf("Running %d lesson(s) of %s with go %s and go %s ...\n", len(lessons), p.Dir, *oldVersion, *newVersion)
+	cs, err := versions.Compare(context.Background(), c.Root, p, lessons, *oldVersion, *newVersion, runner.Options{Timeout: *timeout})
+	if err != nil {
+		return err
+	}
+	differ := 0
+	for _, cmp := range cs {
+		l := cmp.Lesson
+		if !cmp.Differs() {
+			fmt.Printf("\n== %s: same output\n", l.Name)
+			continue
+		}
+		differ++
+		note := ""
+		if m := l.Meta; m != nil && (m.HasTag(lesson.TagRacy) || m.HasTag(lesson.TagUnordered) || m.HasTag(lesson.TagAddress)) {
+			note = " (its output changes from run to run anyway)"
+		}
+		fmt.Printf("\n== %s: output differs%s\n", l.Name, note)
+		for _, f := range findings {
+			if f.Lesson == l {
+				fmt.Printf("   %s\n", f)
+			}
+		}
+		fmt.Printf("%-*s   go %s\n", sideWidth, "go "+*oldVersion, *newVersion)
+		fmt.Print(textdiff.SideBySide(versionOutput(cmp.Old), versionOutput(cmp.New), sideWidth))
+	}
+	fmt.Printf("\n%d of %d lesson(s) behave differently under go %s and go %s.\n", differ, len(cs), *oldVersion, *newVersion)
+	return nil
+}
+
+// versionOutput 返回一次运行的输出，标准错误和退出状态附在后面。
+func versionOutput(res *runner.Result) string {
+	out := string(res.Stdout)
+	if len(res.Stderr) > 0 {
+		out += "-- stderr\n" + string(res.Stderr)
+	}
+	switch {
+	case res.TimedOut:
+		out += "-- timed out\n"
+	case res.ExitCode != 0:
+		out += fmt.Sprintf("-- exit status %d\n", res.ExitCode)
+	}
+	return out
+}
+
+// listVersionFindings 列出整个课程中行为可能取决于语言版本的示例，不运行。
+func listVersionFindings(c *course.Course) error {
+	n := 0
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			findings, err := versions.Check(c.Root, p)
+			if err != nil {
+				return err
+			}
+			for _, l := range versions.Lessons(findings) {
+				n++
+				fmt.Printf("%s\n", l.Path())
+				for _, f := range findings {
+					if f.Lesson == l {
+						fmt.Printf("    %s\n", f)
+					}
+				}
+			}
+		}
+	}
+	fmt.Printf("%d lesson(s) may behave differently since go %s; compare with: study versions <package> [lesson...]\n", n, versions.Loopvar)
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/grade"
+	"study/internal/i18n"
+	"study/internal/progress"
+	"study/internal/runner"
+	"study/internal/textdiff"
+	"study/internal/watch"
+)
+
+func init() {
+	register("watch", &command{
+		usage: "watch [-interval d] [-timeout d] [-lang lang] <package> [lesson ...]",
+		short: "re-run lessons whenever a file in their package changes",
+		run:   runWatch,
+	})
+}
+
+func runWatch(args []string) error {
+	fs := newFlagSet("watch")
+	interval := fs.Duration("interval", 500*time.Millisecond, "check the files every `d`")
+	timeout := fs.Duration("timeout", 30*time.Second, "kill a lesson after `d`")
+	lang := langFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("want <package> [lesson ...]")
+	}
+
+	c, err := loadCourse()
+	if err != nil {
+		return err
+	}
+	p := c.Package(fs.Arg(0))
+	if p == nil {
+		return fmt.Errorf("no package %s", fs.Arg(0))
+	}
+	lessons := p.Lessons
+	if fs.NArg() > 1 {
+		lessons = nil
+		for _, name := range fs.Args()[1:] {
+			l, err := c.Lesson(p.Dir, name)
+			if err != nil {
+				return err
+			}
+			lessons = append(lessons, l)
+		}
+	}
+	if len(lessons) == 0 {
+		return fmt.Errorf("%s has no lessons", p.Dir)
+	}
+	t, err := i18n.Load(c.Root, *lang)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	track(func(pr *progress.Progress, now time.Time) {
+		for _, l := range lessons {
+			pr.Run(l.Path(), now)
+		}
+	})
+	w := &watcher{root: c.Root, pkg: p, lessons: lessons, texts: t, timeout: *timeout,
+		output: map[string]string{}, score: map[string]int{}}
+	dir := filepath.Join(c.Root, filepath.FromSlash(p.Dir))
+	snap, err := watch.Scan(dir)
+	if err != nil {
+		return err
+	}
+	w.run(ctx)
+	for {
+		fmt.Printf("-- watching %s (Ctrl-C to stop)\n", p.Dir)
+		var changed []string
+		snap, changed, err = watch.Wait(ctx, []string{dir}, snap, *interval)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i, name := range changed {
+			changed[i] = filepath.Base(name)
+		}
+		fmt.Printf("\n== %s  %s changed\n", time.Now().Format("15:04:05"), strings.Join(changed, ", "))
+		w.run(ctx)
+	}
+}
+
+// watcher 记录每个示例上一次的输出和练习的分数，用来和这一次比较。
+type watcher struct {
+	root    string
+	pkg     *course.Package
+	lessons []*course.Lesson
+	texts   *i18n.Texts
+	timeout time.Duration
+
+	output map[string]string
+	score  map[string]int
+}
+
+// run 编译一次课程包，依次运行各个示例。
+func (w *watcher) run(ctx context.Context) {
+	main := len(w.lessons) == 1 && w.lessons[0].Name == course.MainLesson
+	bin, cleanup, err := runner.Build(ctx, w.root, w.pkg.Dir, main)
+	if err != nil {
+		fmt.Println(strings.TrimSpace(err.Error()))
+		return
+	}
+	defer cleanup()
+
+	dir := filepath.Join(w.root, filepath.FromSlash(w.pkg.Dir))
+	for _, l := range w.lessons {
+		res, err := runner.Exec(ctx, dir, bin, runner.Args(l.Name), runner.Options{Timeout: w.timeout, Combined: true})
+		if err != nil {
+			fmt.Printf("-- %s: %v\n", l.Name, err)
+			continue
+		}
+		status := fmt.Sprintf("ok (%v)", res.Duration.Round(time.Millisecond))
+		switch {
+		case res.TimedOut:
+			status = fmt.Sprintf("timed out after %v", w.timeout)
+		case res.ExitCode != 0:
+			status = fmt.Sprintf("FAIL (exit status %d)", res.ExitCode)
+		}
+		fmt.Printf("-- %s  %s  %s\n", l.Name, w.texts.Title(l), status)
+
+		out := string(res.Stdout)
+		prev, seen := w.output[l.Name]
+		w.output[l.Name] = out
+		switch {
+		case !seen:
+			printIndented(out, "   ")
+		case prev == out:
+			fmt.Println("   (output unchanged)")
+		default:
+			printIndented(textdiff.Compact(prev, out, 2), "  ")
+		}
+
+		if e := grade.Lookup(l.Path()); e != nil && e.ID == l.Path() {
+			w.grade(ctx, e)
+		}
+	}
+}
+
+// grade 给练习评分。第一次和分数变化时显示没有通过的用例并记录到学习进度，
+// 否则只显示分数。
+func (w *watcher) grade(ctx context.Context, e *grade.Exercise) {
+	r, err := grade.Grade(ctx, w.root, e, "")
+	if err != nil {
+		fmt.Printf("   grade: %v\n", err)
+		return
+	}
+	score := r.Score()
+	prev, seen := w.score[e.ID]
+	w.score[e.ID] = score
+	if seen && prev == score {
+		fmt.Println(scoreLine(r) + "  (unchanged)")
+		return
+	}
+	printReport(r, false)
+	if seen {
+		fmt.Printf("    (was %d/100)\n", prev)
+	}
+	track(func(p *progress.Progress, now time.Time) { p.Attempt(e.ID, score, now) })
+}
+
+func printIndented(s, indent string) {
+	if s == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		fmt.Println(indent + line)
+	}
+}
@@ -0,0 +1,97 @@
+// Package clock 给和时间有关的示例提供可以替换的时钟。
+//
+// 示例调用 clock.Sleep、clock.After 等函数代替 time 包中的同名函数，用法完全相同。
+// 默认使用虚拟时间（见 Fake）：所有 goroutine 都阻塞时时间直接跳到下一个定时器，
+// 示例在几毫秒内运行完，输出也不受机器快慢的影响。设置环境变量 STUDY_CLOCK=real
+// （或者 study run -clock real）使用真实的时间，可以亲眼看到程序在等待。
+package clock
+
+import (
+	"os"
+	"time"
+)
+
+// Clock 时钟，方法与 time 包中的同名函数相同。
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) *Timer
+	NewTicker(d time.Duration) *Ticker
+}
+
+// Timer 与 time.Timer 相同：到时后向 C 发送当时的时间。
+type Timer struct {
+	C     <-chan time.Time
+	stop  func() bool
+	reset func(d time.Duration) bool
+}
+
+// Stop 停止定时器，定时器还没有触发时返回 true。
+func (t *Timer) Stop() bool { return t.stop() }
+
+// Reset 让定时器在 d 之后触发，定时器还没有触发时返回 true。
+func (t *Timer) Reset(d time.Duration) bool { return t.reset(d) }
+
+// Ticker 与 time.Ticker 相同：每隔一段时间向 C 发送当时的时间，来不及接收的会被丢掉。
+type Ticker struct {
+	C     <-chan time.Time
+	stop  func()
+	reset func(d time.Duration)
+}
+
+// Stop 停止 Ticker，不会关闭 C。
+func (t *Ticker) Stop() { t.stop() }
+
+// Reset 把间隔改为 d，从现在开始计时。
+func (t *Ticker) Reset(d time.Duration) { t.reset(d) }
+
+// Real 真实的时钟，直接调用 time 包。
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTimer(d time.Duration) *Timer {
+	t := time.NewTimer(d)
+	return &Timer{C: t.C, stop: t.Stop, reset: t.Reset}
+}
+
+func (realClock) NewTicker(d time.Duration) *Ticker {
+	t := time.NewTicker(d)
+	return &Ticker{C: t.C, stop: t.Stop, reset: t.Reset}
+}
+
+// Env 选择时钟的环境变量，值为 real 时使用真实的时间。
+const Env = "STUDY_CLOCK"
+
+// Epoch 虚拟时间的起点。
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Default 示例使用的时钟，由环境变量 STUDY_CLOCK 决定。
+var Default = fromEnv()
+
+func fromEnv() Clock {
+	if os.Getenv(Env) == "real" {
+		return Real
+	}
+	return NewFake(Epoch, true)
+}
+
+// Now 返回 Default 的当前时间。
+func Now() time.Time { return Default.Now() }
+
+// Sleep 在 Default 上等待 d。
+func Sleep(d time.Duration) { Default.Sleep(d) }
+
+// After 返回 d 之后收到当时时间的通道。
+func After(d time.Duration) <-chan time.Time { return Default.After(d) }
+
+// NewTimer 返回 d 之后触发的定时器。
+func NewTimer(d time.Duration) *Timer { return Default.NewTimer(d) }
+
+// NewTicker 返回每隔 d 触发一次的 Ticker，d 必须大于 0。
+func NewTicker(d time.Duration) *Ticker { return Default.NewTicker(d) }
@@ -0,0 +1,103 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdvance(t *testing.T) {
+	f := NewFake(Epoch, false)
+	a := f.After(2 * time.Second)
+	b := f.NewTimer(time.Second)
+	tick := f.NewTicker(time.Second)
+
+	f.Advance(999 * time.Millisecond)
+	select {
+	case <-a:
+		t.Fatal("After fired early")
+	case <-b.C:
+		t.Fatal("Timer fired early")
+	default:
+	}
+	f.Advance(time.Millisecond)
+	if got := <-b.C; !got.Equal(Epoch.Add(time.Second)) {
+		t.Errorf("Timer fired at %v", got)
+	}
+	if b.Stop() {
+		t.Error("Stop of a fired Timer returned true")
+	}
+	if b.Reset(time.Second) {
+		t.Error("Reset of a fired Timer returned true")
+	}
+	if !b.Stop() {
+		t.Error("Stop of a pending Timer returned false")
+	}
+
+	<-tick.C
+	f.Advance(5 * time.Second) // 没有接收的 Ticker 时间被丢掉，只留最早的一个
+	if got := <-tick.C; !got.Equal(Epoch.Add(2 * time.Second)) {
+		t.Errorf("Ticker sent %v", got)
+	}
+	tick.Stop()
+	if got := <-a; !got.Equal(Epoch.Add(2 * time.Second)) {
+		t.Errorf("After fired at %v", got)
+	}
+	select {
+	case <-b.C:
+		t.Error("stopped Timer fired")
+	case <-tick.C:
+		t.Error("stopped Ticker fired")
+	default:
+	}
+	if got := f.Now(); !got.Equal(Epoch.Add(6 * time.Second)) {
+		t.Errorf("Now = %v", got)
+	}
+}
+
+func TestAuto(t *testing.T) {
+	f := NewFake(Epoch, true)
+	start := time.Now()
+
+	// 和 c6/3.concurrencyControl 的 TestC1 一样：先到时的先发送
+	out1, out2 := make(chan string), make(chan string)
+	go func() { f.Sleep(time.Second); out1 <- "test1" }()
+	go func() { f.Sleep(2 * time.Second); out2 <- "test2" }()
+	select {
+	case s := <-out1:
+		if s != "test1" {
+			t.Errorf("received %q", s)
+		}
+	case <-out2:
+		t.Error("the 2s sleeper woke up first")
+	}
+	<-out2
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for i := 1; i <= 3; i++ {
+		go func(d time.Duration) {
+			defer wg.Done()
+			f.Sleep(d * time.Hour)
+		}(time.Duration(i))
+	}
+	wg.Wait()
+
+	if got := f.Now().Sub(Epoch); got != 2*time.Second+3*time.Hour {
+		t.Errorf("virtual time passed %v", got)
+	}
+	if real := time.Since(start); real > time.Second {
+		t.Errorf("took %v of real time", real)
+	}
+}
+
+func TestReal(t *testing.T) {
+	start := time.Now()
+	Real.Sleep(time.Millisecond)
+	<-Real.After(time.Millisecond)
+	tm := Real.NewTimer(time.Millisecond)
+	<-tm.C
+	if time.Since(start) < 3*time.Millisecond || Real.Now().Before(start) {
+		t.Error("Real does not wait")
+	}
+}
@@ -0,0 +1,240 @@
+package clock
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake 虚拟时间的时钟，只有调用 Advance 或者自动前进时时间才会变化。
+//
+// 自动前进时，后台的 goroutine 在有定时器等待、并且其他 goroutine 都阻塞
+// （等待通道、锁、WaitGroup 等）时把时间跳到最早的定时器并触发它，
+// 然后等被唤醒的 goroutine 再次阻塞，再触发下一个。判断是否阻塞用的是 runtime.Stack 中
+// 各 goroutine 的状态，一直在计算、不阻塞的 goroutine 会让时间停下来。
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter // 按触发时间排列
+	auto    bool
+	running bool // 自动前进的 goroutine 正在运行
+}
+
+type waiter struct {
+	when   time.Time
+	period time.Duration // Ticker 的间隔，Timer 为 0
+	ch     chan time.Time
+}
+
+// NewFake 返回从 start 开始的虚拟时钟，auto 为 true 时自动前进。
+func NewFake(start time.Time, auto bool) *Fake {
+	return &Fake{now: start, auto: auto}
+}
+
+// Now 返回虚拟的当前时间。
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep 阻塞到虚拟时间过去 d。
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		runtime.Gosched()
+		return
+	}
+	<-f.After(d)
+}
+
+// After 返回虚拟时间过去 d 后收到当时时间的通道。
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C
+}
+
+// NewTimer 返回虚拟时间过去 d 后触发的定时器。
+func (f *Fake) NewTimer(d time.Duration) *Timer {
+	w := &waiter{ch: make(chan time.Time, 1)}
+	f.mu.Lock()
+	f.schedule(w, d)
+	f.mu.Unlock()
+	return &Timer{
+		C:    w.ch,
+		stop: func() bool { return f.stop(w) },
+		reset: func(d time.Duration) bool {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			active := f.remove(w)
+			f.schedule(w, d)
+			return active
+		},
+	}
+}
+
+// NewTicker 返回虚拟时间每过 d 触发一次的 Ticker。
+func (f *Fake) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &waiter{period: d, ch: make(chan time.Time, 1)}
+	f.mu.Lock()
+	f.schedule(w, d)
+	f.mu.Unlock()
+	return &Ticker{
+		C:    w.ch,
+		stop: func() { f.stop(w) },
+		reset: func(d time.Duration) {
+			if d <= 0 {
+				panic("clock: non-positive interval for Ticker.Reset")
+			}
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.remove(w)
+			w.period = d
+			f.schedule(w, d)
+		},
+	}
+}
+
+// Advance 把虚拟时间向前拨 d，依次触发期间到时的定时器。
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for len(f.waiters) > 0 && !f.waiters[0].when.After(end) {
+		f.fireNext()
+	}
+	f.now = end
+	f.mu.Unlock()
+}
+
+// schedule 让 w 在 d 之后触发，调用时持有 f.mu。
+func (f *Fake) schedule(w *waiter, d time.Duration) {
+	w.when = f.now.Add(d)
+	if d <= 0 {
+		fire(w, f.now)
+		return
+	}
+	i := sort.Search(len(f.waiters), func(i int) bool { return f.waiters[i].when.After(w.when) })
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+	if f.auto && !f.running {
+		f.running = true
+		go f.advance()
+	}
+}
+
+// remove 取消 w，w 还在等待时返回 true。调用时持有 f.mu。
+func (f *Fake) remove(w *waiter) bool {
+	for i, x := range f.waiters {
+		if x == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fake) stop(w *waiter) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.remove(w)
+}
+
+// fireNext 把时间拨到最早的定时器并触发它，Ticker 排入下一次。调用时持有 f.mu。
+func (f *Fake) fireNext() {
+	w := f.waiters[0]
+	f.waiters = f.waiters[1:]
+	if w.when.After(f.now) {
+		f.now = w.when
+	}
+	fire(w, f.now)
+	if w.period > 0 {
+		f.schedule(w, w.period)
+	}
+}
+
+// fire 不阻塞地发送触发时间，和 time 包一样，来不及接收的 Ticker 时间会被丢掉。
+func fire(w *waiter, now time.Time) {
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+// poll 自动前进时两次检查之间真实等待的时间。
+const poll = 50 * time.Microsecond
+
+// advance 自动前进：其他 goroutine 都阻塞时触发下一个定时器，没有定时器时退出。
+func (f *Fake) advance() {
+	self := goroutineID()
+	for {
+		time.Sleep(poll)
+		f.mu.Lock()
+		if len(f.waiters) == 0 {
+			f.running = false
+			f.mu.Unlock()
+			return
+		}
+		f.mu.Unlock()
+		// 连续两次都没有在运行的 goroutine 才算都阻塞了，中间让出处理器
+		if !idle(self) {
+			continue
+		}
+		runtime.Gosched()
+		if !idle(self) {
+			continue
+		}
+		f.mu.Lock()
+		if len(f.waiters) > 0 {
+			f.fireNext()
+		}
+		f.mu.Unlock()
+	}
+}
+
+// goroutineID 返回当前 goroutine 的编号。
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// goroutine 18 [running]:
+	fields := bytes.Fields(buf)
+	if len(fields) < 2 {
+		return ""
+	}
+	return string(fields[1])
+}
+
+// idle 判断除了编号为 self 的 goroutine 以外，是否都处于阻塞状态。
+func idle(self string) bool {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		// goroutine 7 [chan receive, 2 minutes]:
+		if !bytes.HasPrefix(line, []byte("goroutine ")) {
+			continue
+		}
+		fields := bytes.SplitN(line[len("goroutine "):], []byte(" "), 2)
+		if len(fields) < 2 || string(fields[0]) == self {
+			continue
+		}
+		state := string(bytes.Trim(fields[1], "[]:"))
+		if i := strings.IndexByte(state, ','); i >= 0 {
+			state = state[:i]
+		}
+		if state == "running" || state == "runnable" || state == "syscall" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,429 @@
+// Package course 扫描仓库中的课程目录（c1 ~ c6），解析每个课程包里的示例函数及其注释。
+package course
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"study/internal/lesson"
+)
+
+// MainLesson 是 package main 课程（例如 c1）对应的课程名，运行时使用 go run。
+const MainLesson = "main"
+
+// Course 整个课程，按章节组织。
+type Course struct {
+	Root     string
+	Chapters []*Chapter
+}
+
+// Chapter 一个章节，对应一个 cN 目录。
+type Chapter struct {
+	Name     string // c3
+	Title    string // 来自 README.md 的课程内容列表
+	Packages []*Package
+	Assets   []string // 章节目录下的 pptx 等资料，相对仓库根目录
+}
+
+// Package 一个课程包，对应一个包含 .go 文件的目录。
+type Package struct {
+	Dir     string // 相对仓库根目录，使用 / 分隔，例如 c3/5.slice
+	Name    string // go 包名
+	Files   []string
+	Lessons []*Lesson
+}
+
+// Lesson 一个示例，即一个 TestXxx 函数或 main 函数。
+// 示例在 lesson 包中登记过时（需要导入 study/internal/lesson/all），标题使用登记的标题。
+type Lesson struct {
+	Package *Package
+	Name    string // Test_S9
+	Title   string
+	Comment string // 函数上方的说明注释
+	File    string // 相对仓库根目录
+	Line    int
+	EndLine int
+	Meta    *lesson.Lesson // lesson 包中的登记信息，未登记时为 nil
+}
+
+// Path 返回 包目录/函数名 形式的唯一标识。
+func (l *Lesson) Path() string {
+	return l.Package.Dir + "/" + l.Name
+}
+
+var chapterDir = regexp.MustCompile(`^c\d+$`)
+
+// Load 读取 root 下的所有章节。
+func Load(root string) (*Course, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	titles := readmeTitles(filepath.Join(root, "README.md"))
+
+	c := &Course{Root: root}
+	for _, e := range entries {
+		if !e.IsDir() || !chapterDir.MatchString(e.Name()) {
+			continue
+		}
+		ch, err := loadChapter(root, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if n := chapterNumber(ch.Name); n > 0 && n <= len(titles) {
+			ch.Title = titles[n-1]
+		}
+		c.Chapters = append(c.Chapters, ch)
+	}
+	sort.Slice(c.Chapters, func(i, j int) bool {
+		return chapterNumber(c.Chapters[i].Name) < chapterNumber(c.Chapters[j].Name)
+	})
+	return c, nil
+}
+
+// Package 按目录查找课程包，目录可以带或不带结尾的 /。
+func (c *Course) Package(dir string) *Package {
+	dir = strings.Trim(filepath.ToSlash(dir), "/")
+	dir = strings.TrimPrefix(dir, "./")
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			if p.Dir == dir {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// Lesson 按包目录和函数名查找示例。
+func (c *Course) Lesson(dir, name string) (*Lesson, error) {
+	p := c.Package(dir)
+	if p == nil {
+		return nil, fmt.Errorf("course: no lesson package %q", dir)
+	}
+	for _, l := range p.Lessons {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("course: no lesson %s in %s", name, p.Dir)
+}
+
+// Lessons 返回全部示例，按章节和包的顺序。
+func (c *Course) Lessons() []*Lesson {
+	var ls []*Lesson
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			ls = append(ls, p.Lessons...)
+		}
+	}
+	return ls
+}
+
+func loadChapter(root, name string) (*Chapter, error) {
+	ch := &Chapter{Name: name}
+	err := filepath.Walk(filepath.Join(root, name), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if info.Name() == "testdata" {
+				return filepath.SkipDir
+			}
+			p, err := loadPackage(root, rel)
+			if err != nil {
+				return err
+			}
+			if p != nil {
+				ch.Packages = append(ch.Packages, p)
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".pptx", ".xmind":
+			ch.Assets = append(ch.Assets, rel)
+		}
+		return nil
+	})
+	return ch, err
+}
+
+func loadPackage(root, dir string) (*Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, filepath.Join(root, dir), nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	p := &Package{Dir: dir}
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") && len(pkgs) > 1 {
+			continue
+		}
+		p.Name = name
+		for filename, f := range pkg.Files {
+			if generated(f) {
+				continue
+			}
+			rel, _ := filepath.Rel(root, filename)
+			p.Files = append(p.Files, filepath.ToSlash(rel))
+			p.Lessons = append(p.Lessons, fileLessons(fset, p, filepath.ToSlash(rel), f)...)
+		}
+	}
+	sort.Strings(p.Files)
+	sort.SliceStable(p.Lessons, func(i, j int) bool {
+		a, b := p.Lessons[i], p.Lessons[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return p, nil
+}
+
+var generatedLine = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// generated 判断文件是不是生成的（例如 study examples -w 生成的 example_test.go）。
+// 生成的文件复制了示例的代码和注释，不算作课程内容。
+func generated(f *ast.File) bool {
+	for _, g := range f.Comments {
+		if g.Pos() > f.Package {
+			break
+		}
+		for _, c := range g.List {
+			if generatedLine.MatchString(c.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fileLessons(fset *token.FileSet, p *Package, file string, f *ast.File) []*Lesson {
+	var ls []*Lesson
+	prevEnd := f.Name.End()
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !isLesson(f, fn) {
+			continue
+		}
+		l := &Lesson{
+			Package: p,
+			Name:    fn.Name.Name,
+			File:    file,
+			Line:    fset.Position(fn.Pos()).Line,
+			EndLine: fset.Position(fn.End()).Line,
+		}
+		above := commentsBetween(fset, f, prevEnd, fn.Pos())
+		l.Comment = joinComments(above)
+		l.Title = title(f, fn, above)
+		if m, ok := lesson.Lookup(l.Path()); ok {
+			l.Meta = &m
+			l.Title = m.Title
+		}
+		ls = append(ls, l)
+		prevEnd = fn.End()
+	}
+	return ls
+}
+
+func isLesson(f *ast.File, fn *ast.FuncDecl) bool {
+	if fn.Recv != nil {
+		return false
+	}
+	if f.Name.Name == "main" && fn.Name.Name == "main" {
+		return true
+	}
+	name := fn.Name.Name
+	if !strings.HasPrefix(name, "Test") || len(name) == 4 {
+		return false
+	}
+	return fn.Type.Params != nil && len(fn.Type.Params.List) == 1
+}
+
+// commentsBetween 返回 [from, to) 之间不属于任何声明的注释组，包括函数自身的 Doc。
+// 写在声明行尾的注释属于该声明，也不返回。
+func commentsBetween(fset *token.FileSet, f *ast.File, from, to token.Pos) []*ast.CommentGroup {
+	var cgs []*ast.CommentGroup
+	for _, cg := range f.Comments {
+		if cg.Pos() < from || cg.End() > to {
+			continue
+		}
+		inside := false
+		for _, d := range f.Decls {
+			if d.Pos() <= cg.Pos() && cg.End() <= d.End() ||
+				fset.Position(d.End()).Line == fset.Position(cg.Pos()).Line {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			cgs = append(cgs, cg)
+		}
+	}
+	return cgs
+}
+
+func joinComments(cgs []*ast.CommentGroup) string {
+	var parts []string
+	for _, cg := range cgs {
+		if text := strings.TrimSpace(CommentText(cg)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// CommentText 与 CommentGroup.Text 类似，但保留行首缩进，注释里的代码片段才不会走样。
+func CommentText(cg *ast.CommentGroup) string {
+	var lines []string
+	for _, c := range cg.List {
+		text := c.Text
+		if strings.HasPrefix(text, "//") {
+			text = strings.TrimPrefix(text[2:], " ")
+			lines = append(lines, strings.TrimRight(text, " \t"))
+			continue
+		}
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	return strings.Join(trimIndent(lines), "\n")
+}
+
+// trimIndent 去掉所有非空行共同的前导空白。
+func trimIndent(lines []string) []string {
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimPrefix(line, prefix)
+	}
+	return out
+}
+
+// title 依次从函数 Doc、上方的注释（由远及近）、函数体内的注释中取第一行有意义的文字。
+func title(f *ast.File, fn *ast.FuncDecl, above []*ast.CommentGroup) string {
+	var cgs []*ast.CommentGroup
+	if fn.Doc != nil {
+		cgs = append(cgs, fn.Doc)
+	}
+	for _, cg := range above {
+		if cg != fn.Doc {
+			cgs = append(cgs, cg)
+		}
+	}
+	if fn.Body != nil {
+		cgs = append(cgs, bodyComments(f, fn)...)
+	}
+	for _, cg := range cgs {
+		if t := firstLine(CommentText(cg), fn.Name.Name); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+func bodyComments(f *ast.File, fn *ast.FuncDecl) []*ast.CommentGroup {
+	var cgs []*ast.CommentGroup
+	for _, cg := range f.Comments {
+		if fn.Body.Pos() < cg.Pos() && cg.End() < fn.Body.End() {
+			cgs = append(cgs, cg)
+		}
+	}
+	return cgs
+}
+
+func firstLine(text, name string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "•-* ")
+		switch {
+		case line == "", line == name, strings.HasPrefix(line, name+" "),
+			strings.HasPrefix(line, "@"), strings.HasSuffix(line, ":"), strings.HasSuffix(line, "："):
+			continue
+		}
+		const max = 40
+		if r := []rune(line); len(r) > max {
+			line = string(r[:max]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+func chapterNumber(name string) int {
+	var n int
+	fmt.Sscanf(name, "c%d", &n)
+	return n
+}
+
+// readmeTitles 读取 README.md 中 “课程内容” 下的编号列表。
+func readmeTitles(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	item := regexp.MustCompile(`^(\d+)\.\s+(.+)$`)
+	var titles []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if m := item.FindStringSubmatch(strings.TrimSpace(s.Text())); m != nil {
+			titles = append(titles, strings.TrimSpace(m[2]))
+		}
+	}
+	return titles
+}
+
+// FindRoot 从 dir 开始向上查找 module study 的 go.mod，返回仓库根目录。
+// 设置了环境变量 STUDY_ROOT 时直接使用它。
+func FindRoot(dir string) (string, error) {
+	if root := os.Getenv("STUDY_ROOT"); root != "" {
+		return filepath.Abs(root)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil && strings.Contains(string(data), "module study") {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("course: cannot find the study module root (set STUDY_ROOT)")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,53 @@
+package course
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	root, err := FindRoot(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Chapters) != 6 {
+		t.Fatalf("got %d chapters, want 6", len(c.Chapters))
+	}
+
+	tests := []struct {
+		dir, name string
+		comment   string
+	}{
+		{"c3/5.slice", "Test_S9", "超出原 slice.cap 限制"},
+		{"c3/5.slice", "Test_S2", "通过数组来初始化切片"},
+		{"c4/1.function", "TestF8", "defer特性"},
+		{"c6/2.channel", "TestC5", "判断通道是否已经关闭的操作"},
+		{"c1", MainLesson, ""},
+	}
+	for _, tt := range tests {
+		l, err := c.Lesson(tt.dir, tt.name)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(l.Comment, tt.comment) {
+			t.Errorf("%s comment = %q, want it to contain %q", l.Path(), l.Comment, tt.comment)
+		}
+	}
+
+	if _, err := c.Lesson("c4/2.map", "TestM9"); err == nil {
+		t.Error("found a lesson that does not exist")
+	}
+}
+
+func TestTrimIndent(t *testing.T) {
+	got := trimIndent([]string{"", "    a", "      b", "", "    c"})
+	want := []string{"", "a", "  b", "", "c"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("trimIndent = %q, want %q", got, want)
+	}
+}
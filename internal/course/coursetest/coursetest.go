@@ -0,0 +1,28 @@
+// Package coursetest 为其他包的测试加载仓库中的课程。
+package coursetest
+
+import (
+	"testing"
+
+	"study/internal/course"
+)
+
+// Root 返回仓库的根目录，找不到时测试失败。
+func Root(t testing.TB) string {
+	t.Helper()
+	root, err := course.FindRoot(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// Load 加载仓库中的课程，出错时测试失败。
+func Load(t testing.TB) *course.Course {
+	t.Helper()
+	c, err := course.Load(Root(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
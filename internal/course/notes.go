@@ -0,0 +1,83 @@
+package course
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// Note 课程包源码中不属于任何声明的一段说明注释，例如 arr_test.go 开头数组的特性。
+type Note struct {
+	File   string // 相对仓库根目录
+	Line   int
+	Lesson string // 注释后面的第一个示例，文件中其后没有示例时为空
+	Text   string
+}
+
+// Notes 返回 p 中的说明注释，按文件和行排列。写在声明行尾的注释、
+// 编辑器生成的函数注释模板（见 Boilerplate）不算。
+func Notes(root string, p *Package) ([]*Note, error) {
+	var notes []*Note
+	for _, name := range p.Files {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(name)), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		var fileNotes []*Note
+		for _, cg := range f.Comments {
+			if cg.Pos() < f.Package || inDecl(fset, f, cg) {
+				continue
+			}
+			text := strings.TrimSpace(CommentText(cg))
+			if Boilerplate(text) {
+				continue
+			}
+			fileNotes = append(fileNotes, &Note{File: name, Line: fset.Position(cg.Pos()).Line, Text: text})
+		}
+		for _, n := range fileNotes {
+			for _, l := range p.Lessons {
+				if l.File == name && l.Line > n.Line {
+					n.Lesson = l.Name
+					break
+				}
+			}
+		}
+		notes = append(notes, fileNotes...)
+	}
+	return notes, nil
+}
+
+// inDecl 判断注释是否在某个声明里面，或者写在声明结尾的同一行。
+func inDecl(fset *token.FileSet, f *ast.File, cg *ast.CommentGroup) bool {
+	for _, d := range f.Decls {
+		if d.Pos() <= cg.Pos() && cg.End() <= d.End() ||
+			fset.Position(d.End()).Line == fset.Position(cg.Pos()).Line {
+			return true
+		}
+	}
+	return false
+}
+
+// Boilerplate 判断注释是不是编辑器生成的函数注释模板，例如
+//
+//	// TestM1
+//	// @description:
+//	// parameter:
+//	//		@t:
+//	// return:
+func Boilerplate(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", strings.HasPrefix(line, "@"),
+			strings.HasPrefix(line, "Test") && !strings.Contains(line, " "),
+			strings.HasSuffix(line, ":") && !strings.Contains(line, " "):
+		default:
+			return false
+		}
+	}
+	return true
+}
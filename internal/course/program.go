@@ -0,0 +1,114 @@
+package course
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// MainProgram 读取课程源文件 src，去掉 Test 函数和 main、init 函数，改写成 package main 的源码，
+// 供评分和 REPL 在临时模块中编译。keep 中的函数即使以 Test 开头也保留，src 中没有时返回错误。
+func MainProgram(src string, keep ...string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, src, nil, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	found := map[string]bool{}
+	var decls []ast.Decl
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if ok && fd.Recv == nil && contains(keep, fd.Name.Name) {
+			found[fd.Name.Name] = true
+		} else if ok && fd.Recv == nil && (strings.HasPrefix(fd.Name.Name, "Test") || fd.Name.Name == "main" || fd.Name.Name == "init") {
+			continue
+		}
+		decls = append(decls, d)
+	}
+	for _, fn := range keep {
+		if !found[fn] {
+			return "", fmt.Errorf("course: %s has no func %s", src, fn)
+		}
+	}
+	f.Name.Name = "main"
+	f.Decls = decls
+	f.Comments = nil
+	for _, d := range decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			d.Doc = nil
+		case *ast.GenDecl:
+			d.Doc = nil
+		}
+	}
+	dropUnusedImports(f)
+
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, f); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// dropUnusedImports 删除去掉 Test 函数后不再使用的 import。
+func dropUnusedImports(f *ast.File) {
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var specs []ast.Spec
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		kept := gd.Specs[:0]
+		for _, s := range gd.Specs {
+			is := s.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(is.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if is.Name != nil {
+				name = is.Name.Name
+			}
+			if used[name] || name == "_" {
+				kept = append(kept, s)
+			}
+		}
+		gd.Specs = kept
+		specs = append(specs, kept...)
+	}
+	imports := f.Imports[:0]
+	for _, s := range specs {
+		imports = append(imports, s.(*ast.ImportSpec))
+	}
+	f.Imports = imports
+
+	decls := f.Decls[:0]
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT && len(gd.Specs) == 0 {
+			continue
+		}
+		decls = append(decls, d)
+	}
+	f.Decls = decls
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
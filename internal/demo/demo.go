@@ -0,0 +1,171 @@
+// Package demo 运行那些故意出错的示例：panic、死锁、fatal error 或者一直阻塞。
+//
+// 这些示例如果直接在 go test 中运行，会让整个测试失败或卡住。Run 会在子进程中
+// 重新运行当前测试，子进程里才真正执行示例代码；父进程带超时等待子进程结束，
+// 判断结果是否符合预期，符合时测试通过，其他示例也就能在 CI 中正常运行。
+//
+//	func TestP5(t *testing.T) {
+//		demo.Run(t, func() {
+//			var a *int
+//			*a = 10
+//		}, demo.Panic("nil pointer dereference"))
+//	}
+package demo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Env 子进程中设置为当前测试名的环境变量。
+const Env = "STUDY_DEMO"
+
+// Timeout 等待子进程的时间，超过后认为示例一直阻塞（Hang）。
+var Timeout = 3 * time.Second
+
+// Kind 示例的结局。
+type Kind int
+
+const (
+	KindExit     Kind = iota // 正常结束
+	KindPanic                // panic
+	KindDeadlock             // fatal error: all goroutines are asleep - deadlock!
+	KindFatal                // 其他 fatal error，例如 concurrent map writes
+	KindHang                 // 超时仍未结束
+)
+
+var kindNames = [...]string{
+	KindExit:     "exit",
+	KindPanic:    "panic",
+	KindDeadlock: "deadlock",
+	KindFatal:    "fatal error",
+	KindHang:     "hang",
+}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// Outcome 预期的结局：结局类型以及输出中应包含的文字。
+type Outcome struct {
+	Kind    Kind
+	Message string
+}
+
+// Panic 预期 panic，且 panic 信息包含 msg。
+func Panic(msg string) Outcome { return Outcome{KindPanic, msg} }
+
+// Fatal 预期运行时 fatal error，且错误信息包含 msg。
+func Fatal(msg string) Outcome { return Outcome{KindFatal, msg} }
+
+// Deadlock 预期所有 goroutine 都阻塞，运行时报告死锁。
+var Deadlock = Outcome{Kind: KindDeadlock, Message: "all goroutines are asleep"}
+
+// Hang 预期示例一直阻塞，直到超时被杀掉。
+var Hang = Outcome{Kind: KindHang}
+
+// Result 子进程运行的结果。
+type Result struct {
+	Kind   Kind
+	Stdout []byte
+	Stderr []byte
+}
+
+// Match 判断结果是否符合预期。
+func (r *Result) Match(o Outcome) bool {
+	return r.Kind == o.Kind && bytes.Contains(r.Stderr, []byte(o.Message))
+}
+
+// Run 在子进程中运行 fn，结果符合 want 中任意一个时测试通过。
+// 子进程的输出原样转发到当前进程，学员仍然能看到 panic 信息。
+func Run(t *testing.T, fn func(), want ...Outcome) {
+	t.Helper()
+	if os.Getenv(Env) == t.Name() {
+		fn()
+		return
+	}
+
+	res, err := exec1(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout.Write(bytes.TrimSuffix(res.Stdout, []byte("PASS\n")))
+	os.Stderr.Write(res.Stderr)
+
+	for _, o := range want {
+		if res.Match(o) {
+			t.Logf("demo ended with %s as expected", res.Kind)
+			return
+		}
+	}
+	t.Errorf("demo ended with %s, want %s", res.Kind, describe(want))
+}
+
+// exec1 以子进程方式重新运行测试二进制中的 name 测试。
+func exec1(name string) (*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	// -test.timeout=0：测试框架的超时计时器会让运行时检测不到死锁
+	cmd := exec.CommandContext(ctx, self(), "-test.run=^"+name+"$", "-test.count=1", "-test.timeout=0")
+	cmd.Env = append(os.Environ(), Env+"="+name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	res := &Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		res.Kind = KindHang
+	case err == nil:
+		res.Kind = KindExit
+	case !errors.As(err, &exitErr):
+		return nil, err
+	default:
+		res.Kind = classify(res.Stderr)
+	}
+	return res, nil
+}
+
+// self 返回重新运行测试二进制用的路径。Linux 上用 /proc/self/exe：在沙箱中降为其他用户运行时，
+// 测试二进制所在的目录不允许进入，os.Args[0] 无法执行。
+func self() string {
+	if runtime.GOOS == "linux" {
+		if _, err := os.Lstat("/proc/self/exe"); err == nil {
+			return "/proc/self/exe"
+		}
+	}
+	return os.Args[0]
+}
+
+func classify(stderr []byte) Kind {
+	s := string(stderr)
+	switch {
+	case strings.Contains(s, Deadlock.Message):
+		return KindDeadlock
+	case strings.Contains(s, "fatal error:"):
+		return KindFatal
+	case strings.Contains(s, "panic:"):
+		return KindPanic
+	}
+	return KindExit
+}
+
+func describe(want []Outcome) string {
+	var s []string
+	for _, o := range want {
+		if o.Message != "" {
+			s = append(s, o.Kind.String()+" "+`"`+o.Message+`"`)
+		} else {
+			s = append(s, o.Kind.String())
+		}
+	}
+	return strings.Join(s, " or ")
+}
@@ -0,0 +1,43 @@
+package demo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPanic(t *testing.T) {
+	Run(t, func() {
+		var m map[string]int
+		m["a"] = 1
+	}, Panic("assignment to entry in nil map"))
+}
+
+func TestDeadlock(t *testing.T) {
+	Run(t, func() {
+		ch := make(chan int)
+		ch <- 1
+	}, Deadlock)
+}
+
+func TestExited(t *testing.T) {
+	Run(t, func() {
+		fmt.Println("ok")
+	}, Outcome{Kind: KindExit})
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   Kind
+	}{
+		{"panic: runtime error: index out of range", KindPanic},
+		{"fatal error: all goroutines are asleep - deadlock!", KindDeadlock},
+		{"fatal error: concurrent map writes", KindFatal},
+		{"--- FAIL: TestX", KindExit},
+	}
+	for _, tt := range tests {
+		if got := classify([]byte(tt.stderr)); got != tt.want {
+			t.Errorf("classify(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
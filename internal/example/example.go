@@ -0,0 +1,449 @@
+// Package example 把只打印确定输出的示例转换成带 // Output: 的 Example 函数，
+// 这样 go test 会检查输出，go doc 也能显示它们。
+//
+// 转换时先静态检查示例：打印地址（%p）、遍历 map、依赖 goroutine 调度、用到 t 的示例不能转换；
+// 再运行两次，输出必须相同，且不写 stderr；复制成 Example 后 go vet 报告问题的示例
+// （故意演示错误写法的）也不转换。Example 的函数体原样复制示例的函数体，
+// Output 块是实际的输出。生成的文件是 <包目录>/example_test.go，不要手工修改。
+package example
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/lesson"
+	"study/internal/runner"
+)
+
+// File 生成的文件名。
+const File = "example_test.go"
+
+// Header 生成的文件的第一行，course 包据此跳过这个文件。
+const Header = `// Code generated by "study examples -w"; DO NOT EDIT.`
+
+// Conversion 一个示例的转换结果。
+type Conversion struct {
+	Lesson *course.Lesson
+	Name   string // Example 函数名，例如 Example_p6
+	Output string // 示例的标准输出
+	Reason string // 不能转换的原因，为空表示可以转换
+}
+
+// OK 示例能否转换。
+func (c *Conversion) OK() bool {
+	return c.Reason == ""
+}
+
+// Name 返回示例对应的 Example 函数名：TestP6 → Example_p6，Test_S7 → Example_s7。
+func Name(test string) string {
+	s := strings.TrimPrefix(test, "Test")
+	s = strings.ReplaceAll(s, "_", "")
+	return "Example_" + strings.ToLower(s)
+}
+
+var address = regexp.MustCompile(`0x[0-9a-f]{6,}`)
+
+// Convert 检查并运行包 p 中的示例，返回每个示例的转换结果。
+func Convert(ctx context.Context, root string, p *course.Package) ([]*Conversion, error) {
+	src, err := parsePackage(root, p)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		cs    []*Conversion
+		run   []*Conversion
+		names = map[string]string{}
+	)
+	for _, l := range p.Lessons {
+		c := &Conversion{Lesson: l, Name: Name(l.Name)}
+		cs = append(cs, c)
+		c.Reason = src.reason(l)
+		if c.Reason == "" && l.Name != course.MainLesson {
+			if other, ok := names[c.Name]; ok {
+				c.Reason = fmt.Sprintf("%s is taken by %s", c.Name, other)
+			}
+			names[c.Name] = l.Name
+		}
+		if c.Reason == "" {
+			run = append(run, c)
+		}
+	}
+	if len(run) == 0 {
+		return cs, nil
+	}
+
+	// 单独运行示例时不编译旧的 example_test.go，它可能引用已经改名的函数
+	file := path.Join(p.Dir, File)
+	empty := map[string][]byte{file: []byte("package " + p.Name + "\n")}
+	bin, cleanup, err := runner.BuildWith(ctx, root, p.Dir, false, empty)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	dir := filepath.Join(root, filepath.FromSlash(p.Dir))
+	for _, c := range run {
+		var outputs [2]string
+		for i := 0; i < len(outputs) && c.Reason == ""; i++ {
+			res, err := runner.Exec(ctx, dir, bin, runner.Args(c.Lesson.Name), runner.Options{Timeout: 30 * time.Second})
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case res.Failed():
+				c.Reason = fmt.Sprintf("fails (exit status %d)", res.ExitCode)
+			case len(res.Stderr) > 0:
+				c.Reason = "writes to stderr (println), which // Output: does not check"
+			}
+			outputs[i] = string(res.Stdout)
+		}
+		if c.Reason == "" {
+			c.Output = outputs[0]
+			c.Reason = outputReason(outputs[0], outputs[1])
+		}
+	}
+	return cs, verify(ctx, root, p, cs)
+}
+
+var (
+	failedExample = regexp.MustCompile(`(?m)^--- FAIL: (Example_\w+) `)
+	vetProblem    = regexp.MustCompile(`(?m)` + regexp.QuoteMeta(File) + `:(\d+):\d+: (.*)$`)
+)
+
+// verify 像 go test 一样运行整个包的测试和生成的 Example。示例单独运行和跟在其他示例后面运行时，
+// 输出可能不同（例如 sync.Once 已经执行过），这样的示例改为不能转换。
+func verify(ctx context.Context, root string, p *course.Package, cs []*Conversion) error {
+	dir := filepath.Join(root, filepath.FromSlash(p.Dir))
+	for {
+		src, err := Generate(root, p, cs)
+		if err != nil || src == nil {
+			return err
+		}
+		if changed, err := vet(ctx, root, p, src, cs); err != nil || changed {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		bin, cleanup, err := runner.BuildWith(ctx, root, p.Dir, false, map[string][]byte{path.Join(p.Dir, File): src})
+		if err != nil {
+			return err
+		}
+		res, err := runner.Exec(ctx, dir, bin, []string{"-test.count", "1"}, runner.Options{Timeout: 2 * time.Minute})
+		cleanup()
+		if err != nil || !res.Failed() {
+			return err
+		}
+		changed := false
+		for _, m := range failedExample.FindAllStringSubmatch(string(res.Stdout), -1) {
+			for _, c := range cs {
+				if c.OK() && c.Name == m[1] {
+					c.Reason = "output changes after the other lessons run"
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			return fmt.Errorf("example: %s: tests fail (exit status %d)\n%s%s", p.Dir, res.ExitCode, res.Stdout, res.Stderr)
+		}
+	}
+}
+
+// vet 对生成的文件运行 go vet。有的示例故意演示 vet 能查出来的错误（例如闭包捕获循环变量），
+// 复制成 Example 后 vet 会多报一处，这样的示例改为不能转换。返回是否有示例因此改变。
+func vet(ctx context.Context, root string, p *course.Package, src []byte, cs []*Conversion) (bool, error) {
+	out, err := runner.Vet(ctx, root, p.Dir, map[string][]byte{path.Join(p.Dir, File): src})
+	if err != nil {
+		return false, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, File, src, 0)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for _, m := range vetProblem.FindAllStringSubmatch(string(out), -1) {
+		line, _ := strconv.Atoi(m[1])
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || line < fset.Position(fn.Pos()).Line || line > fset.Position(fn.End()).Line {
+				continue
+			}
+			for _, c := range cs {
+				if c.OK() && c.Name == fn.Name.Name {
+					c.Reason = "trips go vet (" + m[2] + "), the lesson shows the mistake on purpose"
+					changed = true
+				}
+			}
+		}
+	}
+	return changed, nil
+}
+
+// outputReason 检查两次运行的输出能否写成 // Output: 注释。
+// go test 比较前去掉输出首尾的空白，注释中行尾的空白和连续的空行却会丢失。
+func outputReason(first, second string) string {
+	out := strings.TrimSpace(first)
+	switch {
+	case first != second:
+		return "output differs between runs"
+	case out == "":
+		return "prints nothing"
+	case address.MatchString(out):
+		return "prints addresses"
+	case strings.Contains(out, "\n\n\n"):
+		return "prints consecutive blank lines, which // Output: cannot keep"
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimRight(line, " \t") != line {
+			return "prints trailing spaces, which // Output: cannot keep"
+		}
+	}
+	return ""
+}
+
+// source 课程包的源文件和类型信息。
+type source struct {
+	fset  *token.FileSet
+	files map[string]*ast.File // 相对仓库根目录的文件名
+	data  map[string][]byte
+	info  *types.Info
+}
+
+func parsePackage(root string, p *course.Package) (*source, error) {
+	s := &source{
+		fset:  token.NewFileSet(),
+		files: map[string]*ast.File{},
+		data:  map[string][]byte{},
+		info:  &types.Info{Types: map[ast.Expr]types.TypeAndValue{}, Uses: map[*ast.Ident]types.Object{}},
+	}
+	var files []*ast.File
+	for _, name := range p.Files {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(s.fset, name, data, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		s.files[name], s.data[name] = f, data
+		files = append(files, f)
+	}
+	// 只需要判断 range 的对象是不是 map，导入失败等错误不影响
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	conf.Check(p.Name, s.fset, files, s.info)
+	return s, nil
+}
+
+// fn 返回示例的函数声明。
+func (s *source) fn(l *course.Lesson) *ast.FuncDecl {
+	f := s.files[l.File]
+	if f == nil {
+		return nil
+	}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == l.Name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// reason 返回不运行就能看出的、示例不能转换的原因。
+func (s *source) reason(l *course.Lesson) string {
+	if l.Name == course.MainLesson {
+		return "is a main program, not a Test"
+	}
+	if m := l.Meta; m != nil {
+		switch {
+		case m.Exercise:
+			return "is an exercise, its output depends on the answer"
+		case m.Expect != lesson.Prints:
+			return fmt.Sprintf("%s on purpose", m.Expect)
+		case m.HasTag(lesson.TagAddress):
+			return "prints addresses (%p)"
+		case m.HasTag(lesson.TagUnordered):
+			return "depends on map iteration order"
+		case m.HasTag(lesson.TagRacy):
+			return "depends on goroutine scheduling"
+		case m.HasTag(lesson.TagSlow):
+			return "is slow"
+		}
+	}
+	fn := s.fn(l)
+	if fn == nil || fn.Body == nil {
+		return "has no function body"
+	}
+	reason := ""
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if reason != "" {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.BasicLit:
+			if n.Kind == token.STRING && strings.Contains(n.Value, "%p") {
+				reason = "prints addresses (%p)"
+			}
+		case *ast.RangeStmt:
+			if t := s.info.TypeOf(n.X); t != nil {
+				if _, ok := t.Underlying().(*types.Map); ok {
+					reason = "depends on map iteration order"
+				}
+			}
+		case *ast.Ident:
+			if obj := s.info.Uses[n]; obj != nil && isParam(fn, obj) {
+				reason = fmt.Sprintf("uses %s", n.Name)
+			}
+		}
+		return true
+	})
+	return reason
+}
+
+// isParam 判断 obj 是不是 fn 的参数 t *testing.T。
+func isParam(fn *ast.FuncDecl, obj types.Object) bool {
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			if name.Pos() == obj.Pos() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Generate 返回包含可以转换的示例的 example_test.go，没有可以转换的示例时返回 nil。
+func Generate(root string, p *course.Package, cs []*Conversion) ([]byte, error) {
+	src, err := parsePackage(root, p)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		body    bytes.Buffer
+		imports = map[string]bool{}
+	)
+	for _, c := range cs {
+		if !c.OK() {
+			continue
+		}
+		fn := src.fn(c.Lesson)
+		if fn == nil {
+			return nil, fmt.Errorf("example: no func %s in %s", c.Lesson.Name, c.Lesson.File)
+		}
+		for _, imp := range src.imports(c.Lesson.File, fn) {
+			imports[imp] = true
+		}
+		data := src.data[c.Lesson.File]
+		code := data[src.fset.Position(fn.Body.Lbrace).Offset+1 : src.fset.Position(fn.Body.Rbrace).Offset]
+
+		body.WriteString("\n")
+		writeComment(&body, "", doc(c.Lesson))
+		fmt.Fprintf(&body, "func %s() {", c.Name)
+		body.Write(bytes.TrimRight(code, " \t\n"))
+		body.WriteString("\n\n\t// Output:\n")
+		writeComment(&body, "\t", strings.TrimSpace(c.Output))
+		body.WriteString("}\n")
+	}
+	if body.Len() == 0 {
+		return nil, nil
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n\npackage %s\n", Header, p.Name)
+	if len(imports) > 0 {
+		b.WriteString("\nimport (\n")
+		for _, imp := range sortedKeys(imports) {
+			fmt.Fprintf(&b, "\t%s\n", imp)
+		}
+		b.WriteString(")\n")
+	}
+	b.Write(body.Bytes())
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("example: %s: %v", p.Dir, err)
+	}
+	return out, nil
+}
+
+// imports 返回 fn 用到的、file 中导入的包，已经加好引号和别名。
+func (s *source) imports(file string, fn *ast.FuncDecl) []string {
+	used := map[string]bool{}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	var imports []string
+	for _, spec := range s.files[file].Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !used[name] {
+			continue
+		}
+		if spec.Name != nil {
+			imports = append(imports, name+" "+spec.Path.Value)
+		} else {
+			imports = append(imports, spec.Path.Value)
+		}
+	}
+	return imports
+}
+
+// doc 返回 Example 的说明：示例的标题，以及标题之外的注释。
+func doc(l *course.Lesson) string {
+	comment := strings.TrimSpace(l.Comment)
+	switch {
+	case comment == "":
+		return l.Title
+	case strings.HasPrefix(comment, l.Title):
+		return comment
+	}
+	return l.Title + "\n\n" + comment
+}
+
+func writeComment(b *bytes.Buffer, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			fmt.Fprintf(b, "%s//\n", indent)
+		} else {
+			fmt.Fprintf(b, "%s// %s\n", indent, line)
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package example
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+func TestName(t *testing.T) {
+	for test, want := range map[string]string{
+		"TestP6":   "Example_p6",
+		"Test_S7":  "Example_s7",
+		"Test_S10": "Example_s10",
+		"TestM2":   "Example_m2",
+	} {
+		if got := Name(test); got != want {
+			t.Errorf("Name(%q) = %q, want %q", test, got, want)
+		}
+	}
+}
+
+func TestOutputReason(t *testing.T) {
+	for _, tt := range []struct {
+		first, second, want string
+	}{
+		{"25\n30\n", "25\n30\n", ""},
+		{"\n  a\n\nb\n", "\n  a\n\nb\n", ""},
+		{"1\n", "2\n", "output differs between runs"},
+		{"", "", "prints nothing"},
+		{"0xc000012345\n", "0xc000012345\n", "prints addresses"},
+		{"*int \n0\n", "*int \n0\n", "prints trailing spaces, which // Output: cannot keep"},
+		{"a\n\n\nb\n", "a\n\n\nb\n", "prints consecutive blank lines, which // Output: cannot keep"},
+	} {
+		if got := outputReason(tt.first, tt.second); got != tt.want {
+			t.Errorf("outputReason(%q) = %q, want %q", tt.first, got, tt.want)
+		}
+	}
+}
+
+// TestGenerated 检查各课程包的 example_test.go 是最新的，
+// 修改示例后用 study examples -w 重新生成。
+func TestGenerated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every lesson")
+	}
+	c := coursetest.Load(t)
+
+	reasons := map[string]string{}
+	done := map[*course.Package]bool{}
+	ctx := context.Background()
+	for _, l := range c.Lessons() {
+		if l.Name == course.MainLesson {
+			continue
+		}
+		p := l.Package
+		if done[p] {
+			continue
+		}
+		done[p] = true
+		cs, err := Convert(ctx, c.Root, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cv := range cs {
+			reasons[cv.Lesson.Path()] = cv.Reason
+		}
+		want, err := Generate(c.Root, p, cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(p.Dir), File))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s/%s is out of date, run study examples -w %s", p.Dir, File, p.Dir)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"c3/3.pointer/TestP6":            "prints trailing spaces",
+		"c4/1.function/TestF8":           "trips go vet (loop variable i captured by func literal)",
+		"c5/2.method/TestM2":             "",
+		"c3/5.slice/Test_S7":             "",
+		"c3/3.pointer/TestP1":            "prints addresses (%p)",
+		"c4/2.map/TestM2":                "depends on map iteration order",
+		"c3/3.pointer/TestP9":            "is an exercise",
+		"c6/3.concurrencyControl/TestS2": "output changes after the other lessons run",
+	} {
+		got, ok := reasons[path]
+		if !ok {
+			t.Errorf("%s: no such lesson", path)
+			continue
+		}
+		if !strings.HasPrefix(got, want) || (want == "") != (got == "") {
+			t.Errorf("%s: reason %q, want %q", path, got, want)
+		}
+	}
+}
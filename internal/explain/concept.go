@@ -0,0 +1,123 @@
+package explain
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Concept 一行代码用到的一种语法。Name 尽量和 lesson 包中登记的标签相同，
+// Words 是注释中提到它时可能用的说法。
+type Concept struct {
+	Name  string
+	Words []string
+}
+
+// mentionedIn 判断 text 是否提到了这种语法，不区分大小写。
+func (c *Concept) mentionedIn(text string) bool {
+	text = strings.ToLower(text)
+	for _, w := range c.Words {
+		if strings.Contains(text, strings.ToLower(w)) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	label     = &Concept{"label", []string{"label"}}
+	sliceCap  = &Concept{"cap", []string{"cap", "容量"}}
+	pointer   = &Concept{"pointer", []string{"指针", "pointer"}}
+	channel   = &Concept{"channel", []string{"通道", "channel"}}
+	goroutine = &Concept{"goroutine", []string{"goroutine", "协程"}}
+	deferred  = &Concept{"defer", []string{"defer"}}
+	selects   = &Concept{"select", []string{"select"}}
+	closure   = &Concept{"closure", []string{"闭包", "closure", "匿名函数"}}
+	ranges    = &Concept{"range", []string{"range"}}
+	maps      = &Concept{"map", []string{"map"}}
+	assertion = &Concept{"interface", []string{"类型断言", "断言"}}
+)
+
+// builtins 内置函数对应的语法。
+var builtins = map[string]*Concept{
+	"append":  {"append", []string{"append"}},
+	"cap":     sliceCap,
+	"copy":    {"copy", []string{"copy"}},
+	"make":    {"make", []string{"make"}},
+	"new":     {"new", []string{"new("}},
+	"close":   {"close", []string{"close", "关闭"}},
+	"delete":  {"delete", []string{"delete"}},
+	"panic":   {"panic", []string{"panic"}},
+	"recover": {"recover", []string{"recover"}},
+}
+
+// branches break、continue、goto 和 fallthrough。
+var branches = map[token.Token]*Concept{}
+
+func init() {
+	for _, tok := range []token.Token{token.BREAK, token.CONTINUE, token.GOTO, token.FALLTHROUGH} {
+		branches[tok] = &Concept{tok.String(), []string{tok.String()}}
+	}
+}
+
+// concepts 返回函数 fn 中从第 line 行开始的语法结构用到的语法，按第一次出现的顺序。
+func concepts(fset *token.FileSet, fn *ast.FuncDecl, line int) []*Concept {
+	var out []*Concept
+	add := func(c *Concept) {
+		for _, x := range out {
+			if x == c {
+				return
+			}
+		}
+		out = append(out, c)
+	}
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if n == nil || fset.Position(n.Pos()).Line != line {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.BranchStmt:
+			add(branches[n.Tok])
+			if n.Label != nil {
+				add(label)
+			}
+		case *ast.LabeledStmt:
+			add(label)
+		case *ast.SliceExpr:
+			if n.Slice3 {
+				add(sliceCap)
+			}
+		case *ast.CallExpr:
+			if id, ok := n.Fun.(*ast.Ident); ok && builtins[id.Name] != nil {
+				add(builtins[id.Name])
+			}
+		case *ast.UnaryExpr:
+			switch n.Op {
+			case token.AND:
+				add(pointer)
+			case token.ARROW:
+				add(channel)
+			}
+		case *ast.StarExpr:
+			add(pointer)
+		case *ast.SendStmt, *ast.ChanType:
+			add(channel)
+		case *ast.GoStmt:
+			add(goroutine)
+		case *ast.DeferStmt:
+			add(deferred)
+		case *ast.SelectStmt:
+			add(selects)
+		case *ast.FuncLit:
+			add(closure)
+		case *ast.RangeStmt:
+			add(ranges)
+		case *ast.MapType:
+			add(maps)
+		case *ast.TypeAssertExpr:
+			add(assertion)
+		}
+		return true
+	})
+	return out
+}
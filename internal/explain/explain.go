@@ -0,0 +1,314 @@
+// Package explain 为源码中的一行找到讲解它的注释：同一行和紧挨着的上一行的注释、
+// 所在示例上方的说明注释（没有时用上方最近的一段）、相关的编号规则（见 flashcard），
+// 以及课程中其他讲到同一语法的示例。
+package explain
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/flashcard"
+	"study/internal/lesson"
+)
+
+// Explanation 一行源码的讲解。
+type Explanation struct {
+	File     string // 相对仓库根目录
+	Line     int
+	Code     string // 这一行的源码，去掉了缩进
+	Package  *course.Package
+	Lesson   *course.Lesson // 这一行所在的示例，不在示例中时为 nil
+	Func     string         // 这一行所在的函数，不在函数中时为空
+	Inline   []*Comment     // 写在这一行行尾和紧挨着的上一行的注释
+	Notes    []*course.Note // 讲解这一行的说明注释
+	Rules    []*Rule
+	Concepts []*Concept // 这一行用到的语法
+	Related  []*Related
+}
+
+// Comment 代码中的一条注释。
+type Comment struct {
+	Line int
+	Text string
+}
+
+// Rule 一条和这一行有关的规则（见 flashcard.Card）：提到这一行所用语法的规则，
+// 讲解注释中的列表没有这样的规则时是这个列表的全部规则。
+type Rule struct {
+	Card    *flashcard.Card
+	Matched bool // 因为提到这一行所用的语法而选中；为 false 时规则就在讲解这一行的注释中
+}
+
+// Related 课程中另一个讲到同一语法的示例。
+type Related struct {
+	Lesson   *course.Lesson
+	Concepts []string // 共同的语法
+}
+
+// maxRules 最多列出几条不在讲解注释中的规则。
+const maxRules = 4
+
+// maxRelated 最多列出几个相关的示例。
+const maxRelated = 5
+
+// Explain 返回课程中文件 file（相对仓库根目录）第 line 行的讲解。
+func Explain(c *course.Course, file string, line int) (*Explanation, error) {
+	file = path.Clean(filepath.ToSlash(file))
+	p := c.Package(path.Dir(file))
+	if p == nil || !contains(p.Files, file) {
+		return nil, fmt.Errorf("explain: %s is not a source file of the course", file)
+	}
+	data, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return nil, fmt.Errorf("explain: %s has no line %d", file, line)
+	}
+	e := &Explanation{File: file, Line: line, Package: p, Code: strings.TrimSpace(lines[line-1])}
+
+	var fn *ast.FuncDecl
+	for _, d := range f.Decls {
+		if d, ok := d.(*ast.FuncDecl); ok && lineOf(fset, d.Pos()) <= line && line <= lineOf(fset, d.End()) {
+			fn = d
+		}
+	}
+	if fn != nil {
+		e.Func = fn.Name.Name
+		for _, l := range p.Lessons {
+			if l.File == file && l.Line <= line && line <= l.EndLine {
+				e.Lesson = l
+			}
+		}
+		e.Inline = inline(fset, f, fn, lines, line)
+		e.Concepts = concepts(fset, fn, line)
+	}
+
+	notes, err := course.Notes(c.Root, p)
+	if err != nil {
+		return nil, err
+	}
+	ends := map[int]int{} // 注释开始的行 -> 结束的行
+	for _, cg := range f.Comments {
+		ends[lineOf(fset, cg.Pos())] = lineOf(fset, cg.End())
+	}
+	e.Notes = governing(notes, e.Lesson, file, line)
+
+	cards, err := flashcard.All(c)
+	if err != nil {
+		return nil, err
+	}
+	e.Rules = rules(cards, e.Notes, ends, p, e.Concepts)
+	e.Related = related(c.Lessons(), e.Lesson, e.Concepts)
+	return e, nil
+}
+
+// governing 返回讲解第 line 行的说明注释：所在示例上方的注释；
+// 不在示例中或者示例上方没有注释时，是上方最近的一段（包括 line 所在的这一段）。
+func governing(notes []*course.Note, l *course.Lesson, file string, line int) []*course.Note {
+	var out []*course.Note
+	if l != nil {
+		for _, n := range notes {
+			if n.File == file && n.Lesson == l.Name {
+				out = append(out, n)
+			}
+		}
+		if len(out) > 0 {
+			return out
+		}
+	}
+	var nearest *course.Note
+	for _, n := range notes {
+		if n.File == file && n.Line <= line {
+			nearest = n
+		}
+	}
+	if nearest != nil {
+		out = append(out, nearest)
+	}
+	return out
+}
+
+// inline 返回第 line 行上的注释，以及紧挨在它上面、单独占行的注释。
+func inline(fset *token.FileSet, f *ast.File, fn *ast.FuncDecl, lines []string, line int) []*Comment {
+	var on []*Comment
+	above := map[int]*ast.Comment{} // 单独占行的注释，按结束的行
+	for _, cg := range f.Comments {
+		if cg.Pos() < fn.Pos() || cg.End() > fn.End() {
+			continue
+		}
+		for _, c := range cg.List {
+			start, end := lineOf(fset, c.Pos()), lineOf(fset, c.End())
+			switch {
+			case start <= line && line <= end:
+				on = append(on, comment(start, c))
+			case strings.TrimSpace(lines[start-1][:fset.Position(c.Pos()).Column-1]) == "":
+				above[end] = c
+			}
+		}
+	}
+	var out []*Comment
+	for l := line - 1; above[l] != nil; {
+		c := above[l]
+		l = lineOf(fset, c.Pos())
+		out = append([]*Comment{comment(l, c)}, out...)
+		l--
+	}
+	return append(out, on...)
+}
+
+func comment(line int, c *ast.Comment) *Comment {
+	return &Comment{Line: line, Text: strings.TrimSpace(course.CommentText(&ast.CommentGroup{List: []*ast.Comment{c}}))}
+}
+
+// rules 返回讲解注释中的规则，以及其他提到这一行所用语法的规则。
+// 同一文件的排在前面，其次是同一个包，最后是课程的其他地方。
+func rules(cards []*flashcard.Card, notes []*course.Note, ends map[int]int, p *course.Package, cs []*Concept) []*Rule {
+	var out, matched []*Rule
+	lists := map[string][]*flashcard.Card{} // 讲解注释中的列表：文件和标题 -> 规则
+	var keys []string
+	for _, card := range cards {
+		inNote := false
+		for _, n := range notes {
+			if card.File == n.File && n.Line <= card.Line && card.Line <= ends[n.Line] {
+				inNote = true
+			}
+		}
+		if inNote {
+			key := card.File + "\x00" + card.Topic
+			if lists[key] == nil {
+				keys = append(keys, key)
+			}
+			lists[key] = append(lists[key], card)
+			continue
+		}
+		if mentions(card, cs) {
+			matched = append(matched, &Rule{Card: card, Matched: true})
+		}
+	}
+	for _, key := range keys {
+		var some []*Rule
+		for _, card := range lists[key] {
+			if mentions(card, cs) {
+				some = append(some, &Rule{Card: card})
+			}
+		}
+		if len(some) == 0 {
+			for _, card := range lists[key] {
+				some = append(some, &Rule{Card: card})
+			}
+		}
+		out = append(out, some...)
+	}
+	rank := func(r *Rule) int {
+		switch {
+		case len(notes) > 0 && r.Card.File == notes[0].File:
+			return 0
+		case r.Card.Package == p.Dir:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return rank(matched[i]) < rank(matched[j]) })
+	if len(matched) > maxRules {
+		matched = matched[:maxRules]
+	}
+	return append(out, matched...)
+}
+
+// mentions 判断 card 是否提到了 cs 中的某种语法。
+func mentions(card *flashcard.Card, cs []*Concept) bool {
+	for _, c := range cs {
+		if c.mentionedIn(card.Answer) {
+			return true
+		}
+	}
+	return false
+}
+
+// notTopic 只说明输出是否稳定、不是主题的标签。
+var notTopic = map[string]bool{
+	lesson.TagUnordered: true, lesson.TagAddress: true, lesson.TagRacy: true,
+	lesson.TagSlow: true, lesson.TagPuzzle: true,
+}
+
+// related 返回其他用到同一语法的示例：登记的标签和语法同名得 2 分，标题或说明注释提到它得 1 分。
+func related(all []*course.Lesson, l *course.Lesson, cs []*Concept) []*Related {
+	type scored struct {
+		r     *Related
+		score int
+	}
+	var found []scored
+	for _, o := range all {
+		if o == l {
+			continue
+		}
+		r, score := &Related{Lesson: o}, 0
+		for _, c := range cs {
+			s := 0
+			if o.Meta != nil && o.Meta.HasTag(c.Name) {
+				s += 2
+			}
+			if c.mentionedIn(o.Title) || c.mentionedIn(o.Comment) {
+				s++
+			}
+			if s > 0 {
+				score += s
+				r.Concepts = append(r.Concepts, c.Name)
+			}
+		}
+		if score > 0 {
+			found = append(found, scored{r, score})
+		}
+	}
+	if len(found) == 0 && l != nil && l.Meta != nil {
+		// 这一行没有别处也讲到的语法时，退而找和所在示例主题相同的示例
+		for _, o := range all {
+			if o == l || o.Meta == nil {
+				continue
+			}
+			r := &Related{Lesson: o}
+			for _, t := range l.Meta.Tags {
+				if !notTopic[t] && o.Meta.HasTag(t) {
+					r.Concepts = append(r.Concepts, t)
+				}
+			}
+			if len(r.Concepts) > 0 {
+				found = append(found, scored{r, len(r.Concepts)})
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	var out []*Related
+	for i := 0; i < len(found) && i < maxRelated; i++ {
+		out = append(out, found[i].r)
+	}
+	return out
+}
+
+func lineOf(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Line
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package explain
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+// explainCode 讲解 file 中第一行以 code 开头的代码。
+func explainCode(t *testing.T, c *course.Course, file, code string) *Explanation {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(c.Root, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), code) {
+			e, err := Explain(c, file, i+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return e
+		}
+	}
+	t.Fatalf("%s has no line %q", file, code)
+	return nil
+}
+
+func names(cs []*Concept) []string {
+	var out []string
+	for _, c := range cs {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+// numbers 返回规则的标题和编号。
+func numbers(rs []*Rule) []string {
+	var out []string
+	for _, r := range rs {
+		out = append(out, r.Card.Topic+" "+strconv.Itoa(r.Card.Number))
+	}
+	return out
+}
+
+func TestLabel(t *testing.T) {
+	c := coursetest.Load(t)
+	e := explainCode(t, c, "c3/2.control/control_test.go", "continue LABEL1")
+	if e.Lesson == nil || e.Lesson.Name != "TestC5" {
+		t.Fatalf("lesson = %v", e.Lesson)
+	}
+	if got := names(e.Concepts); !reflect.DeepEqual(got, []string{"continue", "label"}) {
+		t.Errorf("concepts = %q", got)
+	}
+	if len(e.Inline) != 1 || e.Inline[0].Text != "= break" {
+		t.Errorf("inline comments = %v", e.Inline)
+	}
+	if len(e.Notes) != 1 || !strings.HasPrefix(e.Notes[0].Text, "循环控制语句") {
+		t.Fatalf("notes = %v", e.Notes)
+	}
+	// 块注释中的列表是规则本身，只列出提到 continue 或 label 的几条
+	if got := numbers(e.Rules); !reflect.DeepEqual(got, []string{"Goto、Break、Continue 1", "Goto、Break、Continue 3", "Goto、Break、Continue 4"}) ||
+		e.Rules[0].Matched {
+		t.Errorf("rules = %q", got)
+	}
+	// 别处没有讲标签的示例，找同一主题的
+	if len(e.Related) == 0 || e.Related[0].Lesson.Name != "TestC1" || e.Related[0].Concepts[0] != "control" {
+		t.Errorf("related = %+v", e.Related)
+	}
+}
+
+func TestSlice3(t *testing.T) {
+	c := coursetest.Load(t)
+	e := explainCode(t, c, "c3/5.slice/slice_test.go", "s := data[2:4:11]")
+	if e.Lesson == nil || e.Lesson.Name != "Test_S9" {
+		t.Fatalf("lesson = %v", e.Lesson)
+	}
+	if got := names(e.Concepts); !reflect.DeepEqual(got, []string{"cap"}) {
+		t.Errorf("concepts = %q", got)
+	}
+	if len(e.Inline) != 1 || !strings.HasPrefix(e.Inline[0].Text, "len = 2(2-0), cap = 3(3-0)") {
+		t.Errorf("inline comments = %v", e.Inline)
+	}
+	if len(e.Notes) != 1 || !strings.HasPrefix(e.Notes[0].Text, "超出原 slice.cap 限制") {
+		t.Errorf("notes = %v", e.Notes)
+	}
+	// 文件开头的列表没有标题，用后面第一个示例的标题；其中讲 cap 的两条
+	if got := numbers(e.Rules); !reflect.DeepEqual(got, []string{"声明切片 4", "声明切片 6"}) || !e.Rules[0].Matched {
+		t.Errorf("rules = %q", got)
+	}
+	if len(e.Related) == 0 || e.Related[0].Lesson.Name != "Test_S8" {
+		t.Errorf("related = %+v", e.Related)
+	}
+}
+
+func TestSeeAlso(t *testing.T) {
+	c := coursetest.Load(t)
+	e := explainCode(t, c, "c6/2.channel/chan_test.go", "close(ch)")
+	// 讲解注释中没有列表，同一个文件中关闭通道的规则
+	want := []string{"关闭通道需要注意的事情是 1", "关闭通道需要注意的事情是 2", "关闭通道需要注意的事情是 3", "关闭通道需要注意的事情是 4"}
+	if got := numbers(e.Rules); !reflect.DeepEqual(got, want) || !e.Rules[0].Matched {
+		t.Errorf("rules = %q", got)
+	}
+}
+
+func TestCommentAbove(t *testing.T) {
+	c := coursetest.Load(t)
+	e := explainCode(t, c, "c3/5.slice/slice_test.go", "s2 := append(s1, 1)")
+	if len(e.Inline) != 1 || !strings.HasPrefix(e.Inline[0].Text, "在不超过 s1 cap 的情况下") {
+		t.Errorf("inline comments = %v", e.Inline)
+	}
+	if got := names(e.Concepts); !reflect.DeepEqual(got, []string{"append"}) {
+		t.Errorf("concepts = %q", got)
+	}
+	if _, err := Explain(c, "c3/5.slice/slice_test.go", 100000); err == nil {
+		t.Error("no error for a line past the end")
+	}
+	if _, err := Explain(c, "README.md", 1); err == nil {
+		t.Error("no error for a file outside the lesson packages")
+	}
+}
@@ -0,0 +1,49 @@
+package flashcard
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Deck 导入 Anki 时使用的牌组名。
+const Deck = "study"
+
+// Write 把卡片写成 Anki 可以导入的文本文件，format 为 tsv 或 csv。
+// 文件开头的 # 行告诉 Anki 分隔符、哪一列是 GUID、哪一列是标签（Anki 2.1.55 起支持），
+// 修改注释后再次导入时按 GUID 更新已有的卡片，不会重复。
+func Write(w io.Writer, cards []*Card, format string) error {
+	cw := csv.NewWriter(w)
+	switch format {
+	case "tsv":
+		cw.Comma = '\t'
+		fmt.Fprintln(w, "#separator:tab")
+	case "csv":
+		fmt.Fprintln(w, "#separator:comma")
+	default:
+		return fmt.Errorf("flashcard: unknown format %q, want tsv or csv", format)
+	}
+	fmt.Fprintf(w, "#html:true\n#notetype:Basic\n#deck:%s\n#guid column:1\n#tags column:4\n", Deck)
+	for _, c := range cards {
+		chapter := c.Package
+		if i := strings.Index(chapter, "/"); i >= 0 {
+			chapter = chapter[:i]
+		}
+		tags := Deck + " " + chapter + " " + c.Package
+		if err := cw.Write([]string{c.ID, html.EscapeString(c.Question), answerHTML(c), tags}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// answerHTML 返回答案的 HTML：规则，以及出处。
+func answerHTML(c *Card) string {
+	var b strings.Builder
+	b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(c.Answer), "\n", "<br>") + "</p>")
+	fmt.Fprintf(&b, "<p><small>%s:%d</small></p>", html.EscapeString(c.File), c.Line)
+	return strings.ReplaceAll(b.String(), "\t", " ")
+}
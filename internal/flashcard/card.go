@@ -0,0 +1,236 @@
+// Package flashcard 把说明注释中的编号列表（数组的特性、defer特性等）做成问答卡片，每条规则一张，
+// 可以导出给 Anki，也可以按 SM-2 算法安排复习。
+package flashcard
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"study/internal/course"
+	"study/internal/snippet"
+)
+
+// Card 一张卡片：问题是列表的标题和规则的编号，答案是这一条规则。
+type Card struct {
+	ID       string // 包目录/标题/规则内容的摘要，例如 c3/4.arr/数组的特性/962ccf8f，复习记录用它对应卡片
+	Package  string // c3/4.arr
+	Topic    string // 列表的标题，例如 数组的特性
+	Number   int    // 规则在列表中的编号，从 1 开始
+	Count    int    // 列表有几条规则
+	Question string // 数组的特性（第 3 条，共 9 条）
+	Answer   string // 规则本身，不带编号；有多行时用换行分隔
+	File     string // 相对仓库根目录
+	Line     int    // 规则所在的行
+}
+
+// minItems 编号列表至少要有几项才做成卡片。
+const minItems = 2
+
+var item = regexp.MustCompile(`^(\d+)\s*[.、．]\s*(\S.*)$`)
+
+// list 注释中的一个编号列表。
+type list struct {
+	title string
+	none  bool // 列表跟在以 notTopic 中的词结尾的行后面，不是规则
+	items []string
+	first []string // 各项第一行的原文，用来找行号
+}
+
+// lists 返回 text 中从 1 开始连续编号的列表。列表的标题是它前面最近的一行主题（见 isTopic），
+// 中间的伪代码不算；前面没有主题时用引出列表的那段说明的开头（见 leadIn）。
+// 以 notTopic 中的词结尾的行之后的列表没有标题，不是规则。
+// 不带编号的行接在上一项后面，空行或者以冒号结尾的行结束列表。
+func lists(text string) []*list {
+	var (
+		out     []*list
+		cur     *list
+		heading string
+		lead    string // 前面没有主题时，最近一段说明的开头
+		none    bool
+		depth   int // 伪代码中 { } 的层数
+	)
+	end := func() {
+		if cur != nil && len(cur.items) >= minItems {
+			out = append(out, cur)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		s := strings.TrimSpace(line)
+		m := item.FindStringSubmatch(s)
+		switch {
+		case m != nil && m[1] == "1":
+			end()
+			cur = &list{title: heading, none: none, items: []string{m[2]}, first: []string{line}}
+			if heading == "" && !none {
+				cur.title = lead
+			}
+		case m != nil && cur != nil && m[1] == strconv.Itoa(len(cur.items)+1):
+			cur.items = append(cur.items, m[2])
+			cur.first = append(cur.first, line)
+		case s == "":
+			end()
+		case cur != nil && !strings.HasSuffix(s, "：") && !strings.HasSuffix(s, ":"):
+			cur.items[len(cur.items)-1] += "\n" + s
+		default:
+			end()
+			code := depth > 0 || isCode(s)
+			depth += strings.Count(s, "{") - strings.Count(s, "}")
+			switch {
+			case code:
+			case isTopic(s):
+				heading, lead, none = cleanTitle(s), "", false
+			case introduces(s):
+				heading, lead, none = "", "", true
+			case lead == "":
+				lead = leadIn(s)
+			}
+		}
+	}
+	end()
+	return out
+}
+
+// maxHeading 标题最多几个字。
+const maxHeading = 24
+
+// maxLead 用说明的开头做标题时最多几个字。
+const maxLead = 32
+
+// notTopic 以这些词结尾的标题引出的是代码或者一串小节名，不是规则。
+var notTopic = []string{"如下", "部分"}
+
+// isTopic 判断一行文字是不是规则的主题，例如 数组的特性：不长，不是句子，
+// 也不以 notTopic 中的词结尾。
+func isTopic(s string) bool {
+	s = cleanTitle(s)
+	if utf8.RuneCountInString(s) > maxHeading || strings.ContainsAny(s, "。.，,；;") {
+		return false
+	}
+	return !introduces(s)
+}
+
+// introduces 判断一行文字是不是以 notTopic 中的词结尾。
+func introduces(s string) bool {
+	s = cleanTitle(s)
+	for _, w := range notTopic {
+		if strings.HasSuffix(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadIn 返回一行说明中第一个标点之前的部分，例如 关闭通道需要注意的事情是，……
+// 中的 关闭通道需要注意的事情是，太长时返回空。
+func leadIn(s string) string {
+	if i := strings.IndexAny(s, "。.，,；;：:"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) > maxLead {
+		return ""
+	}
+	return s
+}
+
+// isCode 判断一行是不是代码，例如伪代码中的 type 类型名 struct {。
+func isCode(s string) bool {
+	return strings.ContainsAny(s, "{}") || strings.HasPrefix(s, "func ") || strings.HasPrefix(s, "type ")
+}
+
+// cleanTitle 去掉结尾的冒号和伪代码标记。
+func cleanTitle(s string) string {
+	s = strings.TrimRight(s, "：:")
+	return strings.TrimSpace(strings.TrimSuffix(s, "（"+snippet.PseudoMarker+"）"))
+}
+
+// Extract 返回课程包 p 的卡片，每条规则一张，按文件和行排列。列表前面既没有主题也没有说明时，
+// 用注释后面第一个示例的标题做标题。
+func Extract(root string, p *course.Package) ([]*Card, error) {
+	notes, err := course.Notes(root, p)
+	if err != nil {
+		return nil, err
+	}
+	titles := map[string]string{}
+	for _, l := range p.Lessons {
+		// 没有登记标题的示例用上面的注释做标题，可能就是这个列表
+		if isTopic(l.Title) {
+			titles[l.Name] = l.Title
+		}
+	}
+	var cards []*Card
+	ids := map[string]int{}
+	sources := map[string][]string{}
+	for _, n := range notes {
+		if sources[n.File] == nil {
+			data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(n.File)))
+			if err != nil {
+				return nil, err
+			}
+			sources[n.File] = strings.Split(string(data), "\n")
+		}
+		for _, l := range lists(n.Text) {
+			if l.title == "" && !l.none {
+				l.title = titles[n.Lesson]
+			}
+			if l.title == "" {
+				continue
+			}
+			line := n.Line - 1
+			for i, a := range l.items {
+				c := &Card{
+					ID:       p.Dir + "/" + l.title + "/" + digest(a),
+					Package:  p.Dir,
+					Topic:    l.title,
+					Number:   i + 1,
+					Count:    len(l.items),
+					Question: fmt.Sprintf("%s（第 %d 条，共 %d 条）", l.title, i+1, len(l.items)),
+					Answer:   a,
+					File:     n.File,
+					Line:     n.Line,
+				}
+				if ids[c.ID]++; ids[c.ID] > 1 {
+					c.ID += "#" + strconv.Itoa(ids[c.ID])
+				}
+				for ; line < len(sources[n.File]); line++ {
+					if strings.TrimSpace(sources[n.File][line]) == strings.TrimSpace(l.first[i]) {
+						c.Line = line + 1
+						break
+					}
+				}
+				cards = append(cards, c)
+			}
+		}
+	}
+	return cards, nil
+}
+
+// digest 返回规则内容的摘要。卡片的 ID 用它而不用编号，
+// 在列表中插入、删除或调整其他规则时复习记录仍然对应原来的卡片。
+func digest(rule string) string {
+	sum := sha1.Sum([]byte(strings.Join(strings.Fields(rule), " ")))
+	return hex.EncodeToString(sum[:4])
+}
+
+// All 返回整个课程的卡片。
+func All(c *course.Course) ([]*Card, error) {
+	var cards []*Card
+	for _, l := range c.Chapters {
+		for _, p := range l.Packages {
+			pc, err := Extract(c.Root, p)
+			if err != nil {
+				return nil, err
+			}
+			cards = append(cards, pc...)
+		}
+	}
+	return cards, nil
+}
@@ -0,0 +1,192 @@
+package flashcard
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+func TestLists(t *testing.T) {
+	text := `结构体的定义（伪代码）：
+	type 类型名 struct {
+        字段名 字段类型
+    }
+    1.类型名：标识自定义结构体的名称。
+    2.字段名：表示结构体字段名，
+必须唯一。
+
+defer用途：
+    1. 关闭文件句柄
+    2. 锁资源释放
+只有一项的列表：
+    1. 不做成卡片
+
+new是一个内置的函数，它的函数签名如下：
+	func new(Type) *Type
+		1.Type表示类型
+		2.*Type表示类型指针
+
+map 原理部分
+	1. 初始化
+	2. 扩容 和 迁移`
+	var got []string
+	for _, l := range lists(text) {
+		got = append(got, l.title+" | "+strings.Join(l.items, " | "))
+	}
+	want := []string{
+		"结构体的定义 | 类型名：标识自定义结构体的名称。 | 字段名：表示结构体字段名，\n必须唯一。",
+		"defer用途 | 关闭文件句柄 | 锁资源释放",
+		" | Type表示类型 | *Type表示类型指针",
+		" | 初始化 | 扩容 和 迁移",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lists() = %q\nwant %q", got, want)
+	}
+}
+
+func TestCourse(t *testing.T) {
+	c := coursetest.Load(t)
+	cards, err := All(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics := map[string][]*Card{}
+	for _, card := range cards {
+		topics[card.Package+"/"+card.Topic] = append(topics[card.Package+"/"+card.Topic], card)
+	}
+	for topic, n := range map[string]int{
+		"c3/4.arr/数组的特性":              9,
+		"c4/1.function/defer特性":       4,
+		"c5/2.method/什么时候应该使用指针类型接收者": 3,
+		"c6/2.channel/单向通道":           2,
+		// 没有标题的列表用引出它的说明或者后面示例的标题
+		"c3/5.slice/声明切片":           6,
+		"c6/2.channel/关闭通道需要注意的事情是": 4,
+	} {
+		if len(topics[topic]) != n {
+			t.Errorf("%s has %d cards, want %d", topic, len(topics[topic]), n)
+		}
+	}
+	// 引出代码或者小节名的列表不做成卡片
+	for _, topic := range []string{"c3/3.pointer/", "c3/3.pointer/new是一个内置的函数，它的函数签名如下", "c4/2.map/map 原理部分", "c4/2.map/"} {
+		if len(topics[topic]) > 0 {
+			t.Errorf("cards for %q: %q", topic, topics[topic][0].Question)
+		}
+	}
+	if arr := topics["c3/4.arr/数组的特性"]; len(arr) > 2 {
+		if arr[0].Line != 9 || arr[1].Line != 10 {
+			t.Errorf("数组的特性 rules are at lines %d and %d, want 9 and 10", arr[0].Line, arr[1].Line)
+		}
+		if arr[1].Number != 2 || arr[1].Count != 9 || arr[1].Question != "数组的特性（第 2 条，共 9 条）" {
+			t.Errorf("second rule = %+v", arr[1])
+		}
+	}
+}
+
+// 卡片的 ID 不随规则的编号变化，在前面插入一条规则后原来的卡片 ID 不变。
+func TestStableID(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rules string) []*Card {
+		src := "package p\n\n/*\n数组的特性：\n" + rules + "*/\n\nfunc TestA(t *testing.T) {}\n"
+		if err := os.WriteFile(filepath.Join(dir, "p_test.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cards, err := Extract(dir, &course.Package{Dir: "p", Files: []string{"p_test.go"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cards
+	}
+	before := write("\t1. 数组是值类型\n\t2. 长度是类型的一部分\n")
+	after := write("\t1. 数组是同一种类型的序列\n\t2. 数组是值类型\n\t3. 长度是类型的一部分\n")
+	if len(before) != 2 || len(after) != 3 {
+		t.Fatalf("got %d and %d cards", len(before), len(after))
+	}
+	if before[0].ID != after[1].ID || before[1].ID != after[2].ID || after[0].ID == after[1].ID {
+		t.Errorf("IDs before %q, after %q", []string{before[0].ID, before[1].ID}, []string{after[0].ID, after[1].ID, after[2].ID})
+	}
+	if !strings.HasPrefix(before[0].ID, "p/数组的特性/") {
+		t.Errorf("ID = %q", before[0].ID)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	cards := []*Card{{ID: "c3/4.arr/数组的特性/0123abcd", Package: "c3/4.arr", Question: "数组的特性（第 7 条，共 9 条）",
+		Answer: "支持 \"==\" 和 <\n比较", File: "c3/4.arr/arr_test.go", Line: 15}}
+	for _, format := range []string{"tsv", "csv"} {
+		var b bytes.Buffer
+		if err := Write(&b, cards, format); err != nil {
+			t.Fatal(err)
+		}
+		var body []string
+		for _, line := range strings.SplitAfter(b.String(), "\n") {
+			if !strings.HasPrefix(line, "#") {
+				body = append(body, line)
+			}
+		}
+		r := csv.NewReader(strings.NewReader(strings.Join(body, "")))
+		if format == "tsv" {
+			r.Comma = '\t'
+		}
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		want := []string{"c3/4.arr/数组的特性/0123abcd", "数组的特性（第 7 条，共 9 条）",
+			"<p>支持 &#34;==&#34; 和 &lt;<br>比较</p><p><small>c3/4.arr/arr_test.go:15</small></p>",
+			"study c3 c3/4.arr"}
+		if len(records) != 1 || !reflect.DeepEqual(records[0], want) {
+			t.Errorf("%s: %q\nwant %q", format, records, want)
+		}
+	}
+	if err := Write(&bytes.Buffer{}, cards, "apkg"); err == nil {
+		t.Error("Write with an unknown format succeeded")
+	}
+}
+
+func TestStatePath(t *testing.T) {
+	if path, err := StatePath("progress", "alice"); err != nil || path != filepath.Join("progress", "alice"+StateSuffix) {
+		t.Errorf("StatePath(alice) = %q, %v", path, err)
+	}
+	if path, err := StatePath("progress", "../alice"); err == nil {
+		t.Errorf("StatePath(../alice) = %q, want an error", path)
+	}
+}
+
+func TestReview(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := &State{Cards: map[string]*Review{}}
+	var intervals []int
+	for _, q := range []int{5, 4, 4, 1, 3} {
+		r := s.Answer("a", q, now)
+		intervals = append(intervals, r.Interval)
+		now = r.Due
+	}
+	// 记住时间隔为 1、6、6×Ease 天，忘了以后从 1 天重新开始
+	if want := []int{1, 6, 16, 1, 1}; !reflect.DeepEqual(intervals, want) {
+		t.Errorf("intervals = %v, want %v", intervals, want)
+	}
+	if e := s.Cards["a"].Ease; e < minEase || e >= initialEase {
+		t.Errorf("Ease = %v", e)
+	}
+
+	cards := []*Card{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	s.Cards["d"] = &Review{Due: now.Add(-time.Hour)}
+	s.Cards["c"] = &Review{Due: now.Add(time.Hour)}
+	var ids []string
+	for _, c := range s.Due(cards, now, 5) {
+		ids = append(ids, c.ID)
+	}
+	if want := []string{"d", "a", "b"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Due() = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,153 @@
+package flashcard
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"study/internal/progress"
+)
+
+// Review 一张卡片的复习记录，按 SM-2 算法安排下次复习的时间。
+type Review struct {
+	Repetitions int       `json:"repetitions"` // 连续记住的次数
+	Interval    int       `json:"interval"`    // 距下次复习的天数
+	Ease        float64   `json:"ease"`        // 间隔的增长系数，初始 2.5，最小 1.3
+	Due         time.Time `json:"due"`
+	Last        time.Time `json:"last"`
+}
+
+// 答题质量，SM-2 中 0～5 分，3 分以上算记住了。
+const (
+	MinQuality  = 0
+	PassQuality = 3
+	MaxQuality  = 5
+)
+
+const (
+	initialEase = 2.5
+	minEase     = 1.3
+)
+
+// answer 按 SM-2 更新复习记录：没记住时从头开始，间隔为 1 天；
+// 记住时间隔依次为 1 天、6 天、上次的间隔乘以 Ease。Ease 随答题质量调整。
+func (r *Review) answer(quality int, now time.Time) {
+	if quality < PassQuality {
+		r.Repetitions = 0
+		r.Interval = 1
+	} else {
+		switch r.Repetitions {
+		case 0:
+			r.Interval = 1
+		case 1:
+			r.Interval = 6
+		default:
+			r.Interval = int(math.Round(float64(r.Interval) * r.Ease))
+		}
+		r.Repetitions++
+	}
+	q := float64(MaxQuality - quality)
+	r.Ease += 0.1 - q*(0.08+q*0.02)
+	if r.Ease < minEase {
+		r.Ease = minEase
+	}
+	r.Last = now
+	r.Due = now.AddDate(0, 0, r.Interval)
+}
+
+// State 一个学员所有卡片的复习记录。
+type State struct {
+	Learner string             `json:"learner"`
+	Cards   map[string]*Review `json:"cards"` // 键是 Card.ID
+}
+
+// StateSuffix 复习记录文件名的后缀，文件和进度文件放在同一个目录。
+const StateSuffix = ".cards.json"
+
+// StatePath 返回 learner 的复习记录文件路径，dir 是进度文件所在的目录。
+// 名字的要求和进度文件相同，见 progress.CheckLearner。
+func StatePath(dir, learner string) (string, error) {
+	if err := progress.CheckLearner(learner); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, learner+StateSuffix), nil
+}
+
+// LoadState 读取复习记录，文件不存在时返回空的记录。
+func LoadState(path string) (*State, error) {
+	s := &State{Cards: map[string]*Review{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Cards == nil {
+		s.Cards = map[string]*Review{}
+	}
+	return s, nil
+}
+
+// Save 先写临时文件再改名，中途出错不会留下写了一半的文件。
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Answer 记录一次复习，quality 为 0～5，返回更新后的记录。
+func (s *State) Answer(id string, quality int, now time.Time) *Review {
+	r := s.Cards[id]
+	if r == nil {
+		r = &Review{Ease: initialEase}
+		s.Cards[id] = r
+	}
+	r.answer(quality, now)
+	return r
+}
+
+// Due 返回 now 时要复习的卡片：先是到期的，按到期时间排列；再是至多 newLimit 张没复习过的，按课程顺序。
+func (s *State) Due(cards []*Card, now time.Time, newLimit int) []*Card {
+	var due, fresh []*Card
+	for _, c := range cards {
+		r := s.Cards[c.ID]
+		switch {
+		case r == nil:
+			if len(fresh) < newLimit {
+				fresh = append(fresh, c)
+			}
+		case !r.Due.After(now):
+			due = append(due, c)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return s.Cards[due[i].ID].Due.Before(s.Cards[due[j].ID].Due) })
+	return append(due, fresh...)
+}
+
+// Next 返回最早的下次复习时间，没有复习记录时返回零值。
+func (s *State) Next() time.Time {
+	var next time.Time
+	for _, r := range s.Cards {
+		if next.IsZero() || r.Due.Before(next) {
+			next = r.Due
+		}
+	}
+	return next
+}
@@ -0,0 +1,112 @@
+// Package golden 把示例的输出与 testdata 中的 .golden 文件比较。
+//
+// golden 文件同时记录标准输出和标准错误（println 写到 stderr）：
+//
+//	-- stdout --
+//	...
+//	-- stderr --
+//	...
+//
+// 比较之前先用 Normalize 处理输出：%p 等打印出来的地址替换为 0xADDR1、0xADDR2……，
+// 同一个地址替换为同一个名字，地址之间是否相同仍然看得出来；
+// 输出依赖 map 遍历顺序的示例，按行排序后再比较。
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"study/internal/lesson"
+	"study/internal/textdiff"
+)
+
+// Options 控制 Normalize 的行为。
+type Options struct {
+	Unordered bool // 输出依赖 map 遍历顺序
+}
+
+// OptionsFor 根据示例的标签决定如何归一化它的输出。
+func OptionsFor(l lesson.Lesson) Options {
+	return Options{Unordered: l.HasTag(lesson.TagUnordered)}
+}
+
+// Stable 判断示例的输出能否做 golden 比较：必须正常结束，且不依赖 goroutine 调度。
+func Stable(l lesson.Lesson) bool {
+	return l.Expect == lesson.Prints && !l.HasTag(lesson.TagRacy)
+}
+
+var (
+	address  = regexp.MustCompile(`0x[0-9a-f]{6,}`)
+	list     = regexp.MustCompile(`\[[^\[\]]*\]`)
+	trailing = regexp.MustCompile(`(?m)[ \t]+$`)
+)
+
+// Normalize 替换输出中的地址，Unordered 时再把行和 [...] 中的元素排序。
+func Normalize(out []byte, opt Options) []byte {
+	names := map[string]string{}
+	out = address.ReplaceAllFunc(out, func(addr []byte) []byte {
+		name, ok := names[string(addr)]
+		if !ok {
+			name = fmt.Sprintf("0xADDR%d", len(names)+1)
+			names[string(addr)] = name
+		}
+		return []byte(name)
+	})
+	if !opt.Unordered || len(out) == 0 {
+		return out
+	}
+
+	out = list.ReplaceAllFunc(out, func(l []byte) []byte {
+		fields := strings.Fields(string(l[1 : len(l)-1]))
+		sort.Strings(fields)
+		return []byte("[" + strings.Join(fields, " ") + "]")
+	})
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// Format 把两路输出组合成 golden 文件的内容。
+func Format(stdout, stderr []byte) []byte {
+	var b bytes.Buffer
+	section := func(name string, data []byte) {
+		fmt.Fprintf(&b, "-- %s --\n", name)
+		b.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			b.WriteByte('\n')
+		}
+	}
+	section("stdout", stdout)
+	section("stderr", stderr)
+	return b.Bytes()
+}
+
+// Path 返回示例的 golden 文件路径：<包目录>/testdata/<函数名>.golden。
+func Path(root string, l lesson.Lesson) string {
+	return filepath.Join(root, filepath.FromSlash(l.Package), "testdata", l.Name+".golden")
+}
+
+// Check 归一化 stdout、stderr 后与 golden 文件比较，返回差异（- golden，+ 实际输出）。
+// 行尾的空白看不出来，比较时忽略。update 为 true 时用实际输出覆盖 golden 文件。
+func Check(root string, l lesson.Lesson, stdout, stderr []byte, update bool) (string, error) {
+	opt := OptionsFor(l)
+	got := Format(Normalize(stdout, opt), Normalize(stderr, opt))
+
+	path := Path(root, l)
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", err
+		}
+		return "", os.WriteFile(path, got, 0o644)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return textdiff.Diff(trailing.ReplaceAllString(string(want), ""), trailing.ReplaceAllString(string(got), "")), nil
+}
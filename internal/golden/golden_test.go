@@ -0,0 +1,95 @@
+package golden
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"study/internal/course/coursetest"
+	"study/internal/lesson"
+	_ "study/internal/lesson/all"
+	"study/internal/runner"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files with the current output")
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		opt  Options
+		want string
+	}{
+		{"0xc000012345 0xc000012345\n", Options{}, "0xADDR1 0xADDR1\n"},
+		{"a: 0xc00001a0b0\nb: 0xc00001a0c8\n", Options{}, "a: 0xADDR1\nb: 0xADDR2\n"},
+		{"b 2\na 1\n", Options{Unordered: true}, "a 1\nb 2\n"},
+		{"[03 01 02]\n", Options{Unordered: true}, "[01 02 03]\n"},
+		{"[03 01 02]\n", Options{}, "[03 01 02]\n"},
+	}
+	for _, tt := range tests {
+		if got := string(Normalize([]byte(tt.in), tt.opt)); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTrailingSpace(t *testing.T) {
+	root := t.TempDir()
+	l := lesson.Lesson{Package: "c3/3.pointer", Name: "TestP6"}
+	if _, err := Check(root, l, []byte("*int \n"), nil, true); err != nil {
+		t.Fatal(err)
+	}
+	diff, err := Check(root, l, []byte("*int\n"), nil, false)
+	if err != nil || diff != "" {
+		t.Errorf("got diff %q, %v, want trailing spaces ignored", diff, err)
+	}
+	if diff, _ := Check(root, l, []byte("*bool\n"), nil, false); diff == "" {
+		t.Error("got no diff for different output")
+	}
+}
+
+// TestLessons 运行所有输出稳定的示例，并与 golden 文件比较。
+// 修改示例后用 go test ./internal/golden -update 更新 golden 文件。
+func TestLessons(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every lesson")
+	}
+	root := coursetest.Root(t)
+
+	byPackage := map[string][]lesson.Lesson{}
+	for _, l := range lesson.All() {
+		if Stable(l) {
+			byPackage[l.Package] = append(byPackage[l.Package], l)
+		}
+	}
+
+	ctx := context.Background()
+	for pkg, ls := range byPackage {
+		bin, cleanup, err := runner.Build(ctx, root, pkg, false)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for _, l := range ls {
+			res, err := runner.Exec(ctx, filepath.Join(root, pkg), bin, runner.Args(l.Name), runner.Options{Timeout: 30 * time.Second})
+			if err != nil {
+				t.Errorf("%s: %v", l.ID, err)
+				continue
+			}
+			if res.Failed() {
+				t.Errorf("%s: exit status %d\n%s%s", l.ID, res.ExitCode, res.Stdout, res.Stderr)
+				continue
+			}
+			diff, err := Check(root, l, res.Stdout, res.Stderr, *update)
+			if err != nil {
+				t.Errorf("%s: %v", l.ID, err)
+				continue
+			}
+			if diff != "" {
+				t.Errorf("%s: output differs from %s (-golden +got):\n%s", l.ID, Path(root, l), diff)
+			}
+		}
+		cleanup()
+	}
+}
@@ -0,0 +1,117 @@
+package grade
+
+// Exercises 所有练习题及其隐藏用例。
+var Exercises = []*Exercise{
+	{
+		ID:   "c3/3.pointer/TestP9",
+		File: "c3/3.pointer/pointer_test.go",
+		Func: "swap",
+		Sig:  "func swap(a, b *int)",
+		Cases: `
+	var _ func(a, b *int) = swap
+	for _, c := range []struct{ name string; x, y int }{
+		{"example", 1, 2},
+		{"negative", -5, 7},
+		{"equal", 3, 3},
+		{"zero", 0, 42},
+	} {
+		x, y := c.x, c.y
+		gradeCheck(c.name, fmt.Sprintf("x, y := %d, %d; swap(&x, &y)", c.x, c.y), fmt.Sprint(c.y, c.x),
+			"b, a = a, b 只交换了两个形参（指针的副本），要交换的是指针指向的值：*a, *b = *b, *a",
+			func() string {
+				swap(&x, &y)
+				return fmt.Sprint(x, y)
+			})
+	}`,
+	},
+	{
+		ID:   "c3/4.arr/Test_A7",
+		File: "c3/4.arr/arr_test.go",
+		Func: "myTest",
+		Sig:  "func myTest(a [5]int, target int) // 每找到一对下标打印一行 (i,j)",
+		Cases: `
+	var _ func(a [5]int, target int) = myTest
+	for _, c := range []struct {
+		name    string
+		a       [5]int
+		target  int
+		want    string
+		explain string
+	}{
+		{"example", [5]int{1, 3, 5, 8, 7}, 8, "(0,4) (1,2)", ""},
+		{"no-pair", [5]int{1, 2, 3, 4, 5}, 100, "", "没有符合条件的两个元素时什么都不打印"},
+		{"no-self-pair", [5]int{4, 1, 2, 3, 5}, 8, "(3,4)", "同一个元素不能使用两次，4 + 4 不算"},
+		{"duplicates", [5]int{2, 2, 2, 9, 9}, 4, "(0,1) (0,2) (1,2)", "值相同但下标不同的元素可以组成一对"},
+		{"negative", [5]int{-1, 9, 0, 8, 5}, 8, "(0,1) (2,3)", "负数和 0 也要考虑"},
+		{"order", [5]int{7, 6, 5, 2, 1}, 8, "(0,4) (1,3)", "按 i 从小到大输出，每对中 i < j"},
+	} {
+		a := c.a
+		gradeCheck(c.name, fmt.Sprintf("myTest(%v, %d)", c.a, c.target), c.want, c.explain, func() string {
+			return strings.Join(strings.Fields(gradeCapture(func() { myTest(a, c.target) })), " ")
+		})
+	}`,
+	},
+	{
+		ID:   "c3/5.slice/Test_S12",
+		File: "c3/5.slice/slice_test.go",
+		Func: "clear",
+		Sig:  "func clear(strs []string) int // 返回去重后的长度，去重结果在 strs[:n] 中",
+		Cases: `
+	var _ func(strs []string) int = clear
+	for _, c := range []struct {
+		name    string
+		in      []string
+		want    []string
+		explain string
+	}{
+		{"example", []string{"abc", "abd", "abe", "abe", "abf", "abg", "abg"}, []string{"abc", "abd", "abe", "abf", "abg"}, ""},
+		{"no-duplicates", []string{"a", "b", "c"}, []string{"a", "b", "c"}, "没有重复时长度不变，不能多返回 1"},
+		{"one-pair", []string{"a", "a"}, []string{"a"}, "只有一对重复时结果只剩一个元素"},
+		{"pairs", []string{"a", "a", "b", "b"}, []string{"a", "b"}, "删除一个元素后，下标 i 不要跳过刚移过来的元素"},
+		{"not-adjacent", []string{"a", "b", "a"}, []string{"a", "b", "a"}, "只消除相邻的重复"},
+		{"empty", []string{}, []string{}, "空切片返回 0"},
+		{"single", []string{"x"}, []string{"x"}, ""},
+	} {
+		in := append([]string(nil), c.in...)
+		gradeCheck(c.name, fmt.Sprintf("clear(%q)", c.in), fmt.Sprintf("%q", c.want), c.explain, func() string {
+			n := clear(in)
+			if n < 0 || n > len(in) {
+				return fmt.Sprintf("returned %d for %d strings", n, len(c.in))
+			}
+			return fmt.Sprintf("%q", in[:n])
+		})
+	}`,
+	},
+	{
+		ID:   "c4/1.function/TestF10",
+		File: "c4/1.function/func_test.go",
+		Func: "TestF10",
+		Sig:  "func TestF10(t *testing.T) // 打开 1.txt 和 2.txt，结束前各自关闭",
+		Cases: `
+	dir, err := os.MkdirTemp("", "grade-f10-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	os.WriteFile("1.txt", []byte("1"), 0o644)
+	os.WriteFile("2.txt", []byte("2"), 0o644)
+
+	before := gradeOpenFiles()
+	out := gradeCapture(func() { TestF10(nil) })
+	gradeCheck("no-double-close", "1.txt 和 2.txt 都存在", "", "两个 defer 的闭包引用的是同一个变量 f，执行时 f 已经指向 2.txt，2.txt 被关闭了两次",
+		func() string { return strings.TrimSpace(out) })
+	gradeCheck("closes-1.txt", "1.txt 和 2.txt 都存在", fmt.Sprint(before, " open files"), "1.txt 没有被关闭：把 f 作为参数传给 defer 的函数，或者给第二个文件换一个变量",
+		func() string { return fmt.Sprint(gradeOpenFiles(), " open files") })
+
+	os.Remove("2.txt")
+	before = gradeOpenFiles()
+	out = gradeCapture(func() { TestF10(nil) })
+	gradeCheck("second-open-fails", "只有 1.txt", fmt.Sprint(before, " open files"), "打开 2.txt 失败直接 return 时，1.txt 也要被关闭",
+		func() string { return fmt.Sprint(gradeOpenFiles(), " open files") })
+	gradeCheck("second-open-fails-output", "只有 1.txt", "", "",
+		func() string { return strings.TrimSpace(out) })`,
+	},
+}
@@ -0,0 +1,150 @@
+// Package grade 给练习题评分。
+//
+// 评分时从学员的源文件中取出练习函数（连同文件里它可能用到的全局变量和辅助函数，
+// 去掉其他 Test 函数），改成 package main，再加上一个带隐藏用例的 harness，
+// 在临时模块中编译运行。harness 里用固定签名引用练习函数，签名不对时编译失败。
+// 每个用例输出一行 JSON 结果，由 Grade 汇总成分数。
+package grade
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/runner"
+	"study/internal/sandbox"
+)
+
+// Timeout 单个练习编译后运行的时间上限。
+var Timeout = 10 * time.Second
+
+// Exercise 一道练习题。
+type Exercise struct {
+	ID   string // 对应示例的 ID，例如 c3/4.arr/Test_A7
+	File string // 默认从这个文件读取学员的实现，相对仓库根目录
+	Func string // 练习函数名
+	Sig  string // 练习函数的签名，提示给学员
+	// Cases 是 harness 中 gradeCases 函数的函数体，调用 gradeCheck 检查每个用例
+	Cases string
+}
+
+// Case 一个用例的结果。
+type Case struct {
+	Name    string `json:"name"`
+	Pass    bool   `json:"pass"`
+	Input   string `json:"input,omitempty"`
+	Got     string `json:"got"`
+	Want    string `json:"want"`
+	Explain string `json:"explain,omitempty"`
+}
+
+// Report 一道练习的评分结果。
+type Report struct {
+	Exercise *Exercise
+	Source   string // 实际评分的源文件
+	Cases    []Case
+	BuildErr string // 编译失败时的输出，此时 Cases 为空
+}
+
+// Passed 通过的用例数。
+func (r *Report) Passed() int {
+	n := 0
+	for _, c := range r.Cases {
+		if c.Pass {
+			n++
+		}
+	}
+	return n
+}
+
+// Score 百分制分数，编译失败为 0。
+func (r *Report) Score() int {
+	if len(r.Cases) == 0 {
+		return 0
+	}
+	return r.Passed() * 100 / len(r.Cases)
+}
+
+// Lookup 按示例 ID 或包目录查找练习。
+func Lookup(id string) *Exercise {
+	for _, e := range Exercises {
+		if e.ID == id || strings.HasPrefix(e.ID, strings.TrimSuffix(id, "/")+"/") {
+			return e
+		}
+	}
+	return nil
+}
+
+// Grade 对 src 中的实现评分，src 为空时使用仓库中的练习文件。学员的代码在沙箱中运行。
+func Grade(ctx context.Context, root string, e *Exercise, src string) (*Report, error) {
+	if src == "" {
+		src = filepath.Join(root, filepath.FromSlash(e.File))
+	}
+	r := &Report{Exercise: e, Source: src}
+
+	prog, err := course.MainProgram(src, e.Func)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "study-grade-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":     "module grade\n\ngo 1.17\n",
+		"learner.go": prog,
+		"harness.go": harness(e),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	bin, cleanup, err := runner.Build(ctx, dir, ".", true)
+	if err != nil {
+		r.BuildErr = err.Error()
+		return r, nil
+	}
+	defer cleanup()
+
+	res, err := runner.Exec(ctx, dir, bin, nil, runner.Options{Timeout: Timeout, Sandbox: &sandbox.DefaultLimits})
+	if err != nil {
+		return nil, err
+	}
+	r.Cases = parseCases(res.Stdout)
+	switch {
+	case res.TimedOut:
+		r.Cases = append(r.Cases, Case{Name: "timeout", Got: "still running after " + Timeout.String(), Want: "finish", Explain: "是不是写出了死循环？"})
+	case res.Signal != "":
+		r.Cases = append(r.Cases, Case{Name: "killed", Got: res.Signal, Want: "finish", Explain: "超出了沙箱的 CPU、内存或进程数限制"})
+	case res.ExitCode != 0 && len(r.Cases) == 0:
+		r.BuildErr = fmt.Sprintf("exit status %d\n%s", res.ExitCode, res.Stderr)
+	}
+	return r, nil
+}
+
+func parseCases(out []byte) []Case {
+	var cases []Case
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		line := s.Bytes()
+		if !bytes.HasPrefix(line, []byte(resultPrefix)) {
+			continue
+		}
+		var c Case
+		if json.Unmarshal(line[len(resultPrefix):], &c) == nil {
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
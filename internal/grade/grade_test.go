@@ -0,0 +1,81 @@
+package grade
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"study/internal/course/coursetest"
+	"study/internal/lesson"
+	_ "study/internal/lesson/all"
+)
+
+// TestExercises 检查每道登记为练习的示例都有评分用例。
+func TestExercises(t *testing.T) {
+	for _, l := range lesson.All() {
+		if l.Exercise && Lookup(l.ID) == nil {
+			t.Errorf("exercise %s has no grader", l.ID)
+		}
+	}
+	for _, e := range Exercises {
+		if l, ok := lesson.Lookup(e.ID); !ok || !l.Exercise {
+			t.Errorf("%s is not a registered exercise", e.ID)
+		}
+	}
+}
+
+// TestGrade 用 testdata 中的参考答案评分，参考答案应该拿满分。
+func TestGrade(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds every exercise")
+	}
+	root := coursetest.Root(t)
+	solutions := map[string]string{
+		"c3/3.pointer/TestP9":   "swap.go",
+		"c3/4.arr/Test_A7":      "twosum.go",
+		"c3/5.slice/Test_S12":   "clear.go",
+		"c4/1.function/TestF10": "defer.go",
+	}
+	for id, file := range solutions {
+		r, err := Grade(context.Background(), root, Lookup(id), filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.BuildErr != "" {
+			t.Errorf("%s: build failed:\n%s", id, r.BuildErr)
+			continue
+		}
+		if r.Score() != 100 {
+			t.Errorf("%s: score = %d, want 100: %+v", id, r.Score(), r.Cases)
+		}
+	}
+}
+
+// TestGradeLesson 仓库中的 swap 是错的（练习就是要发现这一点），不能拿满分。
+func TestGradeLesson(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds an exercise")
+	}
+	root := coursetest.Root(t)
+	r, err := Grade(context.Background(), root, Lookup("c3/3.pointer"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Cases) == 0 || r.Cases[0].Pass {
+		t.Errorf("swap passed %d of %d cases, want the example to fail", r.Passed(), len(r.Cases))
+	}
+}
+
+func TestSignature(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds an exercise")
+	}
+	root := coursetest.Root(t)
+	r, err := Grade(context.Background(), root, Lookup("c3/3.pointer/TestP9"), filepath.Join("testdata", "badsig.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.BuildErr == "" || r.Score() != 0 {
+		t.Errorf("wrong signature was not rejected: %+v", r)
+	}
+}
@@ -0,0 +1,93 @@
+package grade
+
+import (
+	"fmt"
+	"strings"
+)
+
+const resultPrefix = "GRADE "
+
+// harnessPrelude harness 的公共部分。标识符都以 grade 开头，避免和学员的代码重名。
+const harnessPrelude = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var _ = strings.TrimSpace
+
+type gradeResult struct {
+	Name    string ` + "`json:\"name\"`" + `
+	Pass    bool   ` + "`json:\"pass\"`" + `
+	Input   string ` + "`json:\"input,omitempty\"`" + `
+	Got     string ` + "`json:\"got\"`" + `
+	Want    string ` + "`json:\"want\"`" + `
+	Explain string ` + "`json:\"explain,omitempty\"`" + `
+}
+
+// gradeCheck 运行 got，与 want 比较并输出一行结果；got 中的 panic 算作失败。
+func gradeCheck(name, input, want, explain string, got func() string) {
+	r := gradeResult{Name: name, Input: input, Want: want, Explain: explain}
+	func() {
+		defer func() {
+			if e := recover(); e != nil {
+				r.Got = fmt.Sprintf("panic: %v", e)
+			}
+		}()
+		r.Got = got()
+		r.Pass = r.Got == want
+	}()
+	data, _ := json.Marshal(r)
+	fmt.Fprintf(gradeStdout, "%s%s\n", "` + resultPrefix + `", data)
+}
+
+var gradeStdout = os.Stdout
+
+// gradeCapture 捕获 f 写到 os.Stdout 的内容。
+func gradeCapture(f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		panic(err)
+	}
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		r.Close()
+		done <- string(data)
+	}()
+	os.Stdout = w
+	func() {
+		defer func() {
+			os.Stdout = gradeStdout
+			w.Close()
+		}()
+		f()
+	}()
+	return <-done
+}
+
+// gradeOpenFiles 返回当前进程打开的文件数，不支持时返回 -1。
+func gradeOpenFiles() int {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(fds)
+}
+
+func main() {
+	gradeCases()
+}
+`
+
+// harness 生成练习 e 的 harness 源码。
+func harness(e *Exercise) string {
+	var b strings.Builder
+	b.WriteString(harnessPrelude)
+	fmt.Fprintf(&b, "\nfunc gradeCases() {\n%s\n}\n", strings.Trim(e.Cases, "\n"))
+	return b.String()
+}
@@ -0,0 +1,15 @@
+package slice
+
+func clear(strs []string) int {
+	if len(strs) == 0 {
+		return 0
+	}
+	n := 1
+	for i := 1; i < len(strs); i++ {
+		if strs[i] != strs[n-1] {
+			strs[n] = strs[i]
+			n++
+		}
+	}
+	return n
+}
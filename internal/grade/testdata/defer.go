@@ -0,0 +1,31 @@
+package function
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestF10(t *testing.T) {
+	f, err := os.Open("1.txt")
+	if err != nil {
+		return
+	}
+
+	defer func(f *os.File) {
+		if err := f.Close(); err != nil {
+			fmt.Printf("defer close 1.txt err %v\n", err)
+		}
+	}(f)
+
+	f, err = os.Open("2.txt")
+	if err != nil {
+		return
+	}
+
+	defer func(f *os.File) {
+		if err := f.Close(); err != nil {
+			fmt.Printf("defer close 2.txt err %v\n", err)
+		}
+	}(f)
+}
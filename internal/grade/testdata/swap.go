@@ -0,0 +1,5 @@
+package pointer
+
+func swap(a, b *int) {
+	*a, *b = *b, *a
+}
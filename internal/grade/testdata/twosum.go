@@ -0,0 +1,13 @@
+package arr
+
+import "fmt"
+
+func myTest(a [5]int, target int) {
+	for i := 0; i < len(a); i++ {
+		for j := i + 1; j < len(a); j++ {
+			if a[i]+a[j] == target {
+				fmt.Printf("(%d,%d)\n", i, j)
+			}
+		}
+	}
+}
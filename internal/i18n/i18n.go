@@ -0,0 +1,236 @@
+// Package i18n 是课程说明文字的多语言目录。
+//
+// 中文原文来自示例的标题和源码中的说明注释（见 course.Notes），由 Extract 提取，
+// 保存在 i18n/zh.json 中。其他语言的译文放在 i18n/<lang>.json，每条译文同时记录翻译时的中文原文，
+// 原文改了而译文没有跟着改时，Check 把它标为过期，Texts 也不再使用它，回退到中文。
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"study/internal/course"
+)
+
+// Source 原文的语言。
+const Source = "zh"
+
+// Dir 目录文件所在的目录，相对仓库根目录。
+const Dir = "i18n"
+
+// Translation 一条译文。
+type Translation struct {
+	Source string `json:"source"` // 翻译时的中文原文
+	Text   string `json:"text"`
+}
+
+// TitleKey 返回示例标题的键，例如 c3/4.arr/Test_A1#title。
+func TitleKey(l *course.Lesson) string {
+	return l.Path() + "#title"
+}
+
+// ChapterKey 返回章节标题的键，例如 c3#title。
+func ChapterKey(ch *course.Chapter) string {
+	return ch.Name + "#title"
+}
+
+// NoteKeys 返回 p 中每段说明注释的键：注释后面有示例时为 包目录/示例#序号，
+// 例如 c3/4.arr/Test_A1#1，否则为 文件#序号，例如 c3/1.internal/internal.go#2。
+// 序号从 1 开始，在同一个示例或文件中按出现的顺序编号，增删别处的注释不会影响它。
+func NoteKeys(p *course.Package, notes []*course.Note) []string {
+	keys := make([]string, len(notes))
+	count := map[string]int{}
+	for i, n := range notes {
+		prefix := n.File
+		if n.Lesson != "" {
+			prefix = p.Dir + "/" + n.Lesson
+		}
+		count[prefix]++
+		keys[i] = prefix + "#" + strconv.Itoa(count[prefix])
+	}
+	return keys
+}
+
+// Extract 提取课程中全部需要翻译的中文：章节标题、示例标题和说明注释。
+func Extract(c *course.Course) (map[string]string, error) {
+	texts := map[string]string{}
+	for _, ch := range c.Chapters {
+		if ch.Title != "" {
+			texts[ChapterKey(ch)] = ch.Title
+		}
+		for _, p := range ch.Packages {
+			for _, l := range p.Lessons {
+				if l.Title != "" {
+					texts[TitleKey(l)] = l.Title
+				}
+			}
+			notes, err := course.Notes(c.Root, p)
+			if err != nil {
+				return nil, err
+			}
+			for i, key := range NoteKeys(p, notes) {
+				texts[key] = notes[i].Text
+			}
+		}
+	}
+	return texts, nil
+}
+
+// Path 返回 lang 的目录文件。
+func Path(root, lang string) string {
+	return filepath.Join(root, Dir, lang+".json")
+}
+
+// LoadSource 读取 i18n/zh.json。
+func LoadSource(root string) (map[string]string, error) {
+	texts := map[string]string{}
+	return texts, load(Path(root, Source), &texts)
+}
+
+// WriteSource 把 Extract 的结果写入 i18n/zh.json。
+func WriteSource(root string, texts map[string]string) error {
+	return write(Path(root, Source), texts)
+}
+
+// LoadTranslations 读取 lang 的译文。
+func LoadTranslations(root, lang string) (map[string]Translation, error) {
+	tr := map[string]Translation{}
+	return tr, load(Path(root, lang), &tr)
+}
+
+// WriteTranslations 写入 lang 的译文。
+func WriteTranslations(root, lang string, tr map[string]Translation) error {
+	return write(Path(root, lang), tr)
+}
+
+func load(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("i18n: %s: %v", path, err)
+	}
+	return nil
+}
+
+// write 写入 JSON，键按字母顺序排列（encoding/json 对 map 的键排序），方便在 diff 中查看。
+// 注释里有不少 < 和 &，不转义成 \u003c，译者才看得懂。
+func write(path string, v interface{}) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+// Status 译文和当前原文的对比结果，键按字母顺序排列。
+type Status struct {
+	Missing []string // 没有译文
+	Stale   []string // 翻译之后中文原文改过
+	Orphan  []string // 原文已经不存在
+}
+
+// OK 译文是否完整并且都是最新的。
+func (s *Status) OK() bool {
+	return len(s.Missing) == 0 && len(s.Stale) == 0 && len(s.Orphan) == 0
+}
+
+// Check 对比原文 src 和译文 tr。
+func Check(src map[string]string, tr map[string]Translation) *Status {
+	s := &Status{}
+	for key, text := range src {
+		t, ok := tr[key]
+		switch {
+		case !ok:
+			s.Missing = append(s.Missing, key)
+		case t.Source != text:
+			s.Stale = append(s.Stale, key)
+		}
+	}
+	for key := range tr {
+		if _, ok := src[key]; !ok {
+			s.Orphan = append(s.Orphan, key)
+		}
+	}
+	sort.Strings(s.Missing)
+	sort.Strings(s.Stale)
+	sort.Strings(s.Orphan)
+	return s
+}
+
+// Texts 一种语言的课程文字。缺少或过期的译文回退到中文原文。
+type Texts struct {
+	Lang string
+	tr   map[string]Translation
+}
+
+// Load 读取 lang 的译文。lang 为空或 zh 时直接使用原文。
+func Load(root, lang string) (*Texts, error) {
+	t := &Texts{Lang: lang}
+	if lang == "" || lang == Source {
+		t.Lang = Source
+		return t, nil
+	}
+	tr, err := LoadTranslations(root, lang)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("i18n: no %s translation (%s)", lang, Path(root, lang))
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.tr = tr
+	return t, nil
+}
+
+// Lookup 返回 key 的译文，原文是 zh。没有译文或译文已经过期时返回 zh 和 false，
+// 使用原文时总是返回 true。
+func (t *Texts) Lookup(key, zh string) (string, bool) {
+	if t.Lang == Source {
+		return zh, true
+	}
+	if tr, ok := t.tr[key]; ok && tr.Source == zh {
+		return tr.Text, true
+	}
+	return zh, false
+}
+
+// Text 与 Lookup 相同，只返回文字。
+func (t *Texts) Text(key, zh string) string {
+	text, _ := t.Lookup(key, zh)
+	return text
+}
+
+// Title 返回示例的标题。
+func (t *Texts) Title(l *course.Lesson) string {
+	return t.Text(TitleKey(l), l.Title)
+}
+
+// ChapterTitle 返回章节的标题。
+func (t *Texts) ChapterTitle(ch *course.Chapter) string {
+	return t.Text(ChapterKey(ch), ch.Title)
+}
+
+// Notes 返回 p 中的说明注释，Text 换成译文。
+func (t *Texts) Notes(root string, p *course.Package) ([]*course.Note, error) {
+	notes, err := course.Notes(root, p)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range NoteKeys(p, notes) {
+		notes[i].Text = t.Text(key, notes[i].Text)
+	}
+	return notes, nil
+}
@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+var update = flag.Bool("update", false, "rewrite i18n/zh.json with the current comments")
+
+// TestSource 检查 i18n/zh.json 和源码中的注释一致。
+// 修改注释或示例标题后用 go test ./internal/i18n -update 更新。
+func TestSource(t *testing.T) {
+	c := coursetest.Load(t)
+	src, err := Extract(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := WriteSource(c.Root, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved, err := LoadSource(c.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := Check(src, toTranslations(saved)); !s.OK() {
+		t.Errorf("i18n/zh.json is out of date (run go test ./internal/i18n -update): %+v", s)
+	}
+
+	for key, want := range map[string]string{
+		"c3/4.arr/Test_A1#title": "数组的定义和初始化",
+		"c4/2.map/map_test.go#1": "map 原理部分",
+	} {
+		if got := src[key]; len(got) < len(want) || got[:len(want)] != want {
+			t.Errorf("%s = %q, want prefix %q", key, got, want)
+		}
+	}
+}
+
+func toTranslations(texts map[string]string) map[string]Translation {
+	tr := map[string]Translation{}
+	for key, text := range texts {
+		tr[key] = Translation{Source: text, Text: text}
+	}
+	return tr
+}
+
+// TestEnglish 英文译文不能有原文已经删除的条目；过期和缺少的条目用 study i18n check 查看。
+func TestEnglish(t *testing.T) {
+	c := coursetest.Load(t)
+	src, err := Extract(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := LoadTranslations(c.Root, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := Check(src, tr); len(s.Orphan) > 0 {
+		t.Errorf("i18n/en.json has entries for removed text: %v", s.Orphan)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	src := map[string]string{"a": "一", "b": "二", "c": "三"}
+	tr := map[string]Translation{
+		"a": {Source: "一", Text: "one"},
+		"b": {Source: "二（旧）", Text: "two"},
+		"d": {Source: "四", Text: "four"},
+	}
+	got := Check(src, tr)
+	want := &Status{Missing: []string{"c"}, Stale: []string{"b"}, Orphan: []string{"d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check = %+v, want %+v", got, want)
+	}
+
+	texts := &Texts{Lang: "en", tr: tr}
+	for key, want := range map[string]string{"a": "one", "b": "二", "c": "三"} {
+		if got := texts.Text(key, src[key]); got != want {
+			t.Errorf("Text(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestNoteKeys(t *testing.T) {
+	c := coursetest.Load(t)
+	p := c.Package("c3/4.arr")
+	notes, err := course.Notes(c.Root, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NoteKeys(p, notes)
+	if len(keys) < 2 || keys[0] != "c3/4.arr/Test_A1#1" || keys[1] != "c3/4.arr/Test_A1#2" {
+		t.Errorf("NoteKeys = %v", keys)
+	}
+}
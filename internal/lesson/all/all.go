@@ -0,0 +1,17 @@
+// Package all 导入所有课程包，使它们的示例登记到 lesson 包中。
+package all
+
+import (
+	_ "study/c3/2.control"
+	_ "study/c3/3.pointer"
+	_ "study/c3/4.arr"
+	_ "study/c3/5.slice"
+	_ "study/c4/1.function"
+	_ "study/c4/2.map"
+	_ "study/c5/1.strcut"
+	_ "study/c5/2.method"
+	_ "study/c5/3.interface"
+	_ "study/c6/1.goroutine"
+	_ "study/c6/2.channel"
+	_ "study/c6/3.concurrencyControl"
+)
@@ -0,0 +1,44 @@
+package all
+
+import (
+	"testing"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	"study/internal/lesson"
+)
+
+// TestRegistered 检查源码中的每个示例都已登记，登记的示例也都存在。
+func TestRegistered(t *testing.T) {
+	c := coursetest.Load(t)
+
+	found := map[string]bool{}
+	for _, l := range c.Lessons() {
+		if l.Name == course.MainLesson {
+			continue
+		}
+		found[l.Path()] = true
+		if l.Meta == nil {
+			t.Errorf("%s:%d: %s is not registered", l.File, l.Line, l.Path())
+		}
+	}
+	for _, l := range lesson.All() {
+		if !found[l.ID] {
+			t.Errorf("%s is registered but has no Test function", l.ID)
+		}
+		if l.Title == "" {
+			t.Errorf("%s has no title", l.ID)
+		}
+	}
+}
+
+func TestQualifiedID(t *testing.T) {
+	a, ok1 := lesson.Lookup("c4/2.map/TestM1")
+	b, ok2 := lesson.Lookup("c5/2.method/TestM1")
+	if !ok1 || !ok2 {
+		t.Fatal("TestM1 is not registered in both c4/2.map and c5/2.method")
+	}
+	if a.Chapter != "c4" || b.Chapter != "c5" {
+		t.Errorf("chapters = %q, %q; want c4, c5", a.Chapter, b.Chapter)
+	}
+}
@@ -0,0 +1,179 @@
+// Package lesson 是课程示例的登记表。
+//
+// 每个课程包在 init 中调用 Register 登记自己的示例（标题、标签、是否练习、预期行为），
+// 运行器、评分器等工具导入 study/internal/lesson/all 后即可通过 All、Lookup 找到全部示例，
+// 不需要再去猜 go test -run 的正则。
+package lesson
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Behavior 示例运行时的预期行为。
+type Behavior int
+
+const (
+	Prints Behavior = iota // 正常运行结束
+	Panics                 // 故意触发 panic
+	Blocks                 // 故意阻塞，死锁或一直等待
+	Fatal                  // 触发运行时的 fatal error（不能 recover），也可能一直阻塞
+)
+
+var behaviorNames = [...]string{
+	Prints: "prints",
+	Panics: "panics",
+	Blocks: "blocks",
+	Fatal:  "fatal",
+}
+
+func (b Behavior) String() string {
+	if int(b) < len(behaviorNames) {
+		return behaviorNames[b]
+	}
+	return fmt.Sprintf("Behavior(%d)", int(b))
+}
+
+// MarshalText 让 Behavior 在 JSON 中以名字出现。
+func (b Behavior) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText 是 MarshalText 的逆操作。
+func (b *Behavior) UnmarshalText(text []byte) error {
+	for i, name := range behaviorNames {
+		if name == string(text) {
+			*b = Behavior(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("lesson: unknown behavior %q", text)
+}
+
+// 输出不稳定的示例使用的标签。
+const (
+	TagUnordered = "unordered" // 输出依赖 map 的遍历顺序
+	TagAddress   = "address"   // 输出包含内存地址
+	TagRacy      = "racy"      // 输出依赖 goroutine 的调度
+	TagSlow      = "slow"      // 示例中有 time.Sleep，要真的等待；用 clock 包的虚拟时间的不算
+)
+
+// TagPuzzle 标记适合“猜输出”的示例：输出确定，但容易猜错。
+const TagPuzzle = "puzzle"
+
+// Aliases 主题标签在讲义和大纲中的叫法，用来把示例对应到讲义的页和大纲的主题。
+var Aliases = map[string][]string{
+	"control":   {"控制结构"},
+	"pointer":   {"指针"},
+	"array":     {"数组", "array"},
+	"slice":     {"切片", "slice"},
+	"map":       {"map"},
+	"string":    {"字符串", "string"},
+	"function":  {"golang函数", "函数的特点", "返回值"},
+	"closure":   {"闭包", "匿名函数"},
+	"defer":     {"defer"},
+	"struct":    {"结构体", "struct"},
+	"embedding": {"嵌入", "继承"},
+	"method":    {"方法"},
+	"interface": {"接口", "interface"},
+	"goroutine": {"协程", "goroutine"},
+	"channel":   {"通道", "chan", "channel", "chanel"}, // 大纲里写的是 chanel
+	"select":    {"select"},
+	"sync":      {"sync", "基于共享变量的并发"},
+}
+
+// StopWords 示例标题中太常见、不能用来匹配讲义和大纲的英文单词，都是小写。
+var StopWords = map[string]bool{"go": true, "golang": true, "type": true, "nil": true}
+
+// Lesson 一个示例的元信息。
+type Lesson struct {
+	ID       string   `json:"id"`      // 包目录/函数名，例如 c4/2.map/TestM1，全局唯一
+	Name     string   `json:"name"`    // 函数名，例如 TestM1
+	Chapter  string   `json:"chapter"` // 章节，例如 c4
+	Package  string   `json:"package"` // 包目录，例如 c4/2.map
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags,omitempty"`
+	Exercise bool     `json:"exercise,omitempty"` // 练习题
+	Expect   Behavior `json:"expect"`
+}
+
+// HasTag 判断示例是否带有 tag 标签。
+func (l Lesson) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	mu      sync.RWMutex
+	lessons = map[string]Lesson{}
+)
+
+// Register 登记 pkg 包（相对仓库根目录的路径）中的示例，并补全 ID、Chapter、Package。
+// 同一个 ID 登记两次会 panic。
+func Register(pkg string, ls ...Lesson) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	chapter := pkg
+	if i := strings.Index(pkg, "/"); i >= 0 {
+		chapter = pkg[:i]
+	}
+	for _, l := range ls {
+		l.Package = pkg
+		l.Chapter = chapter
+		l.ID = pkg + "/" + l.Name
+		if _, dup := lessons[l.ID]; dup {
+			panic("lesson: Register called twice for " + l.ID)
+		}
+		lessons[l.ID] = l
+	}
+}
+
+// Lookup 按 ID 查找示例。
+func Lookup(id string) (Lesson, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	l, ok := lessons[id]
+	return l, ok
+}
+
+// All 返回全部示例，按 ID 排序。
+func All() []Lesson {
+	mu.RLock()
+	defer mu.RUnlock()
+	ls := make([]Lesson, 0, len(lessons))
+	for _, l := range lessons {
+		ls = append(ls, l)
+	}
+	sort.Slice(ls, func(i, j int) bool { return Less(ls[i], ls[j]) })
+	return ls
+}
+
+// Less 按包目录、再按函数名中的数字排序，Test_S2 排在 Test_S10 前面。
+func Less(a, b Lesson) bool {
+	if a.Package != b.Package {
+		return a.Package < b.Package
+	}
+	pa, na := splitNumber(a.Name)
+	pb, nb := splitNumber(b.Name)
+	if pa != pb {
+		return pa < pb
+	}
+	return na < nb
+}
+
+func splitNumber(name string) (string, int) {
+	i := len(name)
+	for i > 0 && name[i-1] >= '0' && name[i-1] <= '9' {
+		i--
+	}
+	n := 0
+	fmt.Sscan(name[i:], &n)
+	return name[:i], n
+}
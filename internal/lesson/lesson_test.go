@@ -0,0 +1,40 @@
+package lesson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBehaviorJSON(t *testing.T) {
+	for _, b := range []Behavior{Prints, Panics, Blocks, Fatal} {
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Behavior
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != b {
+			t.Errorf("round trip of %v = %v", b, got)
+		}
+	}
+}
+
+func TestLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Test_S2", "Test_S10", true},
+		{"Test_S10", "Test_S2", false},
+		{"TestC5", "TestS1", true},
+		{"TestF8", "TestF10", true},
+	}
+	for _, tt := range tests {
+		got := Less(Lesson{Package: "p", Name: tt.a}, Lesson{Package: "p", Name: tt.b})
+		if got != tt.want {
+			t.Errorf("Less(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
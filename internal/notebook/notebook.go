@@ -0,0 +1,602 @@
+// Package notebook 把课程包导出为 Jupyter notebook（nbformat 4.5）。
+//
+// 说明注释和包目录中的 .md 文件变成 Markdown 单元格，图片作为附件内嵌；
+// 每个示例的函数体改写成顶层语句放进代码单元格，类型、方法等声明原样放进代码单元格，
+// 可以在 Go 内核（gophernotes）中从上到下运行。
+package notebook
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/i18n"
+	"study/internal/lesson"
+)
+
+// Notebook 一个 notebook 文件。
+type Notebook struct {
+	Cells    []*Cell  `json:"cells"`
+	Metadata Metadata `json:"metadata"`
+	Format   int      `json:"nbformat"`
+	Minor    int      `json:"nbformat_minor"`
+}
+
+// Metadata notebook 的元数据，指定运行代码单元格的内核。
+type Metadata struct {
+	Kernel   Kernel   `json:"kernelspec"`
+	Language Language `json:"language_info"`
+}
+
+// Kernel 内核。
+type Kernel struct {
+	DisplayName string `json:"display_name"`
+	Language    string `json:"language"`
+	Name        string `json:"name"`
+}
+
+// Language 代码单元格的语言。
+type Language struct {
+	Name          string `json:"name"`
+	FileExtension string `json:"file_extension"`
+	Mimetype      string `json:"mimetype"`
+}
+
+// 单元格的类型。
+const (
+	Markdown = "markdown"
+	Code     = "code"
+)
+
+// Cell 一个单元格。Attachments 的键是附件名，值是 MIME 类型到 base64 数据的映射。
+type Cell struct {
+	Type        string
+	ID          string
+	Source      string
+	Attachments map[string]map[string]string
+}
+
+// MarshalJSON 按 nbformat 的要求输出：source 按行拆成数组，
+// 代码单元格带空的 outputs 和为 null 的 execution_count。
+func (c *Cell) MarshalJSON() ([]byte, error) {
+	source := []string{}
+	if c.Source != "" {
+		source = strings.SplitAfter(c.Source, "\n")
+	}
+	if c.Type == Code {
+		return json.Marshal(struct {
+			Type           string        `json:"cell_type"`
+			ExecutionCount *int          `json:"execution_count"`
+			ID             string        `json:"id"`
+			Metadata       struct{}      `json:"metadata"`
+			Outputs        []interface{} `json:"outputs"`
+			Source         []string      `json:"source"`
+		}{Type: c.Type, ID: c.ID, Outputs: []interface{}{}, Source: source})
+	}
+	return json.Marshal(struct {
+		Attachments map[string]map[string]string `json:"attachments,omitempty"`
+		Type        string                       `json:"cell_type"`
+		ID          string                       `json:"id"`
+		Metadata    struct{}                     `json:"metadata"`
+		Source      []string                     `json:"source"`
+	}{c.Attachments, c.Type, c.ID, struct{}{}, source})
+}
+
+// Encode 返回 notebook 文件的内容。
+func (nb *Notebook) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(nb, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FileName 返回课程包导出的文件名，例如 c4_2.map.ipynb。
+func FileName(p *course.Package) string {
+	return strings.ReplaceAll(p.Dir, "/", "_") + ".ipynb"
+}
+
+// labels notebook 中固定文字的译文，键是中文。
+var labels = map[string]map[string]string{
+	"en": {
+		"代码单元格需要 Go 内核（[gophernotes](https://github.com/gopherdata/gophernotes)）运行，请从上到下依次运行。": "Code cells need a Go kernel ([gophernotes](https://github.com/gopherdata/gophernotes)); run them from top to bottom.",
+		"（练习）": " (exercise)",
+		"这个示例会一直阻塞或让进程崩溃（%s），会卡住内核，所以只列出代码，请用 `study run %s %s` 运行：": "This lesson blocks forever or crashes the process (%s), which would hang the kernel, so it is only listed here. Run it with `study run %s %s`:",
+		"包目录中的其他图片": "Other images in the package",
+	},
+}
+
+// exporter 导出一个课程包时的状态。
+type exporter struct {
+	root  string
+	p     *course.Package
+	texts *i18n.Texts
+	cells []*Cell
+	used  map[string]bool // 已经作为附件的图片
+	note  bool            // 最后一个单元格是说明注释
+}
+
+func (e *exporter) label(zh string, args ...interface{}) string {
+	if s, ok := labels[e.texts.Lang][zh]; ok {
+		zh = s
+	}
+	return fmt.Sprintf(zh, args...)
+}
+
+// add 添加一个单元格。
+func (e *exporter) add(typ, source string, attachments map[string]map[string]string) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return
+	}
+	if typ == Code {
+		source = realTime(source)
+	}
+	e.cells = append(e.cells, &Cell{Type: typ, ID: "cell-" + strconv.Itoa(len(e.cells)+1), Source: source, Attachments: attachments})
+	e.note = false
+}
+
+// addNote 添加说明注释或示例标题，相邻的合并成一个 Markdown 单元格。
+func (e *exporter) addNote(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if e.note {
+		last := e.cells[len(e.cells)-1]
+		last.Source += "\n\n" + text
+		return
+	}
+	e.add(Markdown, text, nil)
+	e.note = true
+}
+
+// Export 把课程包 p 导出为 notebook，标题和说明注释按 t 的语言显示。
+func Export(c *course.Course, p *course.Package, t *i18n.Texts) (*Notebook, error) {
+	e := &exporter{root: c.Root, p: p, texts: t, used: map[string]bool{}}
+
+	title := "# " + p.Dir
+	for _, ch := range c.Chapters {
+		for _, cp := range ch.Packages {
+			if cp == p && ch.Title != "" {
+				title += "\n\n" + ch.Name + " " + t.ChapterTitle(ch)
+			}
+		}
+	}
+	e.add(Markdown, title+"\n\n"+e.label("代码单元格需要 Go 内核（[gophernotes](https://github.com/gopherdata/gophernotes)）运行，请从上到下依次运行。"), nil)
+
+	files, err := e.parse()
+	if err != nil {
+		return nil, err
+	}
+	e.add(Code, imports(files), nil)
+
+	dir := filepath.Join(c.Root, filepath.FromSlash(p.Dir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, ent := range entries {
+		if !ent.IsDir() && strings.EqualFold(path.Ext(ent.Name()), ".md") {
+			data, err := os.ReadFile(filepath.Join(dir, ent.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, section := range sections(string(data)) {
+				e.markdown(section)
+			}
+		}
+	}
+
+	notes, err := t.Notes(c.Root, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		e.file(f, notes)
+	}
+
+	var rest []string
+	for _, ent := range entries {
+		if !ent.IsDir() && isImage(ent.Name()) && !e.used[ent.Name()] {
+			rest = append(rest, fmt.Sprintf("![%s](%s)", ent.Name(), ent.Name()))
+		}
+	}
+	if len(rest) > 0 {
+		e.markdown("### " + e.label("包目录中的其他图片") + "\n\n" + strings.Join(rest, "\n"))
+	}
+
+	return &Notebook{
+		Cells: e.cells,
+		Metadata: Metadata{
+			Kernel:   Kernel{DisplayName: "Go", Language: "go", Name: "gophernotes"},
+			Language: Language{Name: "go", FileExtension: ".go", Mimetype: "text/x-go"},
+		},
+		Format: 4,
+		Minor:  5,
+	}, nil
+}
+
+// source 一个解析过的源文件。
+type source struct {
+	name    string
+	fset    *token.FileSet
+	f       *ast.File
+	data    []byte
+	lessons map[string]*course.Lesson
+}
+
+func (s *source) text(from, to token.Pos) string {
+	return string(s.data[s.fset.Position(from).Offset:s.fset.Position(to).Offset])
+}
+
+func (s *source) line(pos token.Pos) int {
+	return s.fset.Position(pos).Line
+}
+
+// parse 解析包中的源文件。包中有示例时，跳过没有示例的文件，例如只登记示例的 lessons.go。
+func (e *exporter) parse() ([]*source, error) {
+	var files []*source
+	for _, name := range e.p.Files {
+		s := &source{name: name, fset: token.NewFileSet(), lessons: map[string]*course.Lesson{}}
+		for _, l := range e.p.Lessons {
+			if l.File == name {
+				s.lessons[l.Name] = l
+			}
+		}
+		if len(s.lessons) == 0 && len(e.p.Lessons) > 0 {
+			continue
+		}
+		var err error
+		if s.data, err = os.ReadFile(filepath.Join(e.root, filepath.FromSlash(name))); err != nil {
+			return nil, err
+		}
+		if s.f, err = parser.ParseFile(s.fset, name, s.data, parser.ParseComments); err != nil {
+			return nil, err
+		}
+		files = append(files, s)
+	}
+	return files, nil
+}
+
+// imports 返回导入各文件用到的包的代码，测试和课程工具的包在 notebook 中用不到。
+func imports(files []*source) string {
+	seen := map[string]bool{}
+	var specs []string
+	for _, s := range files {
+		for _, spec := range s.f.Imports {
+			p, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || p == "testing" || strings.HasPrefix(p, "study/") {
+				continue
+			}
+			text := spec.Path.Value
+			if spec.Name != nil {
+				text = spec.Name.Name + " " + text
+			}
+			if !seen[text] {
+				seen[text] = true
+				specs = append(specs, text)
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return ""
+	}
+	sort.Strings(specs)
+	return "import (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+}
+
+// item 源文件中按行排列的一段内容。
+type item struct {
+	line, order int
+	add         func()
+}
+
+// file 按源码的顺序添加说明注释、声明和示例。
+func (e *exporter) file(s *source, notes []*course.Note) {
+	var items []item
+	docs := map[int]bool{} // 声明自己的注释，跟声明一起放进代码单元格
+	prevEnd := 0
+	for _, d := range s.f.Decls {
+		d := d
+		end := d.End() // 包括写在结尾同一行的注释
+		for _, cg := range s.f.Comments {
+			if cg.Pos() >= end && s.line(cg.Pos()) == s.line(d.End()) {
+				end = cg.End()
+			}
+		}
+		if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			prevEnd = s.line(end)
+			continue
+		}
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && s.lessons[fn.Name.Name] != nil {
+			l := s.lessons[fn.Name.Name]
+			items = append(items, item{prevEnd + 1, 0, func() { e.heading(l) }})
+			items = append(items, item{s.line(d.Pos()), 2, func() { e.lesson(s, fn, l) }})
+			prevEnd = s.line(end)
+			continue
+		}
+		start := d.Pos()
+		if doc := declDoc(d); doc != nil {
+			start = doc.Pos()
+			docs[s.line(doc.Pos())] = true
+		}
+		code := s.text(start, end)
+		items = append(items, item{s.line(d.Pos()), 2, func() { e.add(Code, code, nil) }})
+		prevEnd = s.line(end)
+	}
+	for _, n := range notes {
+		if n.File == s.name && !docs[n.Line] {
+			text := n.Text
+			items = append(items, item{n.Line, 1, func() { e.addNote(noteMarkdown(text)) }})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.order < b.order
+	})
+	for _, it := range items {
+		it.add()
+	}
+}
+
+func declDoc(d ast.Decl) *ast.CommentGroup {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// heading 添加示例的标题，放在示例上方的说明注释之前。
+func (e *exporter) heading(l *course.Lesson) {
+	title := "### " + l.Name + " " + e.texts.Title(l)
+	if l.Meta != nil && l.Meta.Exercise {
+		title += e.label("（练习）")
+	}
+	e.addNote(title)
+}
+
+// lesson 添加示例的代码单元格。会阻塞或让进程崩溃的示例只在 Markdown 中列出代码。
+func (e *exporter) lesson(s *source, fn *ast.FuncDecl, l *course.Lesson) {
+	code := Statements(s.fset, s.data, fn)
+	if m := l.Meta; m != nil && (m.Expect == lesson.Blocks || m.Expect == lesson.Fatal) {
+		e.addNote(e.label("这个示例会一直阻塞或让进程崩溃（%s），会卡住内核，所以只列出代码，请用 `study run %s %s` 运行：", m.Expect, e.p.Dir, l.Name) +
+			"\n\n```go\n" + code + "\n```")
+		return
+	}
+	e.add(Code, code, nil)
+}
+
+// Statements 把示例函数的函数体改写成可以直接在内核中执行的顶层语句：
+// 去掉一层缩进；demo.Run(t, func() { ... }, ...) 只保留传入的函数体；
+// 函数体直接使用 return 或 defer 时，包进一个立即调用的函数字面量。
+func Statements(fset *token.FileSet, data []byte, fn *ast.FuncDecl) string {
+	body := fn.Body
+	depth := 1
+	if lit := demoRun(body); lit != nil {
+		body, depth = lit.Body, 2
+	}
+	text := string(data[fset.Position(body.Lbrace).Offset+1 : fset.Position(body.Rbrace).Offset])
+	lines := strings.Split(strings.Trim(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = trimTabs(line, depth)
+	}
+	code := strings.TrimSpace(strings.Join(lines, "\n"))
+	if needsFunc(body) {
+		code = "func() {\n\t" + strings.ReplaceAll(code, "\n", "\n\t") + "\n}()"
+		code = strings.ReplaceAll(code, "\t\n", "\n")
+	}
+	return code
+}
+
+// demoRun 函数体只有一句 demo.Run(t, func() { ... }, ...) 时，返回其中的函数字面量。
+func demoRun(body *ast.BlockStmt) *ast.FuncLit {
+	if len(body.List) != 1 {
+		return nil
+	}
+	es, ok := body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return nil
+	}
+	call, ok := es.X.(*ast.CallExpr)
+	if !ok || len(call.Args) < 2 {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" {
+		return nil
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "demo" {
+		return nil
+	}
+	lit, _ := call.Args[1].(*ast.FuncLit)
+	return lit
+}
+
+// needsFunc 判断函数体是否直接（不在函数字面量中）使用 return 或 defer。
+func needsFunc(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt, *ast.DeferStmt:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// realTime 把 clock.Sleep 等调用改成 time 包中的同名函数。notebook 中没有课程的
+// study/internal/clock 包，两个包的这些函数用法相同，内核中本来就是真实的时间。
+func realTime(code string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(code))
+	var s scanner.Scanner
+	s.Init(file, []byte(code), func(token.Position, string) {}, 0)
+	var b strings.Builder
+	last, ident := 0, -1 // ident 是上一个记号 clock 的位置
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.PERIOD && ident >= 0 {
+			b.WriteString(code[last:ident] + "time")
+			last = ident + len("clock")
+		}
+		ident = -1
+		if tok == token.IDENT && lit == "clock" {
+			ident = file.Offset(pos)
+		}
+	}
+	return b.String() + code[last:]
+}
+
+// trimTabs 去掉行首至多 n 个制表符。
+func trimTabs(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, "\t"); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+var (
+	heading = regexp.MustCompile(`^#{1,6} `)
+	image   = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+)
+
+// sections 按标题把 Markdown 切成几段，代码块中的 # 不算标题。
+func sections(text string) []string {
+	var (
+		out   []string
+		cur   []string
+		fence bool
+	)
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fence = !fence
+		}
+		if !fence && heading.MatchString(line) && len(cur) > 0 {
+			out = append(out, strings.Join(cur, "\n"))
+			cur = nil
+		}
+		cur = append(cur, line)
+	}
+	return append(out, strings.Join(cur, "\n"))
+}
+
+// markdown 添加一个 Markdown 单元格，包目录中的图片作为附件内嵌。
+func (e *exporter) markdown(text string) {
+	attachments := map[string]map[string]string{}
+	dir := filepath.Join(e.root, filepath.FromSlash(e.p.Dir))
+	text = image.ReplaceAllStringFunc(text, func(m string) string {
+		sub := image.FindStringSubmatch(m)
+		src := path.Clean(sub[2])
+		if strings.Contains(src, "://") || !isImage(src) {
+			return m
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(src)))
+		if err != nil {
+			return m
+		}
+		name := path.Base(src)
+		attachments[name] = map[string]string{mimeType(name): base64.StdEncoding.EncodeToString(data)}
+		e.used[src] = true
+		return "![" + sub[1] + "](attachment:" + name + ")"
+	})
+	e.add(Markdown, text, attachments)
+}
+
+// noteMarkdown 把说明注释转换成 Markdown：缩进的行放进代码块，其他行保留换行，
+// 转义会被当成强调、链接或 HTML 的字符。大部分行都缩进的注释（例如对齐的列表）整个放进代码块。
+func noteMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	nonBlank, indented := 0, 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonBlank++
+			if isIndented(line) {
+				indented++
+			}
+		}
+	}
+	if indented*2 > nonBlank {
+		return "```text\n" + text + "\n```"
+	}
+
+	var b strings.Builder
+	fence := false
+	for _, line := range lines {
+		blank := strings.TrimSpace(line) == ""
+		indented := !blank && isIndented(line)
+		if !blank && indented != fence {
+			b.WriteString("```\n")
+			fence = indented
+		}
+		switch {
+		case fence:
+			b.WriteString(line + "\n")
+		case blank:
+			b.WriteString("\n")
+		default:
+			b.WriteString(escape(line) + "  \n")
+		}
+	}
+	if fence {
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
+
+// isIndented 判断行首是否有制表符或者至少四个空格。
+func isIndented(line string) bool {
+	ws := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	return strings.Contains(ws, "\t") || len(ws) >= 4
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;")
+
+func escape(line string) string {
+	line = escaper.Replace(line)
+	if strings.HasPrefix(line, "#") {
+		line = `\` + line
+	}
+	return line
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg":
+		return true
+	}
+	return false
+}
+
+func mimeType(name string) string {
+	typ := mime.TypeByExtension(strings.ToLower(path.Ext(name)))
+	if i := strings.Index(typ, ";"); i >= 0 {
+		typ = typ[:i]
+	}
+	return typ
+}
@@ -0,0 +1,223 @@
+package notebook
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+	"study/internal/i18n"
+	_ "study/internal/lesson/all"
+)
+
+// TestExport 导出每个课程包，按 nbformat 4.5 的结构检查生成的 JSON。
+func TestExport(t *testing.T) {
+	c := coursetest.Load(t)
+	texts, err := i18n.Load(c.Root, i18n.Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			nb, err := Export(c, p, texts)
+			if err != nil {
+				t.Fatalf("%s: %v", p.Dir, err)
+			}
+			data, err := nb.Encode()
+			if err != nil {
+				t.Fatalf("%s: %v", p.Dir, err)
+			}
+			validate(t, p.Dir, data)
+		}
+	}
+}
+
+var (
+	cellID        = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+	attachmentRef = regexp.MustCompile(`\(attachment:([^)\s]+)\)`)
+)
+
+func validate(t *testing.T, name string, data []byte) {
+	t.Helper()
+	var nb map[string]interface{}
+	if err := json.Unmarshal(data, &nb); err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	if nb["nbformat"] != 4.0 || nb["nbformat_minor"] != 5.0 {
+		t.Errorf("%s: nbformat %v.%v, want 4.5", name, nb["nbformat"], nb["nbformat_minor"])
+	}
+	meta, _ := nb["metadata"].(map[string]interface{})
+	kernel, _ := meta["kernelspec"].(map[string]interface{})
+	for _, key := range []string{"name", "display_name", "language"} {
+		if s, _ := kernel[key].(string); s == "" {
+			t.Errorf("%s: metadata.kernelspec.%s is missing", name, key)
+		}
+	}
+	if lang, _ := meta["language_info"].(map[string]interface{}); lang["name"] != "go" {
+		t.Errorf("%s: metadata.language_info = %v", name, meta["language_info"])
+	}
+
+	cells, ok := nb["cells"].([]interface{})
+	if !ok || len(cells) == 0 {
+		t.Errorf("%s: no cells", name)
+		return
+	}
+	ids := map[string]bool{}
+	for i, v := range cells {
+		cell, _ := v.(map[string]interface{})
+		where := func(format string, args ...interface{}) {
+			t.Helper()
+			t.Errorf("%s: cell %d: "+format, append([]interface{}{name, i}, args...)...)
+		}
+		id, _ := cell["id"].(string)
+		if !cellID.MatchString(id) || ids[id] {
+			where("bad or duplicate id %q", id)
+		}
+		ids[id] = true
+		if _, ok := cell["metadata"].(map[string]interface{}); !ok {
+			where("metadata is not an object")
+		}
+		lines, ok := cell["source"].([]interface{})
+		if !ok || len(lines) == 0 {
+			where("empty source")
+			continue
+		}
+		var src strings.Builder
+		for j, line := range lines {
+			s, ok := line.(string)
+			if !ok || (j < len(lines)-1) != strings.HasSuffix(s, "\n") {
+				where("source line %d = %q, only the last line has no newline", j, line)
+			}
+			src.WriteString(s)
+		}
+
+		switch cell["cell_type"] {
+		case "code":
+			if v, ok := cell["execution_count"]; !ok || v != nil {
+				where("execution_count = %v, want null", v)
+			}
+			if outputs, ok := cell["outputs"].([]interface{}); !ok || len(outputs) != 0 {
+				where("outputs = %v, want []", cell["outputs"])
+			}
+			if _, ok := cell["attachments"]; ok {
+				where("code cell has attachments")
+			}
+			if err := parseCell(src.String()); err != nil {
+				where("%v\n%s", err, src.String())
+			}
+		case "markdown":
+			if _, ok := cell["outputs"]; ok {
+				where("markdown cell has outputs")
+			}
+			attachments, _ := cell["attachments"].(map[string]interface{})
+			refs := map[string]bool{}
+			for _, m := range attachmentRef.FindAllStringSubmatch(src.String(), -1) {
+				refs[m[1]] = true
+				if attachments[m[1]] == nil {
+					where("no attachment %s", m[1])
+				}
+			}
+			for file, v := range attachments {
+				if !refs[file] {
+					where("attachment %s is not used", file)
+				}
+				bundle, _ := v.(map[string]interface{})
+				for typ, data := range bundle {
+					s, _ := data.(string)
+					if _, err := base64.StdEncoding.DecodeString(s); !strings.HasPrefix(typ, "image/") || s == "" || err != nil {
+						where("attachment %s: %s %v", file, typ, err)
+					}
+				}
+				if len(bundle) != 1 {
+					where("attachment %s has %d types", file, len(bundle))
+				}
+			}
+		default:
+			where("cell_type %v", cell["cell_type"])
+		}
+	}
+}
+
+// parseCell 检查代码单元格是声明或者顶层语句。
+func parseCell(src string) error {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "", "package p\n"+src, 0); err == nil {
+		return nil
+	}
+	_, err := parser.ParseFile(fset, "", "package p\nfunc _() {\n"+src+"\n}", 0)
+	return err
+}
+
+func TestMap(t *testing.T) {
+	c := coursetest.Load(t)
+	texts, err := i18n.Load(c.Root, i18n.Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb, err := Export(c, c.Package("c4/2.map"), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachments := map[string]bool{}
+	var code, markdown []string
+	for _, cell := range nb.Cells {
+		for name := range cell.Attachments {
+			attachments[name] = true
+		}
+		if cell.Type == Code {
+			code = append(code, cell.Source)
+		} else {
+			markdown = append(markdown, cell.Source)
+		}
+	}
+	for _, name := range []string{"map.png", "img.png", "img_1.png", "img_2.png"} {
+		if !attachments[name] {
+			t.Errorf("%s is not attached", name)
+		}
+	}
+	if !strings.Contains(strings.Join(markdown, "\n"), "### 5. 扩容 和 迁移") {
+		t.Error("map.md is missing")
+	}
+	if got := strings.Join(code, "\n"); !strings.Contains(got, "\nscoreMap := make(map[string]int, 8)\n") {
+		t.Errorf("TestM1 is not a top-level statement:\n%s", got)
+	}
+	// TestM3 会触发 fatal error，只列在 Markdown 中
+	if got := strings.Join(code, "\n"); strings.Contains(got, "m[0] = 1") {
+		t.Errorf("TestM3 is in a code cell:\n%s", got)
+	}
+}
+
+func TestStatements(t *testing.T) {
+	for _, tt := range []struct {
+		body, want string
+	}{
+		{"\n\ta := 1\n\tfmt.Println(a)\n", "a := 1\nfmt.Println(a)"},
+		{"\n\tdemo.Run(t, func() {\n\t\tvar a *int\n\t\t*a = 10\n\t}, demo.Panic(\"nil\"))\n", "var a *int\n*a = 10"},
+		{"\n\tif x {\n\t\treturn\n\t}\n\n\tf()\n", "func() {\n\tif x {\n\t\treturn\n\t}\n\n\tf()\n}()"},
+		{"\n\tgo func() {\n\t\tdefer wg.Done()\n\t}()\n", "go func() {\n\tdefer wg.Done()\n}()"},
+	} {
+		src := "package p\n\nfunc TestX(t *testing.T) {" + tt.body + "}\n"
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Statements(fset, []byte(src), f.Decls[0].(*ast.FuncDecl)); got != tt.want {
+			t.Errorf("Statements(%q) =\n%s\nwant\n%s", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestRealTime(t *testing.T) {
+	src := "clock.Sleep(time.Second) // clock.Sleep\nc := clock.NewTimer(d)\nclock := 1\n_ = \"clock.After\""
+	want := "time.Sleep(time.Second) // clock.Sleep\nc := time.NewTimer(d)\nclock := 1\n_ = \"clock.After\""
+	if got := realTime(src); got != want {
+		t.Errorf("realTime =\n%s\nwant\n%s", got, want)
+	}
+}
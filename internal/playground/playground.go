@@ -0,0 +1,333 @@
+// Package playground 是课程的本地网页：浏览章节和示例，查看源码、注释和配图，
+// 修改示例后在沙箱中运行，stdout 和 stderr 实时返回给浏览器。
+package playground
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"study/internal/course"
+	"study/internal/progress"
+	"study/internal/runner"
+	"study/internal/sandbox"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// imageExts 可以通过 /file/ 访问的文件类型。
+var imageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true}
+
+// Server 网页服务。
+//
+// /run 会运行提交的任意代码，所以只接受同源、带着页面中的 token 的请求，
+// Host 也必须是 localhost 或 IP 地址，挡住 DNS 重绑定的页面。
+type Server struct {
+	Course      *course.Course
+	Limits      sandbox.Limits // 运行学员修改后的代码时的限制
+	BuildLimits sandbox.Limits // 编译学员修改后的代码时的限制
+
+	// Progress 不为 nil 时记录学员看过和运行过的示例。
+	Progress *progress.Tracker
+
+	token string // 嵌在示例页面中，/run 要求带上
+	mux   *http.ServeMux
+}
+
+// New 创建 Server，运行代码使用 sandbox.DefaultLimits，墙钟时间 10 秒，
+// 编译使用 runner.BuildLimits。
+func New(c *course.Course) *Server {
+	s := &Server{Course: c, Limits: sandbox.DefaultLimits, BuildLimits: runner.BuildLimits}
+	s.Limits.WallTime = 10 * time.Second
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	s.token = hex.EncodeToString(b)
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/", s.index)
+	s.mux.HandleFunc("/lesson/", s.lesson)
+	s.mux.HandleFunc("/file/", s.file)
+	s.mux.HandleFunc("/run", s.run)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !localHost(r.Host) {
+		http.Error(w, "unknown host "+r.Host, http.StatusForbidden)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// localHost 判断 Host 是不是 localhost 或 IP 地址。DNS 重绑定的页面用的是攻击者的域名。
+func localHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host == "localhost" || net.ParseIP(strings.Trim(host, "[]")) != nil
+}
+
+// sameOrigin 判断请求是不是来自本服务的页面：浏览器跨站提交时 Origin 是别的网站。
+// 没有 Origin 的请求不是浏览器跨站发出的，交给 token 检查。
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	render(w, "index.html", s.Course)
+}
+
+// lessonPage lesson.html 的数据。
+type lessonPage struct {
+	Lesson *course.Lesson
+	Source string
+	Images []string
+	Token  string
+}
+
+// lesson 显示 /lesson/<包目录>/<函数名>。
+func (s *Server) lesson(w http.ResponseWriter, r *http.Request) {
+	l := s.lookup(strings.TrimPrefix(r.URL.Path, "/lesson/"))
+	if l == nil {
+		http.NotFound(w, r)
+		return
+	}
+	src, err := Source(s.Course.Root, l)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.track(func(p *progress.Progress, now time.Time) { p.View(l.Path(), now) })
+	render(w, "lesson.html", &lessonPage{Lesson: l, Source: src, Images: Images(s.Course.Root, l.Package), Token: s.token})
+}
+
+func (s *Server) lookup(id string) *course.Lesson {
+	i := strings.LastIndex(id, "/")
+	if i < 0 {
+		return nil
+	}
+	l, err := s.Course.Lesson(id[:i], id[i+1:])
+	if err != nil {
+		return nil
+	}
+	return l
+}
+
+// track 记录进度。进度只是附带的统计，出错时不影响页面。
+func (s *Server) track(f func(p *progress.Progress, now time.Time)) {
+	if s.Progress != nil {
+		s.Progress.Update(f)
+	}
+}
+
+// file 提供课程目录中的图片。
+func (s *Server) file(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean(strings.TrimPrefix(r.URL.Path, "/file/"))
+	if !imageExts[strings.ToLower(path.Ext(name))] || s.Course.Package(path.Dir(name)) == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(s.Course.Root, filepath.FromSlash(name)))
+}
+
+// event 运行过程中发给浏览器的一行 JSON。
+type event struct {
+	Kind string `json:"kind"` // build、stdout、stderr、exit
+	Data string `json:"data"`
+}
+
+// eventWriter 把输出转成 event 写给浏览器，stdout 和 stderr 在不同的 goroutine 中写，需要加锁。
+type eventWriter struct {
+	mu  *sync.Mutex
+	w   http.ResponseWriter
+	enc *json.Encoder
+}
+
+func (e *eventWriter) send(kind, data string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enc.Encode(event{kind, data})
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type streamWriter struct {
+	kind string
+	e    *eventWriter
+}
+
+func (s streamWriter) Write(p []byte) (int, error) {
+	s.e.send(s.kind, string(p))
+	return len(p), nil
+}
+
+// run 用表单中的 code 替换示例函数的源码，在沙箱中编译、运行，
+// 以 application/x-ndjson 的形式实时返回输出。
+func (s *Server) run(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) || subtle.ConstantTimeCompare([]byte(r.FormValue("token")), []byte(s.token)) != 1 {
+		http.Error(w, "cross-origin request or missing token, reload the lesson page", http.StatusForbidden)
+		return
+	}
+	l := s.lookup(r.FormValue("lesson"))
+	if l == nil {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := Replace(s.Course.Root, l, r.FormValue("code"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.track(func(p *progress.Progress, now time.Time) { p.Run(l.Path(), now) })
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	ev := &eventWriter{mu: new(sync.Mutex), w: w, enc: json.NewEncoder(w)}
+
+	ctx := r.Context()
+	bin, cleanup, err := runner.BuildSandboxed(ctx, s.Course.Root, l.Package.Dir, l.Name == course.MainLesson,
+		map[string][]byte{l.File: file}, s.BuildLimits)
+	if err != nil {
+		ev.send("build", err.Error())
+		ev.send("exit", "build failed")
+		return
+	}
+	defer cleanup()
+
+	res, err := runner.Exec(ctx, filepath.Join(s.Course.Root, l.Package.Dir), bin, runner.Args(l.Name), runner.Options{
+		Sandbox: &s.Limits,
+		Stdout:  streamWriter{"stdout", ev},
+		Stderr:  streamWriter{"stderr", ev},
+	})
+	if err != nil {
+		ev.send("exit", err.Error())
+		return
+	}
+	ev.send("exit", status(res, s.Limits.WallTime))
+}
+
+func status(res *runner.Result, limit time.Duration) string {
+	var b strings.Builder
+	switch {
+	case res.TimedOut:
+		b.WriteString("killed after " + limit.String())
+	case res.Signal != "":
+		b.WriteString("killed: " + res.Signal)
+	case res.ExitCode != 0:
+		b.WriteString("exit status " + strconv.Itoa(res.ExitCode))
+	default:
+		b.WriteString("ok")
+	}
+	b.WriteString(" (" + res.Duration.Round(time.Millisecond).String() + ")")
+	if res.Truncated {
+		b.WriteString(", output truncated")
+	}
+	return b.String()
+}
+
+// Source 返回示例函数的源码，从 func 一行到结尾的 }。
+func Source(root string, l *course.Lesson) (string, error) {
+	lines, err := readLines(root, l)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines[l.Line-1:l.EndLine], "\n"), nil
+}
+
+// Replace 返回示例所在文件的内容，其中示例函数替换为 code。
+func Replace(root string, l *course.Lesson, code string) ([]byte, error) {
+	lines, err := readLines(root, l)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	for _, line := range lines[:l.Line-1] {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(strings.ReplaceAll(code, "\r\n", "\n") + "\n")
+	for _, line := range lines[l.EndLine:] {
+		b.WriteString(line + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+func readLines(root string, l *course.Lesson) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(l.File)))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
+// Images 返回课程包目录中的图片，相对仓库根目录。
+func Images(root string, p *course.Package) []string {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(p.Dir)))
+	if err != nil {
+		return nil
+	}
+	var images []string
+	for _, e := range entries {
+		if !e.IsDir() && imageExts[strings.ToLower(path.Ext(e.Name()))] {
+			images = append(images, p.Dir+"/"+e.Name())
+		}
+	}
+	return images
+}
+
+func render(w http.ResponseWriter, name string, data interface{}) {
+	var b bytes.Buffer
+	if err := templates.ExecuteTemplate(&b, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+// Serve 在 addr 上启动服务，ctx 结束时关闭。
+func Serve(ctx context.Context, addr string, s *Server) error {
+	srv := &http.Server{Addr: addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	err := srv.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+package playground
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	c := coursetest.Load(t)
+	ts := httptest.NewServer(New(c))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var b strings.Builder
+	s := bufio.NewScanner(resp.Body)
+	for s.Scan() {
+		b.WriteString(s.Text() + "\n")
+	}
+	return resp.StatusCode, b.String()
+}
+
+func TestPages(t *testing.T) {
+	ts := newServer(t)
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{"/", 200, "/lesson/c3/5.slice/Test_S9"},
+		{"/lesson/c3/5.slice/Test_S9", 200, "超出原 slice.cap 限制"},
+		{"/lesson/c4/2.map/TestM1", 200, "/file/c4/2.map/map.png"},
+		{"/lesson/c4/2.map/TestM9", 404, ""},
+		{"/file/c4/2.map/map.png", 200, "PNG"},
+		{"/file/c4/2.map/map_test.go", 404, ""},
+		{"/file/../../etc/passwd.png", 404, ""},
+	}
+	for _, tt := range tests {
+		code, body := get(t, ts.URL+tt.path)
+		if code != tt.code || !strings.Contains(body, tt.want) {
+			t.Errorf("GET %s = %d, want %d containing %q", tt.path, code, tt.code, tt.want)
+		}
+	}
+}
+
+// token 从示例页面中取出运行代码需要的 token。
+func token(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	_, body := get(t, ts.URL+"/lesson/c6/2.channel/TestC2")
+	m := regexp.MustCompile(`name="token" value="([0-9a-f]+)"`).FindStringSubmatch(body)
+	if m == nil {
+		t.Fatal("no token in the lesson page")
+	}
+	return m[1]
+}
+
+// post 提交代码，返回状态码和按 kind 拼接的事件。
+func post(t *testing.T, ts *httptest.Server, form url.Values, header http.Header) (int, map[string]string) {
+	t.Helper()
+	req, err := http.NewRequest("POST", ts.URL+"/run", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if h := header.Get("Host"); h != "" {
+		req.Host = h
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	got := map[string]string{}
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev event
+		if err := dec.Decode(&ev); err != nil {
+			break
+		}
+		got[ev.Kind] += ev.Data
+	}
+	return resp.StatusCode, got
+}
+
+func TestForbidden(t *testing.T) {
+	ts := newServer(t)
+	tok := token(t, ts)
+	form := url.Values{"lesson": {"c6/2.channel/TestC2"}, "code": {"func TestC2(t *testing.T) {}"}, "token": {tok}}
+	noToken := url.Values{"lesson": form["lesson"], "code": form["code"]}
+	tests := []struct {
+		name   string
+		form   url.Values
+		header http.Header
+	}{
+		{"no token", noToken, nil},
+		{"wrong token", url.Values{"lesson": form["lesson"], "code": form["code"], "token": {"x" + tok}}, nil},
+		{"cross origin", form, http.Header{"Origin": {"http://evil.example"}}},
+		{"rebound host", form, http.Header{"Host": {"evil.example"}}},
+	}
+	for _, tt := range tests {
+		if code, got := post(t, ts, tt.form, tt.header); code != http.StatusForbidden {
+			t.Errorf("%s: got %d %q, want 403", tt.name, code, got)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a lesson")
+	}
+	ts := newServer(t)
+	tok := token(t, ts)
+	code := `func TestC2(t *testing.T) {
+	fmt.Println("edited")
+	println("to stderr")
+}`
+	_, got := post(t, ts, url.Values{"lesson": {"c6/2.channel/TestC2"}, "code": {code}, "token": {tok}},
+		http.Header{"Origin": {ts.URL}})
+	if got["stdout"] != "edited\n" || got["stderr"] != "to stderr\n" || !strings.HasPrefix(got["exit"], "ok") {
+		t.Errorf("got events %q", got)
+	}
+}
+
+// TestDemo 用 demo.Run 的示例在子进程中重新运行测试二进制，在沙箱中也要能运行。
+func TestDemo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a lesson")
+	}
+	ts := newServer(t)
+	code := `func TestP5(t *testing.T) {
+	demo.Run(t, func() {
+		var a *int
+		*a = 10
+		fmt.Println(*a)
+	}, demo.Panic("invalid memory address or nil pointer dereference"))
+}`
+	_, got := post(t, ts, url.Values{"lesson": {"c3/3.pointer/TestP5"}, "code": {code}, "token": {token(t, ts)}}, nil)
+	if !strings.Contains(got["stderr"], "nil pointer dereference") || !strings.HasPrefix(got["exit"], "ok") {
+		t.Errorf("got events %q, want the demo to panic as expected", got)
+	}
+}
+
+// TestPrivateDir 代码在沙箱的私有目录中运行：能读到示例用到的 1.txt，写的文件不会落到仓库里。
+func TestPrivateDir(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a lesson")
+	}
+	ts := newServer(t)
+	code := `func TestF10(t *testing.T) {
+	_, err := os.Open("1.txt")
+	fmt.Println(err == nil)
+	fmt.Println(os.WriteFile("written.txt", nil, 0o644) == nil)
+}`
+	_, got := post(t, ts, url.Values{"lesson": {"c4/1.function/TestF10"}, "code": {code}, "token": {token(t, ts)}}, nil)
+	if got["stdout"] != "true\ntrue\n" {
+		t.Errorf("got events %q, want the fixture readable and the directory writable", got)
+	}
+	if _, err := os.Stat("../../c4/1.function/written.txt"); !os.IsNotExist(err) {
+		os.Remove("../../c4/1.function/written.txt")
+		t.Error("the submitted code wrote into the repository")
+	}
+}
@@ -0,0 +1,127 @@
+package pptx
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/lesson"
+)
+
+// Load 读取课程中所有章节的 .pptx 讲义，Deck.Path 是相对仓库根目录的路径。
+func Load(c *course.Course) ([]*Deck, error) {
+	var decks []*Deck
+	for _, ch := range c.Chapters {
+		for _, asset := range ch.Assets {
+			if filepath.Ext(asset) != ".pptx" {
+				continue
+			}
+			d, err := Read(filepath.Join(c.Root, filepath.FromSlash(asset)))
+			if err != nil {
+				return nil, err
+			}
+			d.Path = asset
+			decks = append(decks, d)
+		}
+	}
+	return decks, nil
+}
+
+var word = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_.]*`)
+
+// keywords 返回用来匹配讲义的关键词：第一个标签的别名（见 lesson.Aliases），以及标题中的英文单词。
+func keywords(l *course.Lesson) []string {
+	var kws []string
+	seen := map[string]bool{}
+	add := func(kw string) {
+		if !seen[strings.ToLower(kw)] {
+			seen[strings.ToLower(kw)] = true
+			kws = append(kws, kw)
+		}
+	}
+	// 只用第一个标签：其余的标签是次要的，例如 channel 的示例大多也带 goroutine。
+	if l.Meta != nil && len(l.Meta.Tags) > 0 {
+		for _, kw := range lesson.Aliases[l.Meta.Tags[0]] {
+			add(kw)
+		}
+	}
+	for _, w := range word.FindAllString(l.Title, -1) {
+		if !lesson.StopWords[strings.ToLower(w)] {
+			add(w)
+		}
+	}
+	return kws
+}
+
+// mentions 判断 text 是否提到了 kw：中文按子串匹配，英文按单词匹配。
+func mentions(text, kw string) bool {
+	if !word.MatchString(kw[:1]) {
+		return strings.Contains(text, kw)
+	}
+	for _, w := range word.FindAllString(text, -1) {
+		if strings.EqualFold(w, kw) || strings.EqualFold(w, kw+"s") {
+			return true
+		}
+	}
+	return false
+}
+
+// Link 一页幻灯片和一个示例的对应关系。
+type Link struct {
+	Deck   *Deck
+	Slide  *Slide
+	Lesson *course.Lesson
+	Score  int
+}
+
+// 标题提到示例的关键词记 3 分，正文每提到一个记 1 分，3 分以上才算对应。
+// 标题只看最后一段（小节名），“包、函数、注释 · 1.包” 这样的章节名会出现在很多页上。
+const (
+	titleScore = 3
+	minScore   = 3
+)
+
+// Match 找出讲义中每页幻灯片对应的示例，按讲义、页码排列，同一页中得分高的在前。
+func Match(decks []*Deck, lessons []*course.Lesson) []Link {
+	var links []Link
+	for _, d := range decks {
+		for _, s := range d.Slides {
+			title := s.Title
+			if i := strings.LastIndex(title, " · "); i >= 0 {
+				title = title[i+len(" · "):]
+			}
+			body := s.Text()
+			var found []Link
+			for _, l := range lessons {
+				score := 0
+				for _, kw := range keywords(l) {
+					if mentions(title, kw) {
+						score += titleScore
+					} else if mentions(body, kw) {
+						score++
+					}
+				}
+				if score >= minScore {
+					found = append(found, Link{Deck: d, Slide: s, Lesson: l, Score: score})
+				}
+			}
+			sort.SliceStable(found, func(i, j int) bool { return found[i].Score > found[j].Score })
+			links = append(links, found...)
+		}
+	}
+	return links
+}
+
+// For 返回 l 对应的幻灯片，得分高的在前。
+func For(links []Link, l *course.Lesson) []Link {
+	var out []Link
+	for _, k := range links {
+		if k.Lesson == l {
+			out = append(out, k)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
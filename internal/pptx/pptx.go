@@ -0,0 +1,417 @@
+// Package pptx 读取章节目录中的 .pptx 讲义：每页的标题、要点、代码块和图片，
+// 并把幻灯片和讲同一内容的示例对应起来。
+//
+// .pptx 是 zip 压缩的 XML。幻灯片的顺序记录在 ppt/presentation.xml 中，
+// 每页的内容在 ppt/slides/slideN.xml，图片等引用在对应的 _rels 文件里。
+// 讲义大多没有使用标题占位符，标题按字号判断；代码也没有使用等宽字体，按内容判断。
+package pptx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	nsDrawing = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsPresent = "http://schemas.openxmlformats.org/presentationml/2006/main"
+	nsRel     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// Deck 一份讲义。
+type Deck struct {
+	Path   string   `json:"path"`
+	Slides []*Slide `json:"slides"`
+}
+
+// Slide 一页幻灯片。
+type Slide struct {
+	Number int      `json:"number"` // 从 1 开始，与放映顺序一致
+	Title  string   `json:"title"`
+	Body   []Block  `json:"body,omitempty"`   // 按页面上从上到下的顺序
+	Images []string `json:"images,omitempty"` // 压缩包内的路径，例如 ppt/media/image1.jpeg
+}
+
+// Block 一个要点或一段代码。
+type Block struct {
+	Level int    `json:"level,omitempty"`
+	Text  string `json:"text"` // 代码块有多行
+	Code  bool   `json:"code,omitempty"`
+}
+
+// Code 返回幻灯片上的代码块。
+func (s *Slide) Code() []string {
+	var code []string
+	for _, b := range s.Body {
+		if b.Code {
+			code = append(code, b.Text)
+		}
+	}
+	return code
+}
+
+// Text 返回幻灯片正文（不含标题）的全部文字。
+func (s *Slide) Text() string {
+	var parts []string
+	for _, b := range s.Body {
+		parts = append(parts, b.Text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Read 读取 .pptx 文件。
+func Read(name string) (*Deck, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	d, err := read(&zr.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("pptx: %s: %v", name, err)
+	}
+	d.Path = name
+	return d, nil
+}
+
+func read(zr *zip.Reader) (*Deck, error) {
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	open := func(name string) (io.ReadCloser, error) {
+		f := files[name]
+		if f == nil {
+			return nil, fmt.Errorf("missing %s", name)
+		}
+		return f.Open()
+	}
+
+	order, err := slideOrder(open)
+	if err != nil {
+		return nil, err
+	}
+	d := &Deck{}
+	for i, name := range order {
+		rels, err := readRels(open, relsPath(name))
+		if err != nil {
+			return nil, err
+		}
+		rc, err := open(name)
+		if err != nil {
+			return nil, err
+		}
+		s, err := parseSlide(rc, rels, path.Dir(name))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		s.Number = i + 1
+		d.Slides = append(d.Slides, s)
+	}
+	return d, nil
+}
+
+// slideOrder 按 presentation.xml 中 sldIdLst 的顺序返回幻灯片文件名。
+// 文件名中的数字不一定是放映顺序。
+func slideOrder(open func(string) (io.ReadCloser, error)) ([]string, error) {
+	rels, err := readRels(open, "ppt/_rels/presentation.xml.rels")
+	if err != nil {
+		return nil, err
+	}
+	rc, err := open("ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var names []string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Space == nsPresent && se.Name.Local == "sldId" {
+			if target := rels[attr(se, nsRel, "id")]; target != "" {
+				names = append(names, path.Join("ppt", target))
+			}
+		}
+	}
+}
+
+func relsPath(name string) string {
+	return path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")
+}
+
+// readRels 读取关系文件，返回 Id → Target。文件不存在时返回空表。
+func readRels(open func(string) (io.ReadCloser, error), name string) (map[string]string, error) {
+	rels := map[string]string{}
+	rc, err := open(name)
+	if err != nil {
+		return rels, nil
+	}
+	defer rc.Close()
+	var doc struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&doc); err != nil {
+		return nil, err
+	}
+	for _, r := range doc.Rels {
+		rels[r.ID] = r.Target
+	}
+	return rels, nil
+}
+
+func attr(se xml.StartElement, space, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local && (space == "" || a.Name.Space == space) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// shape 一个文本框、占位符或表格。
+type shape struct {
+	x, y  int64
+	title bool // 标题占位符
+	size  int  // 最大字号，单位 1/100 磅
+	paras []para
+}
+
+type para struct {
+	level int
+	lines []string // 段内用 <a:br/> 换行
+}
+
+// parseSlide 解析一页幻灯片。
+func parseSlide(r io.Reader, rels map[string]string, dir string) (*Slide, error) {
+	s := &Slide{}
+	var shapes []*shape
+	var cur *shape
+	var p *para
+	depth := 0 // cur 所在的元素深度，嵌套的 sp 归到最外层
+	inText := false
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == nsPresent && (t.Name.Local == "sp" || t.Name.Local == "graphicFrame"):
+				if cur == nil {
+					cur = &shape{x: -1, y: -1}
+					shapes = append(shapes, cur)
+				}
+				depth++
+			case cur == nil:
+				if t.Name.Space == nsDrawing && t.Name.Local == "blip" {
+					if target := rels[attr(t, nsRel, "embed")]; target != "" {
+						s.Images = append(s.Images, path.Join(dir, target))
+					}
+				}
+			case t.Name.Space == nsPresent && t.Name.Local == "ph":
+				switch attr(t, "", "type") {
+				case "title", "ctrTitle":
+					cur.title = true
+				}
+			case t.Name.Space == nsDrawing && t.Name.Local == "off" && cur.y < 0:
+				cur.x, _ = strconv.ParseInt(attr(t, "", "x"), 10, 64)
+				cur.y, _ = strconv.ParseInt(attr(t, "", "y"), 10, 64)
+			case t.Name.Space == nsDrawing && t.Name.Local == "p":
+				cur.paras = append(cur.paras, para{lines: []string{""}})
+				p = &cur.paras[len(cur.paras)-1]
+			case t.Name.Space == nsDrawing && t.Name.Local == "pPr" && p != nil:
+				p.level, _ = strconv.Atoi(attr(t, "", "lvl"))
+			case t.Name.Space == nsDrawing && t.Name.Local == "br" && p != nil:
+				p.lines = append(p.lines, "")
+			case t.Name.Space == nsDrawing && t.Name.Local == "rPr":
+				if sz, _ := strconv.Atoi(attr(t, "", "sz")); sz > cur.size {
+					cur.size = sz
+				}
+			case t.Name.Space == nsDrawing && t.Name.Local == "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch {
+			case t.Name.Space == nsPresent && (t.Name.Local == "sp" || t.Name.Local == "graphicFrame"):
+				depth--
+				if depth == 0 {
+					cur = nil
+				}
+			case t.Name.Space == nsDrawing && t.Name.Local == "p":
+				p = nil
+			case t.Name.Space == nsDrawing && t.Name.Local == "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText && p != nil {
+				p.lines[len(p.lines)-1] += strings.ReplaceAll(string(t), "\u00a0", " ")
+			}
+		}
+	}
+
+	sort.SliceStable(shapes, func(i, j int) bool {
+		if shapes[i].y != shapes[j].y {
+			return shapes[i].y < shapes[j].y
+		}
+		return shapes[i].x < shapes[j].x
+	})
+	// 有标题占位符时只用占位符作标题，否则字号大的单行短文本都算标题（章节名 · 小节名）。
+	placeholder := false
+	for _, sh := range shapes {
+		placeholder = placeholder || sh.title
+	}
+	var titles []string
+	for _, sh := range shapes {
+		if text, ok := heading(sh); ok && (sh.title || !placeholder) {
+			titles = append(titles, text)
+			continue
+		}
+		s.addText(sh)
+	}
+	s.Title = strings.Join(titles, " · ")
+	return s, nil
+}
+
+// titleSize 字号不小于 28 磅的单行短文本当作标题。
+const titleSize = 2800
+
+// heading 判断文本框是不是标题，是的话返回标题文字。
+func heading(sh *shape) (string, bool) {
+	var text []string
+	for _, p := range sh.paras {
+		for _, line := range p.lines {
+			if line = strings.TrimSpace(line); line != "" {
+				text = append(text, line)
+			}
+		}
+	}
+	if len(text) != 1 {
+		return "", false
+	}
+	ok := sh.title || sh.size >= titleSize && utf8.RuneCountInString(text[0]) <= 30
+	return text[0], ok
+}
+
+// addText 把文本框的内容分成要点和代码块：连续两行以上像代码的行是代码块，
+// 以 { 结尾的行直到配对的 } 之间都算代码（讲义里的伪代码也是这样写的）。
+func (s *Slide) addText(sh *shape) {
+	type line struct {
+		level int
+		text  string
+	}
+	var lines []line
+	for _, p := range sh.paras {
+		for _, l := range p.lines {
+			lines = append(lines, line{p.level, strings.TrimRight(l, " \t")})
+		}
+	}
+	for i := 0; i < len(lines); {
+		j, depth := i, 0
+		for j < len(lines) && (depth > 0 || looksLikeCode(lines[j].text)) {
+			depth += strings.Count(lines[j].text, "{") - strings.Count(lines[j].text, "}")
+			j++
+		}
+		if j-i >= 2 {
+			var code []string
+			for _, l := range lines[i:j] {
+				code = append(code, l.text)
+			}
+			s.Body = append(s.Body, Block{Text: strings.Join(trimIndent(code), "\n"), Code: true})
+			i = j
+			continue
+		}
+		if j == i {
+			j++
+		}
+		for _, l := range lines[i:j] {
+			if text := strings.TrimSpace(l.text); text != "" {
+				s.Body = append(s.Body, Block{Level: l.level, Text: text})
+			}
+		}
+		i = j
+	}
+}
+
+var codeKeywords = map[string]bool{
+	"package": true, "import": true, "func": true, "type": true, "var": true, "const": true,
+	"return": true, "defer": true, "go": true, "select": true, "case": true, "switch": true,
+	"for": true, "if": true, "else": true,
+}
+
+// looksLikeCode 判断一行文字像不像 Go 代码。带中文标点的是说明文字。
+func looksLikeCode(line string) bool {
+	t := strings.TrimSpace(line)
+	switch {
+	case t == "", strings.ContainsAny(t, "，。；："):
+		return false
+	case t == "…", t == "...", strings.HasPrefix(t, "//"),
+		strings.HasSuffix(t, "{"), strings.HasPrefix(t, "}"), t == ")",
+		strings.Contains(t, ":="), len(t) > 1 && t[0] == '"' && t[len(t)-1] == '"':
+		return true
+	}
+	word := t
+	if i := strings.IndexAny(t, " (\t"); i >= 0 {
+		word = t[:i]
+	}
+	if codeKeywords[word] && len(t) > len(word) {
+		return true
+	}
+	// fmt.Println(...) 这样的调用
+	if i := strings.Index(t, "("); i > 0 && strings.HasSuffix(t, ")") {
+		for _, r := range t[:i] {
+			if !(r == '.' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// trimIndent 去掉各行共同的前导空白。
+func trimIndent(lines []string) []string {
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimPrefix(line, prefix)
+	}
+	return out
+}
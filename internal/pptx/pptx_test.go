@@ -0,0 +1,100 @@
+package pptx
+
+import (
+	"strings"
+	"testing"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+func load(t *testing.T) (*course.Course, []*Deck) {
+	t.Helper()
+	c := coursetest.Load(t)
+	decks, err := Load(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, decks
+}
+
+func deck(t *testing.T, decks []*Deck, chapter string) *Deck {
+	t.Helper()
+	for _, d := range decks {
+		if strings.HasPrefix(d.Path, chapter+"/") {
+			return d
+		}
+	}
+	t.Fatalf("no slides in %s", chapter)
+	return nil
+}
+
+func TestRead(t *testing.T) {
+	_, decks := load(t)
+	for chapter, n := range map[string]int{"c1": 18, "c2": 33, "c6": 7} {
+		if d := deck(t, decks, chapter); len(d.Slides) != n {
+			t.Errorf("%s: got %d slides, want %d", d.Path, len(d.Slides), n)
+		}
+	}
+
+	s := deck(t, decks, "c2").Slides[12]
+	if s.Number != 13 || s.Title != "基本类型 · 3.复数" {
+		t.Fatalf("slide %d title = %q", s.Number, s.Title)
+	}
+	if code := s.Code(); len(code) != 1 || !strings.HasPrefix(code[0], "var c1 complex64 = 5 + 10i\n") {
+		t.Errorf("slide 13 code = %q", code)
+	}
+
+	// 以 { 开头的伪代码直到 } 都是代码块
+	s = deck(t, decks, "c2").Slides[4]
+	found := false
+	for _, code := range s.Code() {
+		found = found || code == "type writer interface{\n    Write([]byte) error\n}"
+	}
+	if !found {
+		t.Errorf("slide 5 code = %q", s.Code())
+	}
+}
+
+func TestMatch(t *testing.T) {
+	c, decks := load(t)
+	links := Match(decks, c.Lessons())
+
+	g1, err := c.Lesson("c6/1.goroutine", "TestG1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := For(links, g1)
+	if len(refs) == 0 || refs[0].Slide.Title != "协程" || refs[0].Slide.Number != 7 {
+		t.Fatalf("TestG1 slides = %v", refs)
+	}
+
+	// 章节名 “包、函数、注释” 不能让 1.包 这一页也对应到函数的示例
+	for _, k := range links {
+		if strings.HasSuffix(k.Slide.Title, "1.包") {
+			t.Errorf("%s slide %d linked to %s", k.Deck.Path, k.Slide.Number, k.Lesson.Path())
+		}
+	}
+}
+
+func TestLooksLikeCode(t *testing.T) {
+	for line, want := range map[string]bool{
+		`fmt.Println("hello")`: true,
+		"x := 1":               true,
+		"func main() {":        true,
+		"}":                    true,
+		"return a, b":          true,
+		`"fmt"`:                true,
+		"// 注释":                true,
+		"var 变量名 类型":           true,
+		"Complex64  32 位实数和虚数": false,
+		"避免循环引用。":              false,
+		"如果要调用 fmt.Println()，需要先导入 fmt": false,
+		"": false,
+	} {
+		if got := looksLikeCode(line); got != want {
+			t.Errorf("looksLikeCode(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Package progress 记录每个学员的学习进度：看过哪些示例、运行过几次、
+// 练习提交了几次、是否通过，以及对应的时间。每个学员一个 JSON 文件。
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record 一个学员在一个示例上的进度。
+type Record struct {
+	Views     int        `json:"views,omitempty"`
+	Runs      int        `json:"runs,omitempty"`
+	Attempts  int        `json:"attempts,omitempty"` // 练习的评分次数
+	Passed    bool       `json:"passed,omitempty"`   // 练习拿过满分
+	BestScore int        `json:"best_score,omitempty"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	PassedAt  *time.Time `json:"passed_at,omitempty"` // 第一次拿满分的时间
+}
+
+// Progress 一个学员的进度文件。
+type Progress struct {
+	Learner string             `json:"learner"`
+	Lessons map[string]*Record `json:"lessons"` // 键是示例 ID，例如 c3/5.slice/Test_S9
+}
+
+// Dir 返回进度文件所在目录：$STUDY_HOME/progress，默认 ~/.study/progress。
+func Dir() (string, error) {
+	home := os.Getenv("STUDY_HOME")
+	if home == "" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		home = filepath.Join(h, ".study")
+	}
+	return filepath.Join(home, "progress"), nil
+}
+
+// Learner 返回当前学员的名字：$STUDY_LEARNER，默认当前系统用户名（去掉 Windows 的域名）。
+// 名字用作文件名，不能用的名字（见 CheckLearner）返回错误。
+func Learner() (string, error) {
+	if name := os.Getenv("STUDY_LEARNER"); name != "" {
+		if err := CheckLearner(name); err != nil {
+			return "", fmt.Errorf("%v, set STUDY_LEARNER to another name", err)
+		}
+		return name, nil
+	}
+	if u, err := user.Current(); err == nil {
+		name := u.Username
+		if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+			name = name[i+1:]
+		}
+		if CheckLearner(name) == nil {
+			return name, nil
+		}
+	}
+	return "learner", nil
+}
+
+// CheckLearner 检查学员的名字能不能用作进度目录中的文件名：不能为空，
+// 不能包含路径分隔符，也不能包含 ..，否则进度文件会写到进度目录以外。
+func CheckLearner(name string) error {
+	if name == "" || name == "." || strings.ContainsAny(name, `/\`+"\x00") || strings.Contains(name, "..") {
+		return fmt.Errorf("progress: bad learner name %q, it must not be empty or contain / \\ or ..", name)
+	}
+	return nil
+}
+
+// Path 返回 learner 的进度文件路径。
+func Path(dir, learner string) (string, error) {
+	if err := CheckLearner(learner); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, learner+".json"), nil
+}
+
+// Load 读取进度文件，文件不存在时返回空的进度。
+func Load(path string) (*Progress, error) {
+	p := &Progress{Lessons: map[string]*Record{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if p.Lessons == nil {
+		p.Lessons = map[string]*Record{}
+	}
+	return p, nil
+}
+
+// Save 先写临时文件再改名，中途出错不会留下写了一半的文件。
+func (p *Progress) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (p *Progress) record(id string, now time.Time) *Record {
+	r := p.Lessons[id]
+	if r == nil {
+		r = &Record{FirstSeen: now}
+		p.Lessons[id] = r
+	}
+	r.LastSeen = now
+	return r
+}
+
+// View 记录查看了一次示例。
+func (p *Progress) View(id string, now time.Time) {
+	p.record(id, now).Views++
+}
+
+// Run 记录运行了一次示例。
+func (p *Progress) Run(id string, now time.Time) {
+	p.record(id, now).Runs++
+}
+
+// Attempt 记录一次练习评分。
+func (p *Progress) Attempt(id string, score int, now time.Time) {
+	r := p.record(id, now)
+	r.Attempts++
+	if score > r.BestScore {
+		r.BestScore = score
+	}
+	if score == 100 && !r.Passed {
+		r.Passed = true
+		r.PassedAt = &now
+	}
+}
+
+// IDs 返回有记录的示例 ID，按字母排序。
+func (p *Progress) IDs() []string {
+	ids := make([]string, 0, len(p.Lessons))
+	for id := range p.Lessons {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Tracker 读取、修改、保存一个学员的进度文件，可以在多个 goroutine 中使用。
+type Tracker struct {
+	Path string
+	Now  func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewTracker 返回当前学员的 Tracker。
+func NewTracker() (*Tracker, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	learner, err := Learner()
+	if err != nil {
+		return nil, err
+	}
+	path, err := Path(dir, learner)
+	if err != nil {
+		return nil, err
+	}
+	return &Tracker{Path: path, Now: time.Now}, nil
+}
+
+// Update 读取进度文件，调用 f 修改后保存。
+func (t *Tracker) Update(f func(p *Progress, now time.Time)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := Load(t.Path)
+	if err != nil {
+		return err
+	}
+	if p.Learner == "" {
+		p.Learner = filepath.Base(t.Path[:len(t.Path)-len(filepath.Ext(t.Path))])
+	}
+	f(p, t.Now())
+	return p.Save(t.Path)
+}
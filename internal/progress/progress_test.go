@@ -0,0 +1,107 @@
+package progress
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"study/internal/lesson"
+)
+
+var t0 = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func TestTracker(t *testing.T) {
+	tr := &Tracker{Path: filepath.Join(t.TempDir(), "alice.json"), Now: func() time.Time { return t0 }}
+	steps := []func(p *Progress, now time.Time){
+		func(p *Progress, now time.Time) { p.View("c3/5.slice/Test_S9", now) },
+		func(p *Progress, now time.Time) { p.Run("c3/5.slice/Test_S9", now) },
+		func(p *Progress, now time.Time) { p.Attempt("c3/5.slice/Test_S12", 28, now) },
+		func(p *Progress, now time.Time) { p.Attempt("c3/5.slice/Test_S12", 100, now.Add(time.Hour)) },
+		func(p *Progress, now time.Time) { p.Attempt("c3/5.slice/Test_S12", 50, now) },
+	}
+	for _, f := range steps {
+		if err := tr.Update(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := Load(tr.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Learner != "alice" {
+		t.Errorf("learner = %q, want alice", p.Learner)
+	}
+	s9 := p.Lessons["c3/5.slice/Test_S9"]
+	if s9.Views != 1 || s9.Runs != 1 || !s9.FirstSeen.Equal(t0) {
+		t.Errorf("Test_S9 = %+v", s9)
+	}
+	s12 := p.Lessons["c3/5.slice/Test_S12"]
+	if s12.Attempts != 3 || !s12.Passed || s12.BestScore != 100 || s12.PassedAt == nil || !s12.PassedAt.Equal(t0.Add(time.Hour)) {
+		t.Errorf("Test_S12 = %+v", s12)
+	}
+}
+
+func TestLearner(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"alice", "张三", "a.b"} {
+		if path, err := Path(dir, name); err != nil || path != filepath.Join(dir, name+".json") {
+			t.Errorf("Path(%q) = %q, %v", name, path, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "../alice", "a/b", `a\b`, "a..b"} {
+		if path, err := Path(dir, name); err == nil {
+			t.Errorf("Path(%q) = %q, want an error", name, path)
+		}
+	}
+	t.Setenv("STUDY_LEARNER", "../../etc/cron.d/x")
+	if name, err := Learner(); err == nil {
+		t.Errorf("Learner() = %q with STUDY_LEARNER=../../etc/cron.d/x", name)
+	}
+	t.Setenv("STUDY_LEARNER", "bob")
+	if name, err := Learner(); err != nil || name != "bob" {
+		t.Errorf("Learner() = %q, %v", name, err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	s8 := lesson.Lesson{ID: "c3/5.slice/Test_S8", Name: "Test_S8", Package: "c3/5.slice", Chapter: "c3", Title: "append"}
+	s12 := lesson.Lesson{ID: "c3/5.slice/Test_S12", Name: "Test_S12", Package: "c3/5.slice", Chapter: "c3", Exercise: true}
+	a := &Progress{Learner: "a", Lessons: map[string]*Record{
+		s8.ID:  {Views: 1, Runs: 5},
+		s12.ID: {Attempts: 4, Passed: true, BestScore: 100},
+	}}
+	b := &Progress{Learner: "b", Lessons: map[string]*Record{
+		s12.ID:        {Attempts: 2, BestScore: 20},
+		"c1/main":     {Runs: 1},
+		"c4/2.map/X1": {Views: 1},
+	}}
+	r := NewReport([]*Progress{b, a}, []lesson.Lesson{s8, s12}, map[string]string{"c3": "切片"}, t0)
+
+	if len(r.Chapters) != 3 || r.Chapters[1].Name != "c3" {
+		t.Fatalf("chapters = %+v", r.Chapters)
+	}
+	c3 := r.Chapters[1].Lessons
+	if c3[0].Lesson.ID != s8.ID || c3[0].RunsPerLearner() != 5 || !c3[0].Stuck() {
+		t.Errorf("Test_S8 stats = %+v", c3[0])
+	}
+	if c3[1].Attempted != 2 || c3[1].Passed != 1 || c3[1].PassRate() != 0.5 || !c3[1].Stuck() {
+		t.Errorf("Test_S12 stats = %+v", c3[1])
+	}
+
+	var csv, html bytes.Buffer
+	if err := r.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(csv.String(), "\n"); lines != 6 {
+		t.Errorf("CSV has %d lines, want header + 5:\n%s", lines, csv.String())
+	}
+	if err := r.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `class="stuck"`) || !strings.Contains(html.String(), "c3 切片") {
+		t.Errorf("HTML report is missing the stuck lessons or chapter title")
+	}
+}
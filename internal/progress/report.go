@@ -0,0 +1,207 @@
+package progress
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"study/internal/lesson"
+)
+
+// LessonStats 一个示例在所有学员中的统计。
+type LessonStats struct {
+	Lesson    lesson.Lesson
+	Viewed    int // 看过或运行过的学员数
+	Runs      int // 运行总次数
+	Attempted int // 提交过练习的学员数
+	Attempts  int // 练习提交总次数
+	Passed    int // 通过练习的学员数
+}
+
+// RunsPerLearner 看过的学员平均运行了几次。
+func (s *LessonStats) RunsPerLearner() float64 {
+	if s.Viewed == 0 {
+		return 0
+	}
+	return float64(s.Runs) / float64(s.Viewed)
+}
+
+// AttemptsPerLearner 提交过的学员平均提交了几次。
+func (s *LessonStats) AttemptsPerLearner() float64 {
+	if s.Attempted == 0 {
+		return 0
+	}
+	return float64(s.Attempts) / float64(s.Attempted)
+}
+
+// PassRate 提交过的学员中通过的比例。
+func (s *LessonStats) PassRate() float64 {
+	if s.Attempted == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Attempted)
+}
+
+// Stuck 学员是否在这里卡住了：练习通过率低于一半或平均提交 3 次以上，
+// 普通示例平均运行 3 次以上。
+func (s *LessonStats) Stuck() bool {
+	if s.Attempted > 0 {
+		return s.PassRate() < 0.5 || s.AttemptsPerLearner() >= 3
+	}
+	return s.RunsPerLearner() >= 3
+}
+
+// ChapterStats 一个章节的统计。
+type ChapterStats struct {
+	Name    string
+	Title   string
+	Lessons []*LessonStats
+}
+
+// Report 合并多个学员的进度。
+type Report struct {
+	Generated time.Time
+	Learners  []*Progress
+	Chapters  []*ChapterStats
+}
+
+// NewReport 按章节汇总 ps。lessons 是登记的全部示例，titles 是章节标题。
+// 进度文件里有、但没有登记的示例也会出现在报告中。
+func NewReport(ps []*Progress, lessons []lesson.Lesson, titles map[string]string, now time.Time) *Report {
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Learner < ps[j].Learner })
+	r := &Report{Generated: now, Learners: ps}
+
+	stats := map[string]*LessonStats{}
+	for _, l := range lessons {
+		stats[l.ID] = &LessonStats{Lesson: l}
+	}
+	for _, p := range ps {
+		for id, rec := range p.Lessons {
+			s := stats[id]
+			if s == nil {
+				s = &LessonStats{Lesson: unregistered(id)}
+				stats[id] = s
+			}
+			if rec.Views > 0 || rec.Runs > 0 {
+				s.Viewed++
+			}
+			s.Runs += rec.Runs
+			if rec.Attempts > 0 {
+				s.Attempted++
+				s.Attempts += rec.Attempts
+			}
+			if rec.Passed {
+				s.Passed++
+			}
+		}
+	}
+
+	chapters := map[string]*ChapterStats{}
+	for _, s := range stats {
+		ch := chapters[s.Lesson.Chapter]
+		if ch == nil {
+			ch = &ChapterStats{Name: s.Lesson.Chapter, Title: titles[s.Lesson.Chapter]}
+			chapters[ch.Name] = ch
+			r.Chapters = append(r.Chapters, ch)
+		}
+		ch.Lessons = append(ch.Lessons, s)
+	}
+	sort.Slice(r.Chapters, func(i, j int) bool { return r.Chapters[i].Name < r.Chapters[j].Name })
+	for _, ch := range r.Chapters {
+		sort.Slice(ch.Lessons, func(i, j int) bool { return lesson.Less(ch.Lessons[i].Lesson, ch.Lessons[j].Lesson) })
+	}
+	return r
+}
+
+func unregistered(id string) lesson.Lesson {
+	l := lesson.Lesson{ID: id, Name: id[strings.LastIndex(id, "/")+1:]}
+	l.Package = strings.TrimSuffix(strings.TrimSuffix(id, l.Name), "/")
+	l.Chapter = l.Package
+	if i := strings.Index(l.Package, "/"); i >= 0 {
+		l.Chapter = l.Package[:i]
+	}
+	return l
+}
+
+// WriteCSV 每个学员、每个示例一行。
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"learner", "chapter", "lesson", "title", "exercise", "views", "runs", "attempts", "passed", "best_score", "first_seen", "last_seen", "passed_at"})
+	for _, p := range r.Learners {
+		for _, ch := range r.Chapters {
+			for _, s := range ch.Lessons {
+				rec := p.Lessons[s.Lesson.ID]
+				if rec == nil {
+					continue
+				}
+				cw.Write([]string{
+					p.Learner, ch.Name, s.Lesson.ID, s.Lesson.Title,
+					strconv.FormatBool(s.Lesson.Exercise),
+					strconv.Itoa(rec.Views), strconv.Itoa(rec.Runs), strconv.Itoa(rec.Attempts),
+					strconv.FormatBool(rec.Passed), strconv.Itoa(rec.BestScore),
+					timestamp(rec.FirstSeen), timestamp(rec.LastSeen), passedAt(rec),
+				})
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func passedAt(r *Record) string {
+	if r.PassedAt == nil {
+		return ""
+	}
+	return timestamp(*r.PassedAt)
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// WriteHTML 按章节输出汇总表，卡住的示例标红。
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportHTML.Execute(w, r)
+}
+
+var reportHTML = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return strconv.Itoa(int(f*100+0.5)) + "%" },
+	"fixed":   func(f float64) string { return strconv.FormatFloat(f, 'f', 1, 64) },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>学习进度报告</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; text-align: right; }
+th:first-child, td:first-child, td.title { text-align: left; }
+tr.stuck { background: #fde2e1; }
+</style>
+</head>
+<body>
+<h1>学习进度报告</h1>
+<p>{{len .Learners}} 名学员，生成于 {{.Generated.Format "2006-01-02 15:04"}}。标红的示例平均运行或提交 3 次以上，或练习通过率不到一半。</p>
+{{range .Chapters}}
+<h2>{{.Name}} {{.Title}}</h2>
+<table>
+<tr><th>示例</th><th>标题</th><th>看过</th><th>人均运行</th><th>提交</th><th>人均提交</th><th>通过</th><th>通过率</th></tr>
+{{range .Lessons}}<tr{{if .Stuck}} class="stuck"{{end}}>
+<td>{{.Lesson.ID}}</td><td class="title">{{.Lesson.Title}}{{if .Lesson.Exercise}} (练习){{end}}</td>
+<td>{{.Viewed}}</td><td>{{fixed .RunsPerLearner}}</td>
+<td>{{if .Lesson.Exercise}}{{.Attempted}}{{end}}</td><td>{{if .Lesson.Exercise}}{{fixed .AttemptsPerLearner}}{{end}}</td>
+<td>{{if .Lesson.Exercise}}{{.Passed}}{{end}}</td><td>{{if .Attempted}}{{percent .PassRate}}{{end}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,240 @@
+// Package quiz 实现“猜输出”：只给出示例的代码（去掉注释，免得注释直接给出答案），
+// 学员写下预期的输出，再运行示例逐行对比，最后给出示例的说明注释。
+package quiz
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/golden"
+	"study/internal/lesson"
+	"study/internal/textdiff"
+)
+
+// Skip 返回示例不适合猜输出的原因，适合时返回 ""。
+// 输出依赖 map 遍历顺序的示例仍然可以猜，比较时不计行的顺序。
+func Skip(l lesson.Lesson) string {
+	switch {
+	case l.Exercise:
+		return "练习题，用 study grade 评分"
+	case l.Expect != lesson.Prints:
+		return "示例会 " + l.Expect.String() + "，输出不是重点"
+	case l.HasTag(lesson.TagRacy):
+		return "输出依赖 goroutine 的调度，每次运行都可能不同"
+	case l.HasTag(lesson.TagAddress):
+		return "输出包含内存地址，无法预测"
+	}
+	return ""
+}
+
+// Result 一次作答的结果。
+type Result struct {
+	Lines   []textdiff.Line // Delete 是答案中多出的行，Insert 是答案中缺少的行
+	Right   int             // 答对的行数
+	Total   int             // 实际输出的行数
+	Correct bool
+}
+
+// Check 比较学员的答案和实际输出。行尾空白和结尾的空行不计，
+// opt.Unordered 时不计行的顺序。
+func Check(answer string, output []byte, opt golden.Options) *Result {
+	want := clean(string(output), opt)
+	got := clean(answer, opt)
+	r := &Result{Lines: textdiff.Lines(got, want)}
+	for _, l := range r.Lines {
+		switch l.Op {
+		case textdiff.Equal:
+			r.Right++
+			r.Total++
+		case textdiff.Insert:
+			r.Total++
+		}
+	}
+	r.Correct = r.Right == r.Total && len(r.Lines) == r.Total
+	return r
+}
+
+// clean 返回归一化后的文本，每行以换行结尾。
+func clean(s string, opt golden.Options) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return string(golden.Normalize([]byte(strings.Join(lines, "\n")+"\n"), opt))
+}
+
+// Code 返回出题用的代码：示例函数，以及它直接或间接用到的包级函数、变量、常量和类型
+// （类型连同它的方法），按源码顺序排列，去掉了所有注释。
+func Code(root string, l *course.Lesson) (string, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	srcs := map[*ast.File][]byte{}
+	for _, name := range l.Package.Files {
+		src, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return "", err
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return "", err
+		}
+		files = append(files, f)
+		srcs[f] = src
+	}
+
+	// 包级名字 → 声明，方法挂在接收者类型名下。
+	decls := map[string][]ast.Decl{}
+	var lessonDecl ast.Decl
+	for _, f := range files {
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv != nil {
+					if name := recvType(d.Recv.List[0].Type); name != "" {
+						decls[name] = append(decls[name], d)
+					}
+					continue
+				}
+				decls[d.Name.Name] = append(decls[d.Name.Name], d)
+				if d.Name.Name == l.Name && fset.Position(d.Pos()).Filename == l.File {
+					lessonDecl = d
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						decls[s.Name.Name] = append(decls[s.Name.Name], d)
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							decls[n.Name] = append(decls[n.Name], d)
+						}
+					}
+				}
+			}
+		}
+	}
+	if lessonDecl == nil {
+		return "", os.ErrNotExist
+	}
+
+	used := map[ast.Decl]bool{}
+	var visit func(d ast.Decl)
+	visit = func(d ast.Decl) {
+		if used[d] {
+			return
+		}
+		used[d] = true
+		ast.Inspect(d, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				for _, d := range decls[id.Name] {
+					visit(d)
+				}
+			}
+			return true
+		})
+	}
+	visit(lessonDecl)
+
+	// 源码中紧挨着的声明（例如连续几行 var）输出时也不空行。
+	var b strings.Builder
+	for _, f := range files {
+		prevLine := -1
+		for _, d := range f.Decls {
+			if !used[d] {
+				continue
+			}
+			if b.Len() > 0 {
+				if fset.Position(d.Pos()).Line == prevLine+1 {
+					b.WriteString("\n")
+				} else {
+					b.WriteString("\n\n")
+				}
+			}
+			b.WriteString(stripComments(fset, f, srcs[f], d))
+			prevLine = fset.Position(d.End()).Line
+		}
+	}
+	return b.String(), nil
+}
+
+func recvType(x ast.Expr) string {
+	switch t := x.(type) {
+	case *ast.StarExpr:
+		return recvType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// stripComments 返回声明的源码，去掉其中的注释和只有注释的行，再用 gofmt 排版。
+func stripComments(fset *token.FileSet, f *ast.File, src []byte, d ast.Decl) string {
+	tf := fset.File(d.Pos())
+	start, end := tf.Offset(d.Pos()), tf.Offset(d.End())
+	code := append([]byte(nil), src[start:end]...)
+	for _, cg := range f.Comments {
+		if cg.Pos() < d.Pos() || cg.End() > d.End() {
+			continue
+		}
+		for i := tf.Offset(cg.Pos()) - start; i < tf.Offset(cg.End())-start; i++ {
+			if code[i] != '\n' {
+				code[i] = ' '
+			}
+		}
+	}
+
+	orig := strings.Split(string(src[start:end]), "\n")
+	var lines []string
+	for i, line := range strings.Split(string(code), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && strings.TrimSpace(orig[i]) != "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	out := []byte(strings.Join(lines, "\n"))
+	if formatted, err := format.Source(out); err == nil {
+		out = formatted
+	}
+	return string(bytes.TrimSpace(out))
+}
+
+// Note 示例中的一条注释，作答后作为解释显示。
+type Note struct {
+	Line int
+	Text string
+}
+
+// Notes 返回示例函数体内的注释，按行号排列。
+func Notes(root string, l *course.Lesson) ([]Note, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(l.File)), nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for _, cg := range f.Comments {
+		line := fset.Position(cg.Pos()).Line
+		if line < l.Line || line > l.EndLine {
+			continue
+		}
+		if text := strings.TrimSpace(cg.Text()); text != "" {
+			notes = append(notes, Note{Line: line, Text: text})
+		}
+	}
+	return notes, nil
+}
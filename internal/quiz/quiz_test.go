@@ -0,0 +1,91 @@
+package quiz
+
+import (
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+	"study/internal/golden"
+	"study/internal/lesson"
+	_ "study/internal/lesson/all"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    string
+		output    string
+		unordered bool
+		right     int
+		correct   bool
+	}{
+		{"exact", "x = 20 y = 120\ndefer: 10 120\n", "x = 20 y = 120\ndefer: 10 120\n", false, 2, true},
+		{"trailing space and blank lines", "a  \r\nb\n\n\n", "a\nb\n", false, 2, true},
+		{"wrong line", "4\n3\n", "4\n4\n", false, 1, false},
+		{"extra line", "1\n2\n", "1\n", false, 1, false},
+		{"empty output", "", "", false, 0, true},
+		{"order matters", "b 2\na 1\n", "a 1\nb 2\n", false, 1, false},
+		{"map order ignored", "b 2\na 1\n", "a 1\nb 2\n", true, 2, true},
+	}
+	for _, tt := range tests {
+		r := Check(tt.answer, []byte(tt.output), golden.Options{Unordered: tt.unordered})
+		if r.Right != tt.right || r.Correct != tt.correct {
+			t.Errorf("%s: right %d/%d correct %v, want right %d correct %v", tt.name, r.Right, r.Total, r.Correct, tt.right, tt.correct)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	for _, l := range lesson.All() {
+		reason := Skip(l)
+		if l.HasTag(lesson.TagPuzzle) && reason != "" {
+			t.Errorf("puzzle %s is skipped: %s", l.ID, reason)
+		}
+		if !golden.Stable(l) && reason == "" {
+			t.Errorf("%s has unstable output but is not skipped", l.ID)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	c := coursetest.Load(t)
+
+	tests := []struct {
+		dir, name string
+		want      []string
+		not       []string
+	}{
+		{"c6/3.concurrencyControl", "TestC3",
+			[]string{"var ch1 chan int\nvar ch2 chan int", "var numbers", "func getNumber(i int) int", "func getChan(i int) chan int", "func TestC3("},
+			[]string{"//", "求值顺序", "func TestC2("}},
+		{"c4/1.function", "TestF8",
+			[]string{"defer fmt.Println(i)"},
+			[]string{"闭包", "defer特性"}},
+		{"c3/2.control", "TestC2",
+			[]string{"fallthrough\n\tcase 1:"},
+			[]string{"/*", "Go的switch"}},
+		{"c5/2.method", "TestM1",
+			[]string{"type Person struct", "func (p Person) Dream()", "func (p *Person) SetAge(newAge int)"},
+			[]string{"//", "type Dog"}},
+	}
+	for _, tt := range tests {
+		l, err := c.Lesson(tt.dir, tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, err := Code(c.Root, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(code, s) {
+				t.Errorf("%s: code does not contain %q:\n%s", l.Path(), s, code)
+			}
+		}
+		for _, s := range tt.not {
+			if strings.Contains(code, s) {
+				t.Errorf("%s: code contains %q:\n%s", l.Path(), s, code)
+			}
+		}
+	}
+}
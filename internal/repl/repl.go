@@ -0,0 +1,443 @@
+// Package repl 在一个课程包的类型上交互地执行 Go 语句。
+//
+// 课程包都是测试文件，不能被导入，所以把包中 Test 函数以外的声明复制到临时模块的
+// package main 中（见 course.MainProgram）。每输入一条语句，就把之前执行成功的语句和它
+// 一起放进 main 函数，重新编译运行，只显示这条语句的输出；表达式的值和新定义的变量
+// 用 %#v 和 %T 显示。之前的语句每次都会重新执行，所以其中的输出不再显示。
+package repl
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/runner"
+	"study/internal/snippet"
+)
+
+// mark 程序在执行新语句之前输出的分隔行。
+const mark = "\x1estudy-repl\x1e"
+
+// helpers 临时模块中固定的辅助函数。
+const helpers = `package main
+
+import "fmt"
+
+func studyReplMark() { fmt.Println("` + mark + `") }
+
+func studyReplShow(name string, v interface{}) {
+	if name != "" {
+		fmt.Printf("%s = ", name)
+	}
+	fmt.Printf("%#v  (%T)\n", v, v)
+}
+`
+
+// Session 一次 REPL 会话。
+type Session struct {
+	Package *course.Package
+	Timeout time.Duration // 每次运行的时间上限
+
+	dir     string // 临时模块
+	fset    *token.FileSet
+	prelude []*ast.File // 从课程包复制的声明和 helpers
+	imp     types.Importer
+	decls   []string // 输入的类型和函数声明
+	stmts   []string // 执行成功、需要保留的语句，加上了 _ = 变量 等
+	inputs  []string // stmts 对应的原始输入
+}
+
+// Start 为课程包 p 创建临时模块，开始一次会话。用完后调用 Close。
+func Start(root string, p *course.Package) (*Session, error) {
+	dir, err := os.MkdirTemp("", "study-repl-")
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{Package: p, Timeout: 10 * time.Second, dir: dir, fset: token.NewFileSet(), imp: importer.Default()}
+	files := map[string]string{
+		"go.mod":        "module repl\n\ngo 1.17\n",
+		"study_repl.go": helpers,
+	}
+	for _, name := range p.Files {
+		src, err := course.MainProgram(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		// go build 不编译 _test.go
+		files[strings.TrimSuffix(filepath.Base(name), "_test.go")+".go"] = src
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			s.Close()
+			return nil, err
+		}
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(s.fset, name, src, 0)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.prelude = append(s.prelude, f)
+	}
+	return s, nil
+}
+
+// Close 删除临时模块。
+func (s *Session) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// Reset 清空输入过的声明和语句。
+func (s *Session) Reset() {
+	s.decls, s.stmts, s.inputs = nil, nil, nil
+}
+
+// History 返回输入过的声明和之后还会执行的语句。
+func (s *Session) History() []string {
+	return append(append([]string(nil), s.decls...), s.inputs...)
+}
+
+// Types 返回课程包中定义的类型名。
+func (s *Session) Types() []string {
+	pkg, _ := s.check(nil)
+	var names []string
+	if pkg == nil {
+		return nil
+	}
+	for _, name := range pkg.Scope().Names() {
+		if _, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Result 一次输入的结果。
+type Result struct {
+	Output string // 新语句的输出，以及表达式和新变量的值
+	Err    string // 编译错误、panic 或超时，不为空时这条输入不会保留
+}
+
+// Eval 执行一条输入：类型或函数声明、一条或几条语句、或者一个表达式。
+func (s *Session) Eval(ctx context.Context, input string) (*Result, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return &Result{}, nil
+	}
+	if isDecl(input) {
+		decls := append(append([]string(nil), s.decls...), input)
+		_, main, _, err := s.mainFile(decls, s.stmts, "", nil)
+		if err != nil {
+			return &Result{Err: err.Error()}, nil
+		}
+		if _, err := s.check(main); err != nil {
+			return &Result{Err: err.Error()}, nil
+		}
+		s.decls = decls
+		return &Result{}, nil
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", "package p; func _() {\n"+input+"\n}", 0); err != nil {
+		return &Result{Err: syntaxError(err)}, nil
+	}
+	_, main, body, err := s.mainFile(s.decls, s.stmts, input, nil)
+	if err != nil {
+		return &Result{Err: err.Error()}, nil
+	}
+	info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}, Defs: map[*ast.Ident]types.Object{}}
+	_, expr := singleExpr(body)
+	if _, err := s.checkInfo(main, info, expr); err != nil {
+		return &Result{Err: err.Error()}, nil
+	}
+
+	// 表达式显示它的值；语句显示新定义的变量，之后的程序中用 _ = 变量 避免 “declared and not used”
+	var show []string
+	orig, keep := input, input
+	if es, ok := singleExpr(body); ok {
+		tv := info.Types[es.X]
+		n := 1
+		if t, ok := tv.Type.(*types.Tuple); ok {
+			n = t.Len()
+		}
+		if n > 0 && !tv.IsVoid() {
+			vars := make([]string, n)
+			for i := range vars {
+				vars[i] = fmt.Sprintf("studyReplV%d", i)
+				show = append(show, fmt.Sprintf("studyReplShow(%q, %s)", "", vars[i]))
+			}
+			input = strings.Join(vars, ", ") + " := " + input
+			keep = strings.Repeat("_, ", n-1) + "_ = " + keep
+		}
+		if !hasCall(es.X, info) {
+			keep = ""
+		}
+	} else {
+		for _, name := range defined(body, info) {
+			show = append(show, fmt.Sprintf("studyReplShow(%q, %s)", name, name))
+			keep += "\n_ = " + name
+		}
+	}
+
+	src, _, _, err := s.mainFile(s.decls, s.stmts, input, show)
+	if err != nil {
+		return &Result{Err: err.Error()}, nil
+	}
+	res, err := s.run(ctx, src)
+	if err != nil || res.Err != "" {
+		return res, err
+	}
+	if keep != "" {
+		s.stmts = append(s.stmts, keep)
+		s.inputs = append(s.inputs, orig)
+	}
+	return res, nil
+}
+
+// isDecl 判断输入是不是顶层的类型或函数声明。
+func isDecl(input string) bool {
+	return strings.HasPrefix(input, "func ") || strings.HasPrefix(input, "type ") && strings.ContainsAny(input, "{ ")
+}
+
+// mainFile 生成 main 函数所在的源文件并解析，返回源码、解析结果和 input 对应的语句。
+func (s *Session) mainFile(decls, stmts []string, input string, show []string) (string, *ast.File, []ast.Stmt, error) {
+	var b strings.Builder
+	b.WriteString("package main\n\n")
+	for _, d := range decls {
+		b.WriteString(d + "\n\n")
+	}
+	b.WriteString("func main() {\n")
+	for _, st := range stmts {
+		b.WriteString(st + "\n")
+	}
+	b.WriteString("studyReplMark()\n")
+	if input != "" {
+		b.WriteString(input + "\n")
+	}
+	for _, st := range show {
+		b.WriteString(st + "\n")
+	}
+	b.WriteString("}\n")
+
+	src := b.String()
+	f, err := parser.ParseFile(s.fset, "main.go", src, 0)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%s", syntaxError(err))
+	}
+	if imports := snippet.MissingImports(f); len(imports) > 0 {
+		head := "package main\n\nimport ("
+		for _, path := range imports {
+			head += fmt.Sprintf("%q; ", path)
+		}
+		src = head + ")\n" + strings.TrimPrefix(src, "package main\n")
+		if f, err = parser.ParseFile(s.fset, "main.go", src, 0); err != nil {
+			return "", nil, nil, fmt.Errorf("%s", syntaxError(err))
+		}
+	}
+
+	// main 函数中标记之后的语句
+	var body []ast.Stmt
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != "main" {
+			continue
+		}
+		for i, st := range fd.Body.List {
+			if isMark(st) {
+				body = fd.Body.List[i+1:]
+			}
+		}
+	}
+	return src, f, body, nil
+}
+
+func isMark(st ast.Stmt) bool {
+	es, ok := st.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := es.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	return ok && id.Name == "studyReplMark"
+}
+
+func (s *Session) check(main *ast.File) (*types.Package, error) {
+	return s.checkInfo(main, nil, false)
+}
+
+// checkInfo 对课程包的声明和 main 做类型检查。没有使用的变量不算错误，显示新变量时会用到它们；
+// expr 为 true 时输入是一个表达式，它的值没有使用也不算错误，之后会显示它。
+func (s *Session) checkInfo(main *ast.File, info *types.Info, expr bool) (*types.Package, error) {
+	var first error
+	conf := types.Config{
+		Importer: s.imp,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok && (e.Soft || expr && strings.HasSuffix(e.Msg, " is not used")) {
+				return
+			}
+			if first == nil {
+				first = err
+			}
+		},
+	}
+	files := s.prelude
+	if main != nil {
+		files = append(append([]*ast.File(nil), s.prelude...), main)
+	}
+	pkg, _ := conf.Check("main", s.fset, files, info)
+	if first != nil {
+		if e, ok := first.(types.Error); ok {
+			return pkg, fmt.Errorf("%s", e.Msg)
+		}
+		return pkg, first
+	}
+	return pkg, nil
+}
+
+func singleExpr(body []ast.Stmt) (*ast.ExprStmt, bool) {
+	if len(body) != 1 {
+		return nil, false
+	}
+	es, ok := body[0].(*ast.ExprStmt)
+	return es, ok
+}
+
+// hasCall 判断表达式中有没有函数调用（类型转换不算）。有调用时它可能修改变量，之后要保留。
+func hasCall(e ast.Expr, info *types.Info) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && !info.Types[call.Fun].IsType() {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// defined 返回 body 中直接定义的变量（x := ...、var x ...），不含 _。
+func defined(body []ast.Stmt, info *types.Info) []string {
+	var names []string
+	add := func(id *ast.Ident) {
+		if id.Name != "_" && info.Defs[id] != nil {
+			names = append(names, id.Name)
+		}
+	}
+	for _, st := range body {
+		switch st := st.(type) {
+		case *ast.AssignStmt:
+			if st.Tok != token.DEFINE {
+				continue
+			}
+			for _, e := range st.Lhs {
+				if id, ok := e.(*ast.Ident); ok {
+					add(id)
+				}
+			}
+		case *ast.DeclStmt:
+			gd, ok := st.Decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				for _, id := range spec.(*ast.ValueSpec).Names {
+					add(id)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// compileError 匹配 go build 输出中的错误行，例如 ./main.go:12:5: undefined: x。
+var compileError = regexp.MustCompile(`(?m)^\./[^:\s]+:\d+:\d+: (.*)$`)
+
+// run 写入 main.go，编译运行，返回标记之后的输出。
+func (s *Session) run(ctx context.Context, src string) (*Result, error) {
+	if err := os.WriteFile(filepath.Join(s.dir, "main.go"), []byte(src), 0o644); err != nil {
+		return nil, err
+	}
+	bin, cleanup, err := runner.Build(ctx, s.dir, ".", true)
+	if err != nil {
+		var msgs []string
+		for _, m := range compileError.FindAllStringSubmatch(err.Error(), -1) {
+			msgs = append(msgs, m[1])
+		}
+		if len(msgs) == 0 {
+			return nil, err
+		}
+		return &Result{Err: strings.Join(msgs, "\n")}, nil
+	}
+	defer cleanup()
+
+	res, err := runner.Exec(ctx, s.dir, bin, nil, runner.Options{Timeout: s.Timeout, Combined: true})
+	if err != nil {
+		return nil, err
+	}
+	out := string(res.Stdout)
+	if i := strings.Index(out, mark+"\n"); i >= 0 {
+		out = out[i+len(mark)+1:]
+	}
+	r := &Result{Output: out}
+	switch {
+	case res.TimedOut:
+		r.Err = fmt.Sprintf("timed out after %v", s.Timeout)
+	case res.ExitCode != 0:
+		r.Err = fmt.Sprintf("exit status %d", res.ExitCode)
+	}
+	return r, nil
+}
+
+// syntaxError 返回第一个语法错误，去掉生成的文件中的位置。
+func syntaxError(err error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, " (and "); i >= 0 {
+		msg = msg[:i]
+	}
+	if i := strings.LastIndex(msg, ": "); i >= 0 && strings.Contains(msg[:i], ":") {
+		msg = msg[i+2:]
+	}
+	return msg
+}
+
+// Complete 判断输入的括号是否已经配对，没有配对时 REPL 继续读下一行。
+func Complete(input string) bool {
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && quote != '`' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '{' || r == '(' || r == '[':
+			depth++
+		case r == '}' || r == ')' || r == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
@@ -0,0 +1,69 @@
+package repl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+)
+
+func TestEval(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program for every statement")
+	}
+	c := coursetest.Load(t)
+	s, err := Start(c.Root, c.Package("c5/2.method"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	types := strings.Join(s.Types(), " ")
+	for _, name := range []string{"Animal", "Dog", "MyInt", "Person", "Teacher"} {
+		if !strings.Contains(" "+types+" ", " "+name+" ") {
+			t.Errorf("Types() = %s, missing %s", types, name)
+		}
+	}
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		input, output, err string
+	}{
+		{`p := Person{name: "小王子", age: 25}`, "p = main.Person{name:\"小王子\", age:25}  (main.Person)\n", ""},
+		{"p.SetAge(30)", "", ""},
+		{"p.age", "30  (int)\n", ""},
+		{"p.Dream()", "小王子的梦想是学好Go语言！\n", ""},
+		{"MyInt(3)", "3  (main.MyInt)\n", ""},
+		{"strings.Repeat(p.name, 2)", "\"小王子小王子\"  (string)\n", ""},
+		{"q.age", "", "undefined: q"},
+		{"p.age = ", "", "expected operand, found '}'"},
+		{"func (m MyInt) Double() MyInt {\n\treturn m * 2\n}", "", ""},
+		{"MyInt(4).Double()", "8  (main.MyInt)\n", ""},
+	} {
+		r, err := s.Eval(ctx, tt.input)
+		if err != nil {
+			t.Fatalf("Eval(%q): %v", tt.input, err)
+		}
+		if r.Output != tt.output || r.Err != tt.err {
+			t.Errorf("Eval(%q) = %q, %q; want %q, %q", tt.input, r.Output, r.Err, tt.output, tt.err)
+		}
+	}
+	// 失败的输入和没有函数调用的表达式不保留
+	if h := s.History(); len(h) != 6 {
+		t.Errorf("History() = %q", h)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	for input, want := range map[string]bool{
+		"p.age":                    true,
+		"for i := 0; i < 3; i++ {": false,
+		`s := "{"`:                 true,
+		"f(`)\n(`":                 false,
+	} {
+		if got := Complete(input); got != want {
+			t.Errorf("Complete(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+// Package runner 编译并运行单个课程示例，分别捕获标准输出和标准错误。
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"study/internal/course"
+	"study/internal/sandbox"
+)
+
+// Result 一次运行的结果。
+type Result struct {
+	Stdout    []byte
+	Stderr    []byte
+	ExitCode  int
+	Duration  time.Duration
+	TimedOut  bool
+	Signal    string // 在沙箱中运行、被信号杀死时的信号
+	Truncated bool   // 在沙箱中运行、输出超过限制被截断
+}
+
+// Failed 示例是否以非零状态退出或超时。
+func (r *Result) Failed() bool {
+	return r.ExitCode != 0 || r.TimedOut
+}
+
+// Options 运行参数。
+type Options struct {
+	Timeout time.Duration // 为 0 时不限时
+	Env     []string      // 追加到当前环境变量之后
+
+	// Stdout、Stderr 不为 nil 时，输出在收集的同时实时写到这里。
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Sandbox 不为 nil 时在沙箱中以这些限制运行，Timeout 不为 0 时覆盖其中的 WallTime。
+	// 运行学员写的代码时应当设置。程序在沙箱的私有目录中运行，
+	// 只有 dir 中被源码提到的文件（见 Fixtures）会复制进去。
+	Sandbox *sandbox.Limits
+
+	// Combined 为 true 时 stdout 和 stderr 共用一个管道，Result.Stdout 按实际的先后顺序
+	// 包含两路输出，Result.Stderr 为空。不能和 Sandbox 一起使用。
+	Combined bool
+}
+
+// Run 编译 l 所在的包，并只运行 l 这一个示例。
+// 测试二进制在包目录下运行，这样示例里的相对路径（例如 1.txt）才能找到。
+func Run(ctx context.Context, root string, l *course.Lesson, opt Options) (*Result, error) {
+	bin, cleanup, err := Build(ctx, root, l.Package.Dir, l.Name == course.MainLesson)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	return Exec(ctx, filepath.Join(root, l.Package.Dir), bin, Args(l.Name), opt)
+}
+
+// Args 返回只运行 name 这一个示例时传给测试二进制的参数。
+func Args(name string) []string {
+	if name == course.MainLesson {
+		return nil
+	}
+	return []string{"-test.run", "^" + name + "$", "-test.count", "1"}
+}
+
+// Build 编译 root 下的 dir 包。main 为 true 时编译可执行程序，否则编译测试二进制。
+// 返回的 cleanup 删除编译产物。
+func Build(ctx context.Context, root, dir string, main bool) (bin string, cleanup func(), err error) {
+	return BuildWith(ctx, root, dir, main, nil)
+}
+
+// BuildWith 与 Build 相同，但编译时用 files 中的内容替换对应的源文件（go build -overlay），
+// files 的键是相对 root 的路径。仓库中的文件不会被修改。
+func BuildWith(ctx context.Context, root, dir string, main bool, files map[string][]byte) (bin string, cleanup func(), err error) {
+	tmp, err := os.MkdirTemp("", "study-build-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+
+	bin = filepath.Join(tmp, "lesson.test")
+	args := []string{"test", "-c", "-o", bin}
+	if main {
+		args = []string{"build", "-o", bin}
+	}
+	if len(files) > 0 {
+		overlay, err := writeOverlay(tmp, root, files)
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		args = append(args, "-overlay", overlay)
+	}
+	args = append(args, "./"+dir)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = root
+	// 不让 #cgo 指令在编译时执行任意命令；go 指令比本地工具链新时报错，不去下载别的工具链
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOTOOLCHAIN=local")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("runner: build %s: %v\n%s", dir, err, out.Bytes())
+	}
+	return bin, cleanup, nil
+}
+
+// Vet 对 root 下的 dir 包运行 go vet，files 的含义与 BuildWith 相同。
+// vet 发现问题时以非零状态退出，所以不当作错误，只返回它的输出。
+// 输出中替换的文件用 files 中的名字（只替换一个文件时），见 vetNames。
+func Vet(ctx context.Context, root, dir string, files map[string][]byte) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "study-vet-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	args := []string{"vet"}
+	if len(files) > 0 {
+		overlay, err := writeOverlay(tmp, root, files)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-overlay", overlay)
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, "./"+dir)...)
+	cmd.Dir = root
+	// 与 BuildWith 相同，不去下载别的工具链
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+	return vetNames(out, root, files), nil
+}
+
+var vetPosition = regexp.MustCompile(`(?m)^(\S+?\.go)(:\d+:\d+: )`)
+
+// vetNames 把 out 中不在 root 下的文件（替换的文件）换成 files 中唯一的那个文件。go vet 的缓存不区分文件路径，
+// 内容相同时会直接输出以前的结果，其中替换的文件可能是以前某次运行的临时文件。
+func vetNames(out []byte, root string, files map[string][]byte) []byte {
+	if len(files) != 1 {
+		return out
+	}
+	var name string
+	for name = range files {
+	}
+	return vetPosition.ReplaceAllFunc(out, func(m []byte) []byte {
+		sub := vetPosition.FindSubmatch(m)
+		if rel, err := filepath.Rel(root, string(sub[1])); !filepath.IsAbs(string(sub[1])) || err == nil && !strings.HasPrefix(rel, "..") {
+			return m
+		}
+		return append([]byte(name), sub[2]...)
+	})
+}
+
+// BuildLimits 在沙箱中编译学员代码时的默认限制。go 命令会并行启动编译器和链接器，
+// 进程数和内存都比运行时宽松。
+var BuildLimits = sandbox.Limits{
+	CPUTime:  time.Minute,
+	Memory:   2 << 30,
+	Procs:    1024,
+	Output:   1 << 20,
+	WallTime: time.Minute,
+}
+
+// BuildSandboxed 与 BuildWith 相同，但 go 命令在沙箱中以 lim 运行：
+// 只把 dir 依赖的仓库中的包和 go.mod 复制进沙箱的私有目录，files 直接写在对应的位置。
+// 编译产物应当同样在沙箱中运行。
+func BuildSandboxed(ctx context.Context, root, dir string, main bool, files map[string][]byte, lim sandbox.Limits) (bin string, cleanup func(), err error) {
+	tmp, err := os.MkdirTemp("", "study-build-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+	src, err := sources(ctx, tmp, root, dir, main, files)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	// 沙箱中编译用的用户可能不是调用者，输出和缓存目录都要交给它
+	out, err := os.MkdirTemp("", "study-build-out-")
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	defer os.RemoveAll(out)
+	cache, err := sandboxCache()
+	if err == nil {
+		err = sandbox.BuilderDir(out)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	// go 不认私有目录（也是 TMPDIR）根上的 go.mod，源码放在 src 下
+	args := []string{"test", "-c", "-o", filepath.Join(out, "lesson.test")}
+	if main {
+		args = []string{"build", "-o", filepath.Join(out, "lesson.test")}
+	}
+	res, err := sandbox.Run(ctx, &sandbox.Cmd{
+		Path:    gobin,
+		Args:    append(args, "./"+dir),
+		Env:     []string{"GOCACHE=" + cache, "GOTOOLCHAIN=local", "GOPROXY=off", "CGO_ENABLED=0"},
+		Files:   src,
+		Dir:     "src",
+		Limits:  lim,
+		Builder: true,
+	})
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if !res.OK() {
+		cleanup()
+		status := "exit status " + strconv.Itoa(res.ExitCode)
+		switch {
+		case res.TimedOut:
+			status = "timed out after " + lim.WallTime.String()
+		case res.Signal != "":
+			status = "killed: " + res.Signal
+		}
+		return "", nil, fmt.Errorf("runner: build %s: %s\n%s%s", dir, status, res.Stdout, res.Stderr)
+	}
+	bin = filepath.Join(tmp, "lesson.test")
+	if err := os.Rename(filepath.Join(out, "lesson.test"), bin); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	// 编译用的用户的 umask 是 077，运行的用户要能执行
+	if err := os.Chown(bin, os.Getuid(), os.Getgid()); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return bin, cleanup, os.Chmod(bin, 0o755)
+}
+
+// sources 返回编译 dir 需要复制进沙箱的文件：go.mod 和 dir 依赖的仓库中的包的 Go 源码、
+// 嵌入的文件，键是 src/ 加上相对 root 的路径。files 中的内容先写到 tmp 中。
+func sources(ctx context.Context, tmp, root, dir string, main bool, files map[string][]byte) (map[string]string, error) {
+	args := []string{"list", "-deps", "-json"}
+	if !main {
+		args = append(args, "-test")
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, "./"+dir)...)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOTOOLCHAIN=local")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("runner: list %s: %v\n%s", dir, err, stderr.Bytes())
+	}
+
+	src := map[string]string{}
+	add := func(path string) error {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("runner: %s is outside %s", path, root)
+		}
+		src["src/"+filepath.ToSlash(rel)] = path
+		return nil
+	}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			add(filepath.Join(root, name))
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var p struct {
+			Dir                                         string
+			Standard                                    bool
+			EmbedFiles, TestEmbedFiles, XTestEmbedFiles []string
+		}
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if p.Standard || p.Dir == "" {
+			continue
+		}
+		gofiles, err := filepath.Glob(filepath.Join(p.Dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range append(append(p.EmbedFiles, p.TestEmbedFiles...), p.XTestEmbedFiles...) {
+			gofiles = append(gofiles, filepath.Join(p.Dir, name))
+		}
+		for _, path := range gofiles {
+			if err := add(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	i := 0
+	for name, data := range files {
+		i++
+		path := filepath.Join(tmp, fmt.Sprintf("file%d.go", i))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, err
+		}
+		src["src/"+filepath.ToSlash(filepath.Clean(name))] = path
+	}
+	return src, nil
+}
+
+// sandboxCache 返回沙箱中编译共用的 GOCACHE。仓库用户的缓存在沙箱中的用户访问不到的地方，
+// 每次都用新缓存又要重新编译标准库。缓存只有编译用的用户能访问，运行学员代码时改不了它（见 sandbox.BuilderDir）。
+func sandboxCache() (string, error) {
+	dir := filepath.Join(os.TempDir(), "study-sandbox-gocache")
+	if err := os.Mkdir(dir, 0o700); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	return dir, sandbox.BuilderDir(dir)
+}
+
+func writeOverlay(tmp, root string, files map[string][]byte) (string, error) {
+	replace := map[string]string{}
+	i := 0
+	for name, data := range files {
+		i++
+		path := filepath.Join(tmp, fmt.Sprintf("overlay%d.go", i))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return "", err
+		}
+		replace[filepath.Join(root, filepath.FromSlash(name))] = path
+	}
+	data, err := json.Marshal(map[string]interface{}{"Replace": replace})
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(tmp, "overlay.json")
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// Exec 在 dir 下运行 bin，并收集结果。非零退出不算错误，体现在 Result.ExitCode 中。
+func Exec(ctx context.Context, dir, bin string, args []string, opt Options) (*Result, error) {
+	if opt.Stdout != nil && args != nil {
+		dp := &dropPass{w: opt.Stdout}
+		defer dp.flush()
+		opt.Stdout = dp
+	}
+	if opt.Sandbox != nil {
+		if opt.Combined {
+			return nil, errors.New("runner: Combined output is not supported in the sandbox")
+		}
+		return execSandbox(ctx, dir, bin, args, opt)
+	}
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), opt.Env...)
+	cmd.Stdout = tee(&stdout, opt.Stdout)
+	cmd.Stderr = tee(&stderr, opt.Stderr)
+	if opt.Combined {
+		cmd.Stderr = cmd.Stdout // 同一个 Writer，os/exec 只创建一个管道
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	res := &Result{Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case ctx.Err() == context.DeadlineExceeded:
+		res.TimedOut = true
+		res.ExitCode = -1
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	default:
+		return nil, err
+	}
+	res.Stdout = trimPass(stdout.Bytes())
+	res.Stderr = stderr.Bytes()
+	return res, nil
+}
+
+// trimPass 去掉测试二进制在结尾打印的 PASS 行。
+func trimPass(b []byte) []byte {
+	if bytes.HasSuffix(b, []byte("PASS\n")) {
+		rest := b[:len(b)-len("PASS\n")]
+		if len(rest) == 0 || rest[len(rest)-1] == '\n' {
+			return rest
+		}
+	}
+	return b
+}
+
+func execSandbox(ctx context.Context, dir, bin string, args []string, opt Options) (*Result, error) {
+	lim := *opt.Sandbox
+	if opt.Timeout > 0 {
+		lim.WallTime = opt.Timeout
+	}
+	files, err := Fixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := sandbox.Run(ctx, &sandbox.Cmd{
+		Path:   bin,
+		Args:   args,
+		Files:  files,
+		Env:    opt.Env,
+		Stdout: opt.Stdout,
+		Stderr: opt.Stderr,
+		Limits: lim,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Stdout:    trimPass(sr.Stdout),
+		Stderr:    sr.Stderr,
+		ExitCode:  sr.ExitCode,
+		Duration:  sr.Duration,
+		TimedOut:  sr.TimedOut,
+		Signal:    sr.Signal,
+		Truncated: sr.Truncated,
+	}, nil
+}
+
+// Fixtures 返回 dir 中被 Go 源码以字符串字面量提到的文件，例如示例打开的 1.txt，
+// 键是相对 dir 的路径。在沙箱中运行时只复制这些文件。
+func Fixtures(dir string) (map[string]string, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, name := range names {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var s scanner.Scanner
+		s.Init(token.NewFileSet().AddFile(name, -1, len(src)), src, nil, 0)
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if tok != token.STRING {
+				continue
+			}
+			v, err := strconv.Unquote(lit)
+			if err != nil || v == "" || filepath.IsAbs(v) || strings.HasSuffix(v, ".go") {
+				continue
+			}
+			rel := filepath.Clean(filepath.FromSlash(v))
+			if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			path := filepath.Join(dir, rel)
+			if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+				files[filepath.ToSlash(rel)] = path
+			}
+		}
+	}
+	return files, nil
+}
+
+func tee(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, w)
+}
+
+// dropPass 按行转发输出，去掉测试二进制打印的 PASS 行。
+type dropPass struct {
+	w    io.Writer
+	line []byte
+}
+
+func (d *dropPass) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			d.line = append(d.line, p...)
+			break
+		}
+		d.line = append(d.line, p[:i+1]...)
+		p = p[i+1:]
+		if string(d.line) != "PASS\n" {
+			d.w.Write(d.line)
+		}
+		d.line = d.line[:0]
+	}
+	return n, nil
+}
+
+func (d *dropPass) flush() {
+	if len(d.line) > 0 {
+		d.w.Write(d.line)
+	}
+}
@@ -0,0 +1,112 @@
+// Package sandbox 在受限的子进程中运行学员的代码。
+//
+// 子进程有 CPU 时间、地址空间（RLIMIT_AS）、进程数、输出大小和墙钟时间的限制，
+// 在私有的临时目录中运行，环境变量只保留最少的几个，没有网络。
+//
+// 以 root 运行时，每次运行各用一个临时的用户，写不了仓库，RLIMIT_NPROC 也才有效；
+// 子进程在自己的 PID 和挂载命名空间中运行，/tmp、/var/tmp 和 /dev/shm 是空的 tmpfs，
+// 看不到也改不了其他运行的进程和文件，结束时命名空间中的进程全部被杀掉。
+// Builder 命令（编译学员的代码）共用一个编译用户和它的文件，例如 go 的编译缓存，其他命令改不了它们。
+//
+// 不以 root 运行时，子进程和调用者是同一个用户，只有资源限制、网络隔离和私有的工作目录：
+// 它能读写调用者能读写的所有文件，包括仓库、编译缓存和其他运行的临时目录，
+// 也能向其他运行的进程发信号。在多人共用的机器上提供服务时要以 root 运行（例如在容器中）。
+//
+// rlimit 由一个辅助进程设置：Run 先以特殊的环境变量重新启动当前可执行文件，
+// 辅助进程在 init 中设置好 rlimit、降低权限后再 exec 目标程序，所以导入本包的程序都能使用 Run。
+package sandbox
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrUnsupported 当前平台不支持沙箱。
+var ErrUnsupported = errors.New("sandbox: not supported on this platform")
+
+// Limits 资源限制，值为 0 的项不限制。
+type Limits struct {
+	CPUTime  time.Duration // RLIMIT_CPU，超过后被 SIGKILL
+	Memory   int64         // RLIMIT_AS，字节
+	Procs    int           // RLIMIT_NPROC，按用户计算，包括线程；不以 root 运行时和调用者的其他进程共用
+	Output   int64         // stdout、stderr 各自最多保留的字节数，超出部分丢弃
+	WallTime time.Duration // 墙钟时间，超时后杀掉整个进程组
+	Network  bool          // 允许访问网络；默认在新的网络命名空间中运行
+}
+
+// DefaultLimits 运行一个示例或练习的默认限制。
+// Go 程序启动时就要保留几百 MB 的地址空间，Memory 不能设得太小。
+var DefaultLimits = Limits{
+	CPUTime:  10 * time.Second,
+	Memory:   1 << 30,
+	Procs:    256,
+	Output:   1 << 20,
+	WallTime: 15 * time.Second,
+}
+
+// Cmd 要在沙箱中运行的命令。
+type Cmd struct {
+	Path   string
+	Args   []string // 不包括 Path 本身
+	Env    []string // 追加在最小环境变量之后
+	Stdin  io.Reader
+	Stdout io.Writer // 可选，输出同时实时写到这里
+	Stderr io.Writer
+	Limits Limits
+
+	// Files 运行前复制到私有临时目录中的文件，键是目录中的相对路径，值是要复制的文件。
+	// 子进程在这个目录中运行，看不到调用者的工作目录。
+	Files map[string]string
+	Dir   string // 子进程的工作目录，是私有目录中的相对路径，默认是私有目录本身
+
+	// Builder 以编译用的用户运行，可以写 BuilderDir 交给它的目录；不使用私有的 /tmp，
+	// 也不在单独的 PID 命名空间中。只用来运行不执行学员代码的命令，例如 go build。
+	Builder bool
+}
+
+// Result 运行结果。
+type Result struct {
+	ExitCode    int    // 被信号杀死时为 -1
+	Signal      string // 杀死进程的信号
+	TimedOut    bool   // 超过 WallTime
+	CPUExceeded bool   // 超过 CPUTime
+	Truncated   bool   // 输出超过 Output 被截断
+	Isolated    bool   // 在独立的网络命名空间中运行
+	Stdout      []byte
+	Stderr      []byte
+	Duration    time.Duration
+	CPUTime     time.Duration
+}
+
+// OK 进程正常退出且退出码为 0。
+func (r *Result) OK() bool {
+	return r.ExitCode == 0 && !r.TimedOut && r.Signal == ""
+}
+
+// limitedBuffer 最多保留 max 个字节，超出的部分丢弃，但照样返回成功，子进程不会因此阻塞。
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int64
+	tee       io.Writer
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.max > 0 {
+		if room := b.max - int64(b.buf.Len()); int64(len(p)) > room {
+			if room < 0 {
+				room = 0
+			}
+			p = p[:room]
+			b.truncated = true
+		}
+	}
+	b.buf.Write(p)
+	if b.tee != nil && len(p) > 0 {
+		b.tee.Write(p)
+	}
+	return n, nil
+}
@@ -0,0 +1,450 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// helperEnv 辅助进程从这个环境变量读取配置。
+const helperEnv = "STUDY_SANDBOX_HELPER"
+
+// 以 root 运行时子进程使用的 uid 和 gid（两者相同）：Builder 命令用 builderUID，
+// 其他命令从 firstUID 开始各用一个。这些 uid 不对应任何账号，系统中没有属于它们的文件。
+const (
+	builderUID = 0x53540000
+	firstUID   = builderUID + 1
+	numUIDs    = 1 << 16
+)
+
+// privateDirs 以 root 运行时在子进程的挂载命名空间中换成空的 tmpfs 的目录。
+var privateDirs = []string{"/tmp", "/var/tmp", "/dev/shm"}
+
+// tmpfsSize 每个 tmpfs 的大小。
+const tmpfsSize = "64m"
+
+type helperConfig struct {
+	Path    string
+	Args    []string
+	Rlimits map[int]uint64
+	UID     int    // 不为 0 时降为这个用户后再 exec
+	Private bool   // 在新的挂载命名空间中换掉 privateDirs 和 /proc
+	Dir     string // 工作目录相对私有目录的路径，见 Cmd.Dir
+}
+
+var (
+	uidMu   sync.Mutex
+	uidUsed = map[int]bool{}
+	uidNext = os.Getpid() % numUIDs // 同时运行的几个进程从不同的地方开始分配
+)
+
+// allocUID 返回一个本进程中没有其他沙箱在用的 uid，用完后要调用 freeUID。
+func allocUID() int {
+	uidMu.Lock()
+	defer uidMu.Unlock()
+	for {
+		uid := firstUID + uidNext
+		uidNext = (uidNext + 1) % numUIDs
+		if !uidUsed[uid] {
+			uidUsed[uid] = true
+			return uid
+		}
+	}
+}
+
+func freeUID(uid int) {
+	uidMu.Lock()
+	delete(uidUsed, uid)
+	uidMu.Unlock()
+}
+
+func init() {
+	if cfg := os.Getenv(helperEnv); cfg != "" {
+		helper(cfg)
+	}
+}
+
+// helper 在辅助进程中设置 rlimit，需要时降低权限，然后 exec 目标程序，不会返回。
+func helper(data string) {
+	var cfg helperConfig
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		fatalf("bad config: %v", err)
+	}
+	os.Unsetenv(helperEnv)
+	for res, v := range cfg.Rlimits {
+		if err := syscall.Setrlimit(res, &syscall.Rlimit{Cur: v, Max: v}); err != nil {
+			fatalf("setrlimit %d: %v", res, err)
+		}
+	}
+	path := cfg.Path
+	if cfg.UID != 0 {
+		// 目标程序常在降低权限后进不去的目录里（例如 /root 下），先以 root 打开，
+		// 降低权限后通过 /proc/self/fd 执行。
+		fd, err := syscall.Open(cfg.Path, syscall.O_RDONLY, 0)
+		if err != nil {
+			fatalf("open %s: %v", cfg.Path, err)
+		}
+		if cfg.Private {
+			if err := private(cfg.Dir); err != nil {
+				fatalf("private mounts: %v", err)
+			}
+		}
+		if err := drop(cfg.UID); err != nil {
+			fatalf("drop privileges: %v", err)
+		}
+		path = "/proc/self/fd/" + strconv.Itoa(fd)
+	}
+	err := syscall.Exec(path, append([]string{cfg.Path}, cfg.Args...), os.Environ())
+	fatalf("exec %s: %v", cfg.Path, err)
+}
+
+// private 在辅助进程的挂载命名空间中把 privateDirs 换成空的 tmpfs，重新挂载 /proc，
+// 只看得到自己的 PID 命名空间。私有目录在 /tmp 下，先把它绑定到新的 /tmp 中的同一个路径；
+// 工作目录是私有目录中的 dir。
+func private(dir string) error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	top, up := wd, "."
+	if dir != "." {
+		top = strings.TrimSuffix(wd, string(filepath.Separator)+dir)
+		up = strings.Repeat("../", strings.Count(dir, string(filepath.Separator))+1)
+	}
+	for _, dir := range privateDirs {
+		if fi, err := os.Lstat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		if err := syscall.Mount("tmpfs", dir, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777,size="+tmpfsSize); err != nil {
+			return fmt.Errorf("%s: %v", dir, err)
+		}
+	}
+	// 挂载 tmpfs 后 "." 仍然是原来的工作目录
+	if err := os.MkdirAll(top, 0o700); err != nil {
+		return err
+	}
+	if err := syscall.Mount(up, top, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("bind %s: %v", top, err)
+	}
+	if err := syscall.Chdir(wd); err != nil {
+		return err
+	}
+	if err := syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("/proc: %v", err)
+	}
+	return nil
+}
+
+// drop 把辅助进程的所有线程降为 uid，gid 与 uid 相同。以后创建的文件只有自己能读写。
+// 权限变化会清除 Pdeathsig，需要重新设置。
+func drop(uid int) error {
+	if err := syscall.Setgroups(nil); err != nil {
+		return err
+	}
+	if err := syscall.Setgid(uid); err != nil {
+		return err
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return err
+	}
+	syscall.Umask(0o077)
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_SET_PDEATHSIG, uintptr(syscall.SIGKILL), 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "sandbox: "+format+"\n", args...)
+	os.Exit(127)
+}
+
+// Run 在沙箱中运行 c，等待结束后返回结果。进程以非零状态退出或被杀死都不算错误。
+func Run(ctx context.Context, c *Cmd) (*Result, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "study-sandbox-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyFiles(tmp, c.Files); err != nil {
+		return nil, err
+	}
+
+	lim := c.Limits
+	cfg := helperConfig{Path: c.Path, Args: c.Args, Rlimits: map[int]uint64{}, Dir: "."}
+	if c.Dir != "" {
+		if cfg.Dir, err = inside(c.Dir); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Join(tmp, cfg.Dir), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	if os.Getuid() == 0 {
+		cfg.UID = builderUID
+		if !c.Builder {
+			cfg.UID = allocUID()
+			defer freeUID(cfg.UID)
+			cfg.Private = true
+		}
+		if err := chownAll(tmp, cfg.UID); err != nil {
+			return nil, err
+		}
+	}
+	if lim.CPUTime > 0 {
+		cfg.Rlimits[syscall.RLIMIT_CPU] = uint64((lim.CPUTime + time.Second - 1) / time.Second)
+	}
+	if lim.Memory > 0 {
+		cfg.Rlimits[syscall.RLIMIT_AS] = uint64(lim.Memory)
+	}
+	if lim.Procs > 0 {
+		cfg.Rlimits[rlimitNproc] = uint64(lim.Procs)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if lim.WallTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lim.WallTime)
+		defer cancel()
+	}
+
+	stdout := &limitedBuffer{max: lim.Output, tee: c.Stdout}
+	stderr := &limitedBuffer{max: lim.Output, tee: c.Stderr}
+	cmd := exec.Command(self)
+	cmd.Args = []string{"study-sandbox"}
+	cmd.Dir = filepath.Join(tmp, cfg.Dir)
+	cmd.Env = append([]string{
+		helperEnv + "=" + string(data),
+		"PATH=/usr/local/bin:/usr/bin:/bin",
+		"HOME=" + tmp,
+		"TMPDIR=" + tmp,
+		"LANG=C.UTF-8",
+	}, c.Env...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	if !lim.Network {
+		isolate(cmd.SysProcAttr)
+	}
+	if cfg.Private {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNS | syscall.CLONE_NEWPID
+	}
+
+	// 输入输出自己用管道转发：进程组以外的子进程可能一直开着输出管道，
+	// 不能像 exec.Cmd 那样等到管道关闭。
+	var files []*os.File // 子进程一端的管道，启动后关闭
+	pipe := func() (r, w *os.File, err error) {
+		r, w, err = os.Pipe()
+		if err == nil {
+			files = append(files, r, w)
+		}
+		return r, w, err
+	}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	outR, outW, err := pipe()
+	if err != nil {
+		return nil, err
+	}
+	errR, errW, err := pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout, cmd.Stderr = outW, errW
+	var inW *os.File
+	if c.Stdin != nil {
+		var inR *os.File
+		if inR, inW, err = pipe(); err != nil {
+			return nil, err
+		}
+		cmd.Stdin = inR
+	}
+
+	start := time.Now()
+	err = cmd.Start()
+	if err != nil {
+		if cmd.SysProcAttr.Cloneflags != 0 {
+			return nil, fmt.Errorf("sandbox: cannot create namespaces: %v", err)
+		}
+		return nil, err
+	}
+	outW.Close()
+	errW.Close()
+	if inW != nil {
+		cmd.Stdin.(*os.File).Close()
+		go func() {
+			io.Copy(inW, c.Stdin)
+			inW.Close()
+		}()
+	}
+	copied := make(chan struct{}, 2)
+	for _, p := range []struct {
+		r *os.File
+		w io.Writer
+	}{{outR, stdout}, {errR, stderr}} {
+		go func(r *os.File, w io.Writer) {
+			io.Copy(w, r)
+			copied <- struct{}{}
+		}(p.r, p.w)
+	}
+
+	// 超时或取消时杀掉整个进程组
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		case <-exited:
+		}
+	}()
+	err = cmd.Wait()
+	close(exited)
+	duration := time.Since(start)
+	// 进程组里可能还有子进程
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	// 进程组以外的子进程还开着输出管道时，最多再等一秒
+	timeout := time.After(time.Second)
+	for n := 0; n < 2; n++ {
+		select {
+		case <-copied:
+		case <-timeout:
+			outR.Close()
+			errR.Close()
+			<-copied
+		}
+	}
+	res := &Result{
+		Duration:  duration,
+		Isolated:  !lim.Network,
+		Stdout:    stdout.buf.Bytes(),
+		Stderr:    stderr.buf.Bytes(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+	ps := cmd.ProcessState
+	res.CPUTime = ps.UserTime() + ps.SystemTime()
+	res.ExitCode = ps.ExitCode()
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		res.Signal = ws.Signal().String()
+	}
+	res.TimedOut = ctx.Err() == context.DeadlineExceeded
+	res.CPUExceeded = lim.CPUTime > 0 && res.Signal != "" && res.CPUTime >= lim.CPUTime-100*time.Millisecond
+	return res, nil
+}
+
+// inside 返回私有目录中的相对路径 name 整理后的结果，name 在私有目录以外时返回错误。
+func inside(name string) (string, error) {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("sandbox: %s is outside the sandbox directory", name)
+	}
+	return name, nil
+}
+
+// copyFiles 把 files 复制到 dir 中。
+func copyFiles(dir string, files map[string]string) error {
+	for name, src := range files {
+		name, err := inside(name)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := copyFile(dst, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// BuilderDir 让 Builder 命令可以写 path 及其中的文件，其他命令改不了它们：以 root 运行时
+// 把它们交给编译用的用户，path 本身只有这个用户能访问；已经属于这个用户时什么也不做。
+// 不以 root 运行时所有命令和调用者是同一个用户，什么也不用做，也就没有这样的保证。
+func BuilderDir(path string) error {
+	if os.Getuid() != 0 {
+		return nil
+	}
+	var st syscall.Stat_t
+	if err := syscall.Lstat(path, &st); err != nil {
+		return err
+	}
+	if st.Mode&syscall.S_IFMT != syscall.S_IFDIR {
+		return fmt.Errorf("sandbox: %s is not a directory", path)
+	}
+	if int(st.Uid) == builderUID && st.Mode&0o077 == 0 {
+		return nil
+	}
+	if err := chownAll(path, builderUID); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o700)
+}
+
+// chownAll 把 path 及其中的文件交给 uid。
+func chownAll(path string, uid int) error {
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, uid, uid)
+	})
+}
+
+// isolate 让子进程运行在新的网络命名空间中，里面只有一个没有启用的 lo，访问不了网络。
+// 非 root 用户需要同时创建用户命名空间。
+func isolate(attr *syscall.SysProcAttr) {
+	attr.Cloneflags = syscall.CLONE_NEWNET
+	if uid := os.Getuid(); uid != 0 {
+		gid := os.Getgid()
+		attr.Cloneflags |= syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+	}
+}
+
+// rlimitNproc syscall 包没有导出 RLIMIT_NPROC。
+const rlimitNproc = 6
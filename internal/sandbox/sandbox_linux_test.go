@@ -0,0 +1,192 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sh(script string, lim Limits) *Cmd {
+	return &Cmd{Path: "/bin/sh", Args: []string{"-c", script}, Limits: lim}
+}
+
+func run(t *testing.T, c *Cmd) *Result {
+	t.Helper()
+	res, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestExitCode(t *testing.T) {
+	res := run(t, sh("echo out; echo err >&2; exit 3", DefaultLimits))
+	if res.ExitCode != 3 || string(res.Stdout) != "out\n" || string(res.Stderr) != "err\n" {
+		t.Errorf("got exit %d, stdout %q, stderr %q", res.ExitCode, res.Stdout, res.Stderr)
+	}
+}
+
+func TestWallTime(t *testing.T) {
+	res := run(t, sh("sleep 10", Limits{WallTime: 200 * time.Millisecond}))
+	if !res.TimedOut || res.Duration > 5*time.Second {
+		t.Errorf("got %+v, want timed out quickly", res)
+	}
+}
+
+func TestCPUTime(t *testing.T) {
+	res := run(t, sh("while :; do :; done", Limits{CPUTime: time.Second, WallTime: 10 * time.Second}))
+	if !res.CPUExceeded || res.TimedOut || res.Signal == "" {
+		t.Errorf("got %+v, want killed for exceeding the CPU limit", res)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	res := run(t, sh("yes | head -c 100000", Limits{Output: 1000}))
+	if !res.Truncated || len(res.Stdout) != 1000 || !res.OK() {
+		t.Errorf("got %d bytes, truncated %v, want 1000 and truncated", len(res.Stdout), res.Truncated)
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	res := run(t, sh("cat /proc/net/dev", DefaultLimits))
+	if !res.Isolated {
+		t.Fatal("not isolated")
+	}
+	for _, line := range strings.Split(string(res.Stdout), "\n") {
+		name := strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+		if strings.Contains(line, ":") && name != "lo" {
+			t.Errorf("network interface %q is visible in the sandbox", name)
+		}
+	}
+}
+
+func TestTempDir(t *testing.T) {
+	res := run(t, sh(`pwd; echo "$HOME"; echo "$SECRET"`, DefaultLimits))
+	lines := strings.Split(string(res.Stdout), "\n")
+	if len(lines) < 3 || !strings.Contains(lines[0], "study-sandbox-") || lines[0] != lines[1] || lines[2] != "" {
+		t.Errorf("got %q, want a private temp dir as cwd and HOME and no inherited env", res.Stdout)
+	}
+	if _, err := os.Stat(lines[0]); !os.IsNotExist(err) {
+		t.Errorf("temp dir %s was not removed", lines[0])
+	}
+}
+
+func TestProcs(t *testing.T) {
+	res := run(t, sh("for i in $(seq 50); do sleep 1 & done; wait", Limits{Procs: 10, WallTime: 10 * time.Second}))
+	if res.OK() || !strings.Contains(strings.ToLower(string(res.Stderr)), "fork") {
+		t.Errorf("got exit %d, stderr %q, want fork to fail after 10 processes", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 测试二进制在沙箱中以 allocEnv 启动时分配 2 GB，见 TestMain
+	res := run(t, &Cmd{Path: self, Env: []string{allocEnv + "=1"}, Limits: Limits{Memory: DefaultLimits.Memory, WallTime: 10 * time.Second}})
+	if res.OK() || !strings.Contains(string(res.Stderr), "out of memory") {
+		t.Errorf("got exit %d, stderr %.200q, want out of memory", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "1.txt")
+	if err := os.WriteFile(src, []byte("fixture\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := sh(`cat data/1.txt; echo x >> data/1.txt; echo x > "$DIR/new" && echo wrote outside`, DefaultLimits)
+	c.Files = map[string]string{"data/1.txt": src}
+	c.Env = []string{"DIR=" + dir}
+	res := run(t, c)
+	if string(res.Stdout) != "fixture\n" {
+		t.Errorf("got stdout %q, want the copied fixture and no write outside the sandbox", res.Stdout)
+	}
+	if data, _ := os.ReadFile(src); string(data) != "fixture\n" {
+		t.Errorf("the original fixture was changed to %q", data)
+	}
+	if os.Getuid() != 0 {
+		t.Skip("only root drops privileges, the caller's directories stay writable")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+		t.Errorf("the sandbox wrote into %s", dir)
+	}
+}
+
+func TestUser(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("not running as root")
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		res := run(t, sh("id -u; id -g", DefaultLimits))
+		ids := strings.Fields(string(res.Stdout))
+		if len(ids) != 2 || ids[0] != ids[1] || ids[0] == "0" || ids[0] == strconv.Itoa(builderUID) || seen[ids[0]] {
+			t.Errorf("run %d got uid and gid %q, want a new user for every run", i, res.Stdout)
+		}
+		if len(ids) > 0 {
+			seen[ids[0]] = true
+		}
+	}
+
+	// t.TempDir 的上一级只有 root 能进入
+	dir, err := os.MkdirTemp("", "sandbox-builder-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := BuilderDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm() != 0o700 {
+		t.Errorf("builder dir mode = %v, %v, want 0700", fi.Mode(), err)
+	}
+	c := sh(`id -u; echo x > "$DIR/entry" && echo wrote`, DefaultLimits)
+	c.Env = []string{"DIR=" + dir}
+	c.Builder = true
+	if got, want := string(run(t, c).Stdout), strconv.Itoa(builderUID)+"\nwrote\n"; got != want {
+		t.Errorf("builder got %q, want %q", got, want)
+	}
+}
+
+// TestPrivate 同时运行的两个命令看不到彼此的 /tmp 和进程。
+func TestPrivate(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("only root gets private namespaces")
+	}
+	done := make(chan *Result)
+	go func() {
+		res, _ := Run(context.Background(), sh("echo secret > /tmp/leak; sleep 3", DefaultLimits))
+		done <- res
+	}()
+	time.Sleep(500 * time.Millisecond)
+	res := run(t, sh("ls -A /tmp; cat /tmp/leak; ls /proc | grep -c '^[0-9]'", DefaultLimits))
+	lines := strings.Split(strings.TrimSpace(string(res.Stdout)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "study-sandbox-") {
+		t.Errorf("got %q, want only the own temp dir in /tmp", res.Stdout)
+	}
+	if n, _ := strconv.Atoi(lines[len(lines)-1]); n == 0 || n > 4 {
+		t.Errorf("%s processes are visible, want only the sandbox's own", lines[len(lines)-1])
+	}
+	<-done
+}
+
+// allocEnv 让测试二进制在 TestMemory 中只分配内存。
+const allocEnv = "SANDBOX_TEST_ALLOC"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(allocEnv) != "" {
+		b := make([]byte, 2<<30)
+		for i := 0; i < len(b); i += 4096 {
+			b[i] = 1
+		}
+		os.Exit(0)
+	}
+	os.Setenv("SECRET", "leaked")
+	os.Exit(m.Run())
+}
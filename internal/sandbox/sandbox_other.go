@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package sandbox
+
+import "context"
+
+// Run 在当前平台上不可用。
+func Run(ctx context.Context, c *Cmd) (*Result, error) {
+	return nil, ErrUnsupported
+}
+
+// BuilderDir 在当前平台上什么也不做。
+func BuilderDir(path string) error {
+	return nil
+}
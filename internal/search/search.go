@@ -0,0 +1,322 @@
+// Package search 是课程的全文检索：示例名和标题、代码中的标识符、说明注释、
+// 课程包中的 Markdown 文档和讲义幻灯片的文字。分词见 Tokens，排序使用 BM25。
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/pptx"
+)
+
+// Doc 一个检索单位：一个示例（连同它上方的注释）、没有示例的源文件、文档中的一节或一页幻灯片。
+type Doc struct {
+	Name  string // c3/5.slice/Test_S9、c4/2.map/map.md
+	Title string
+	File  string // 相对仓库根目录
+	Line  int    // 没有哪一行匹配时（只有标题匹配）显示的行号，幻灯片为 0
+	Slide int    // 幻灯片的页码
+	Lines []Line
+
+	freq map[string]int
+	size int
+}
+
+// Line 文档中的一行。
+type Line struct {
+	Num    int
+	Text   string
+	tokens map[string]bool
+}
+
+// location 返回 文件:行号，幻灯片返回 文件 slide N。
+func (d *Doc) location(line int) string {
+	if d.Slide > 0 {
+		return fmt.Sprintf("%s slide %d", d.File, d.Slide)
+	}
+	return fmt.Sprintf("%s:%d", d.File, line)
+}
+
+// titleWeight 标题和名字中的词按出现这么多次计算。
+const titleWeight = 3
+
+// Index 检索索引。
+type Index struct {
+	Docs []*Doc
+	df   map[string]int
+	size int // 所有文档的词数
+}
+
+// NewIndex 返回一个空索引。
+func NewIndex() *Index {
+	return &Index{df: map[string]int{}}
+}
+
+// Add 把文档加入索引。
+func (ix *Index) Add(d *Doc) {
+	d.freq = map[string]int{}
+	for _, tok := range indexTokens(d.Name + " " + d.Title) {
+		d.freq[tok] += titleWeight
+	}
+	for i := range d.Lines {
+		l := &d.Lines[i]
+		l.tokens = map[string]bool{}
+		for _, tok := range indexTokens(l.Text) {
+			d.freq[tok]++
+			l.tokens[tok] = true
+		}
+	}
+	for tok, n := range d.freq {
+		ix.df[tok]++
+		d.size += n
+	}
+	ix.size += d.size
+	ix.Docs = append(ix.Docs, d)
+}
+
+// Build 为课程建立索引。
+func Build(c *course.Course) (*Index, error) {
+	ix := NewIndex()
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			if err := ix.addPackage(c.Root, p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	decks, err := pptx.Load(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range decks {
+		for _, s := range d.Slides {
+			doc := &Doc{Name: path.Base(d.Path), Title: s.Title, File: d.Path, Slide: s.Number}
+			for _, text := range strings.Split(s.Text(), "\n") {
+				doc.Lines = append(doc.Lines, Line{Text: text})
+			}
+			ix.Add(doc)
+		}
+	}
+	return ix, nil
+}
+
+// addPackage 把每个示例连同上方的注释作为一个文档。包中有示例时，
+// 没有示例的文件（例如 lessons.go）不加入，示例的标题已经在文档的 Title 中。
+func (ix *Index) addPackage(root string, p *course.Package) error {
+	for _, name := range p.Files {
+		lines, err := readLines(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		from := 1
+		for _, l := range p.Lessons {
+			if l.File != name {
+				continue
+			}
+			ix.Add(&Doc{Name: l.Path(), Title: l.Title, File: name, Line: l.Line, Lines: lines[from-1 : l.EndLine]})
+			from = l.EndLine + 1
+		}
+		switch {
+		case from == 1 && len(p.Lessons) == 0:
+			ix.Add(&Doc{Name: name, File: name, Line: 1, Lines: lines})
+		case from > 1 && from <= len(lines):
+			// 最后一个示例后面的注释，例如 map_test.go 结尾的 map 原理
+			ix.Add(&Doc{Name: name, File: name, Line: from, Lines: lines[from-1:]})
+		}
+	}
+
+	dir := filepath.Join(root, filepath.FromSlash(p.Dir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		lines, err := readLines(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		ix.addMarkdown(path.Join(p.Dir, e.Name()), lines)
+	}
+	return nil
+}
+
+// addMarkdown 按标题把 Markdown 文档分成几节。
+func (ix *Index) addMarkdown(name string, lines []Line) {
+	var doc *Doc
+	for _, l := range lines {
+		if strings.HasPrefix(l.Text, "#") || doc == nil {
+			if doc != nil {
+				ix.Add(doc)
+			}
+			doc = &Doc{Name: name, Title: strings.TrimSpace(strings.TrimLeft(l.Text, "#")), File: name, Line: l.Num}
+			continue
+		}
+		doc.Lines = append(doc.Lines, l)
+	}
+	if doc != nil {
+		ix.Add(doc)
+	}
+}
+
+func readLines(name string) ([]Line, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []Line
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, Line{Num: len(lines) + 1, Text: strings.TrimSpace(sc.Text())})
+	}
+	return lines, sc.Err()
+}
+
+// Result 一条检索结果。
+type Result struct {
+	Doc      *Doc
+	Location string // 匹配最好的一行，例如 c3/5.slice/slice_test.go:168
+	Text     string // 这一行的文字，只有标题匹配时为标题
+	Score    float64
+}
+
+// BM25 的参数。
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// synonymWeight 同义词匹配的得分按原词的这个比例计算。
+const synonymWeight = 0.7
+
+// alternative 查询中一个词的一种说法。
+type alternative struct {
+	tokens []string
+	weight float64
+}
+
+// Search 返回和 query 最相关的至多 n 个结果。query 中用空格分开的每个词
+// 都单独计分，一个词的所有切分结果都出现时才算匹配；匹配的词越多排得越前。
+func (ix *Index) Search(query string, n int) []*Result {
+	var terms [][]alternative
+	for _, word := range strings.Fields(query) {
+		alts := []alternative{{uniq(Tokens(word)), 1}}
+		for _, syn := range Synonyms(word) {
+			alts = append(alts, alternative{uniq(Tokens(syn)), synonymWeight})
+		}
+		if len(alts[0].tokens) > 0 {
+			terms = append(terms, alts)
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []*Result
+	for _, d := range ix.Docs {
+		score, matched := 0.0, 0
+		for _, alts := range terms {
+			best := 0.0
+			for _, a := range alts {
+				// 按词数折算，切分出更多词的同义词不会比原词得分高
+				scale := a.weight * float64(len(alts[0].tokens)) / float64(len(a.tokens))
+				if s := scale * ix.score(d, a.tokens); s > best {
+					best = s
+				}
+			}
+			if best > 0 {
+				score += best
+				matched++
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+		score *= float64(matched) / float64(len(terms))
+		line, text := d.Line, d.Title
+		if l := bestLine(d, terms); l != nil {
+			line, text = l.Num, l.Text
+		}
+		results = append(results, &Result{Doc: d, Location: d.location(line), Text: text, Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Location < results[j].Location
+	})
+	if len(results) > n {
+		results = results[:n]
+	}
+	return results
+}
+
+// score 计算 tokens 在 d 中的 BM25 得分，有一个词不在 d 中时为 0。
+func (ix *Index) score(d *Doc, tokens []string) float64 {
+	avg := float64(ix.size) / float64(len(ix.Docs))
+	total := 0.0
+	for _, tok := range tokens {
+		tf := float64(d.freq[tok])
+		if tf == 0 {
+			return 0
+		}
+		df := float64(ix.df[tok])
+		idf := math.Log(1 + (float64(len(ix.Docs))-df+0.5)/(df+0.5))
+		total += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(d.size)/avg))
+	}
+	return total
+}
+
+// bestLine 返回匹配查询词最多的一行，都不匹配时返回 nil。
+func bestLine(d *Doc, terms [][]alternative) *Line {
+	var best *Line
+	bestScore := 0.0
+	for i := range d.Lines {
+		l := &d.Lines[i]
+		score := 0.0
+		for _, alts := range terms {
+			s := 0.0
+			for _, a := range alts {
+				if a.weight > s && containsAll(l.tokens, a.tokens) {
+					s = a.weight
+				}
+			}
+			score += s
+		}
+		if score > bestScore {
+			best, bestScore = l, score
+		}
+	}
+	return best
+}
+
+func containsAll(set map[string]bool, tokens []string) bool {
+	for _, tok := range tokens {
+		if !set[tok] {
+			return false
+		}
+	}
+	return true
+}
+
+func uniq(tokens []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, tok := range tokens {
+		if !seen[tok] {
+			seen[tok] = true
+			out = append(out, tok)
+		}
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+func TestTokens(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want []string
+	}{
+		{"扩容迁移", []string{"扩容", "容迁", "迁移"}},
+		{"map的扩容", []string{"map", "的扩", "扩容"}},
+		{"锁", []string{"锁"}},
+		{"h.sameSizeGrow()", []string{"h", "samesizegrow", "same", "size", "grow"}},
+		{"Test_S9 // 超出 cap", []string{"test_s9", "test", "s9", "超出", "cap"}},
+	} {
+		if got := Tokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokens(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func build(t *testing.T) *Index {
+	t.Helper()
+	c := coursetest.Load(t)
+	ix, err := Build(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ix
+}
+
+func TestSearch(t *testing.T) {
+	ix := build(t)
+
+	// 扩容：map.md 中扩容一节排在最前，只写了 “重新分配底层数组” 的 Test_S9 通过同义词找到
+	results := ix.Search("扩容", 10)
+	if len(results) == 0 || results[0].Doc.Title != "5. 扩容 和 迁移" || results[0].Location != "c4/2.map/map.md:100" {
+		t.Fatalf("Search(扩容)[0] = %+v", results[0])
+	}
+	found := false
+	for _, r := range results {
+		if r.Doc.Name == "c3/5.slice/Test_S9" {
+			found = true
+			if r.Location != "c3/5.slice/slice_test.go:168" {
+				t.Errorf("Test_S9 location = %s, want the comment above it", r.Location)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Search(扩容) does not find Test_S9")
+	}
+
+	for _, tt := range []struct{ query, name string }{
+		{"Test_S9", "c3/5.slice/Test_S9"},
+		{"sameSizeGrow", "c4/2.map/map.md"},
+		{"select 多路复用", "c6/3.concurrencyControl/TestC1"},
+		{"闭包", "c4/1.function/Test_F7"},
+	} {
+		results := ix.Search(tt.query, 1)
+		if len(results) == 0 || results[0].Doc.Name != tt.name {
+			t.Errorf("Search(%q) = %v, want %s first", tt.query, results, tt.name)
+		}
+	}
+
+	// 幻灯片
+	results = ix.Search("协程", 20)
+	slide := false
+	for _, r := range results {
+		slide = slide || r.Doc.Slide > 0
+	}
+	if !slide {
+		t.Errorf("Search(协程) does not find any slide")
+	}
+	if got := ix.Search("没有这个词", 10); len(got) != 0 {
+		t.Errorf("Search(没有这个词) = %v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tokens 把文字切分成检索用的词。
+//
+// 中文没有空格分词，连续的汉字按相邻两个字切分（“扩容迁移” 切成 扩容、容迁、迁移），
+// 只有一个字时保留这个字。英文和代码按标识符切分并转成小写，驼峰和下划线连接的
+// 标识符同时保留整体和各部分，例如 sameSizeGrow 切成 samesizegrow、same、size、grow。
+func Tokens(text string) []string {
+	return tokenize(text, false)
+}
+
+// indexTokens 建索引用的切分，每个汉字也单独保留，检索一个字（例如 锁）时也能找到。
+func indexTokens(text string) []string {
+	return tokenize(text, true)
+}
+
+func tokenize(text string, unigrams bool) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			j := i
+			for j < len(runes) && unicode.Is(unicode.Han, runes[j]) {
+				j++
+			}
+			if j-i == 1 || unigrams {
+				for k := i; k < j; k++ {
+					tokens = append(tokens, string(runes[k]))
+				}
+			}
+			for k := i; k+1 < j; k++ {
+				tokens = append(tokens, string(runes[k:k+2]))
+			}
+			i = j
+		case isWord(r):
+			j := i
+			for j < len(runes) && (isWord(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, identTokens(string(runes[i:j]))...)
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isWord(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// identTokens 切分一个标识符。
+func identTokens(id string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i <= len(id); i++ {
+		if i == len(id) || id[i] == '_' ||
+			unicode.IsUpper(rune(id[i])) && unicode.IsLower(rune(id[i-1])) {
+			if part := strings.Trim(id[start:i], "_"); part != "" {
+				parts = append(parts, strings.ToLower(part))
+			}
+			start = i
+		}
+	}
+	whole := strings.ToLower(strings.Trim(id, "_"))
+	if len(parts) <= 1 {
+		return []string{whole}
+	}
+	return append([]string{whole}, parts...)
+}
+
+// synonyms 课程中同一个概念的不同说法。检索 扩容 时，
+// 只写了 “重新分配底层数组” 的示例也能找到。
+var synonyms = map[string][]string{
+	"扩容":  {"重新分配", "grow", "growslice"},
+	"协程":  {"goroutine"},
+	"通道":  {"channel", "chan"},
+	"管道":  {"channel", "chan"},
+	"切片":  {"slice"},
+	"数组":  {"array"},
+	"指针":  {"pointer"},
+	"结构体": {"struct"},
+	"接口":  {"interface"},
+	"闭包":  {"closure"},
+	"映射":  {"map"},
+	"哈希表": {"map", "hmap"},
+	"方法":  {"method"},
+	"函数":  {"func", "function"},
+	"延迟":  {"defer"},
+	"并发":  {"goroutine", "concurrency"},
+	"锁":   {"mutex", "lock"},
+	"互斥锁": {"mutex"},
+}
+
+// reverse 英文到中文的同义词，由 synonyms 生成。
+var reverse = map[string][]string{}
+
+func init() {
+	for zh, words := range synonyms {
+		for _, w := range words {
+			reverse[w] = append(reverse[w], zh)
+		}
+	}
+	for _, zh := range reverse {
+		sort.Strings(zh)
+	}
+}
+
+// Synonyms 返回 term 的同义词。
+func Synonyms(term string) []string {
+	if s, ok := synonyms[term]; ok {
+		return s
+	}
+	return reverse[strings.ToLower(term)]
+}
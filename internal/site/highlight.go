@@ -0,0 +1,64 @@
+package site
+
+import (
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// Highlight 用 go/scanner 给 Go 代码加上语法高亮的 <span>，不要求代码能编译，
+// 讲义中的伪代码也能处理。
+func Highlight(src string) template.HTML {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), func(token.Position, string) {}, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // 自动插入的分号，源码中没有
+		}
+		off := file.Offset(pos)
+		n := len(lit)
+		if n == 0 {
+			n = len(tok.String())
+		}
+		if off < last || off+n > len(src) {
+			continue
+		}
+		class := tokenClass(tok, lit)
+		if class == "" {
+			continue // 和前后的普通文字一起输出
+		}
+		b.WriteString(html.EscapeString(src[last:off]))
+		b.WriteString(`<span class="` + class + `">` + html.EscapeString(src[off:off+n]) + `</span>`)
+		last = off + n
+	}
+	b.WriteString(html.EscapeString(src[last:]))
+	return template.HTML(b.String())
+}
+
+func tokenClass(tok token.Token, lit string) string {
+	switch {
+	case tok.IsKeyword():
+		return "kw"
+	case tok == token.COMMENT:
+		return "com"
+	case tok == token.STRING, tok == token.CHAR:
+		return "str"
+	case tok == token.INT, tok == token.FLOAT, tok == token.IMAG:
+		return "num"
+	case tok == token.IDENT && types.Universe.Lookup(lit) != nil:
+		return "bi" // int、len、nil 等预声明的标识符
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package site
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown 把 README.md、map.md 这类文档转成 HTML。只支持仓库里用到的写法：
+// 标题、段落、有序和无序列表（可以嵌套）、代码块、图片、链接、行内代码、粗体和 2^B^ 上标。
+// image 把图片地址转成页面中使用的地址，例如内嵌的 data: URL。
+func Markdown(text string, image func(src string) string) template.HTML {
+	m := &mdRenderer{image: image}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+		indent := indentOf(line)
+		switch {
+		case trimmed == "":
+			m.flush()
+		case strings.HasPrefix(trimmed, "```"):
+			m.flush()
+			if !m.inList(indent) {
+				m.closeLists(-1)
+			}
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, trimPrefixSpace(lines[i], indent))
+			}
+			m.code(strings.TrimPrefix(trimmed, "```"), strings.Join(code, "\n"))
+		case heading.MatchString(trimmed):
+			m.flush()
+			m.closeLists(-1)
+			h := heading.FindStringSubmatch(trimmed)
+			n := string(rune('0' + len(h[1])))
+			m.b.WriteString("<h" + n + ">" + m.inline(h[2]) + "</h" + n + ">\n")
+		case listItem.MatchString(line):
+			m.flush()
+			l := listItem.FindStringSubmatch(line)
+			tag, start := "ul", 1
+			if l[2] != "-" && l[2] != "*" && l[2] != "+" {
+				tag = "ol"
+				start, _ = strconv.Atoi(strings.TrimSuffix(l[2], "."))
+			}
+			m.item(indent, tag, start)
+			m.b.WriteString(m.inline(l[3]))
+		default:
+			if !m.inList(indent) {
+				m.flush()
+				m.closeLists(-1)
+			}
+			m.para = append(m.para, m.inline(trimmed))
+		}
+	}
+	m.flush()
+	m.closeLists(-1)
+	return template.HTML(m.b.String())
+}
+
+var (
+	heading  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listItem = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s+(.*)$`)
+	inlineRe = regexp.MustCompile("`([^`]+)`" +
+		`|!\[([^\]]*)\]\(([^)\s]+)\)` +
+		`|\[([^\]]+)\]\(([^)\s]+)\)` +
+		`|(https?://[^\s<>"，。；）)]+)` +
+		`|\*\*([^*]+)\*\*` +
+		`|\^([^^\s]+)\^`)
+)
+
+type mdList struct {
+	indent int
+	tag    string
+}
+
+type mdRenderer struct {
+	b     strings.Builder
+	image func(string) string
+	para  []string
+	lists []mdList
+}
+
+// inList 判断缩进为 indent 的一行是否属于当前列表项。
+func (m *mdRenderer) inList(indent int) bool {
+	return len(m.lists) > 0 && indent > m.lists[len(m.lists)-1].indent
+}
+
+func (m *mdRenderer) flush() {
+	if len(m.para) > 0 {
+		m.b.WriteString("<p>" + strings.Join(m.para, "<br>\n") + "</p>\n")
+		m.para = nil
+	}
+}
+
+// closeLists 关闭缩进大于 indent 的列表，indent 为 -1 时全部关闭。
+func (m *mdRenderer) closeLists(indent int) {
+	for len(m.lists) > 0 && m.lists[len(m.lists)-1].indent > indent {
+		m.b.WriteString("</li></" + m.lists[len(m.lists)-1].tag + ">\n")
+		m.lists = m.lists[:len(m.lists)-1]
+	}
+}
+
+// item 开始一个列表项，必要时先开始一个新列表。start 是有序列表第一项的编号。
+func (m *mdRenderer) item(indent int, tag string, start int) {
+	m.closeLists(indent)
+	if n := len(m.lists); n > 0 && m.lists[n-1].indent == indent {
+		if m.lists[n-1].tag == tag {
+			m.b.WriteString("</li>\n<li>")
+			return
+		}
+		m.closeLists(indent - 1)
+	}
+	m.lists = append(m.lists, mdList{indent, tag})
+	if start != 1 {
+		m.b.WriteString("<" + tag + ` start="` + strconv.Itoa(start) + `">` + "\n<li>")
+		return
+	}
+	m.b.WriteString("<" + tag + ">\n<li>")
+}
+
+// code 输出代码块。info 是 ``` 后面的内容，例如 go 或 go pseudo（见 snippet 包）。
+func (m *mdRenderer) code(info, code string) {
+	if f := strings.Fields(info); len(f) > 0 && f[0] == "go" {
+		m.b.WriteString(`<pre class="code">` + string(Highlight(code)) + "</pre>\n")
+		return
+	}
+	m.b.WriteString(`<pre class="text">` + html.EscapeString(code) + "</pre>\n")
+}
+
+func (m *mdRenderer) inline(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, g := range inlineRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(html.EscapeString(s[last:g[0]]))
+		last = g[1]
+		sub := func(i int) string {
+			if g[2*i] < 0 {
+				return ""
+			}
+			return s[g[2*i]:g[2*i+1]]
+		}
+		switch {
+		case g[2] >= 0:
+			b.WriteString("<code>" + html.EscapeString(sub(1)) + "</code>")
+		case g[6] >= 0:
+			src := sub(3)
+			if m.image != nil {
+				src = m.image(src)
+			}
+			b.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(sub(2)) + `">`)
+		case g[8] >= 0:
+			b.WriteString(`<a href="` + html.EscapeString(sub(5)) + `">` + html.EscapeString(sub(4)) + "</a>")
+		case g[12] >= 0:
+			b.WriteString(Autolink(sub(6)))
+		case g[14] >= 0:
+			b.WriteString("<b>" + html.EscapeString(sub(7)) + "</b>")
+		default:
+			b.WriteString("<sup>" + html.EscapeString(sub(8)) + "</sup>")
+		}
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
+
+var urlRe = regexp.MustCompile(`https?://[^\s<>"，。；）)]+`)
+
+// Autolink 转义 s，并把其中的网址变成链接。注释里的参考资料都是直接写的网址。
+func Autolink(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range urlRe.FindAllStringIndex(s, -1) {
+		u := html.EscapeString(s[loc[0]:loc[1]])
+		b.WriteString(html.EscapeString(s[last:loc[0]]))
+		b.WriteString(`<a href="` + u + `">` + u + "</a>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
+
+func indentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// trimPrefixSpace 去掉 line 开头最多 n 个空格，代码块跟着围栏的缩进一起缩进。
+func trimPrefixSpace(line string, n int) string {
+	i := 0
+	for i < len(line) && i < n && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
@@ -0,0 +1,411 @@
+// Package site 把整个课程生成为静态网站：首页是 README，每章一页，包含课程包里的 Markdown 文档、
+// 源码中的说明注释和高亮的示例代码。图片以 data: URL 内嵌，讲义复制到 assets/，
+// 不依赖任何外部资源，离线或放在只能托管静态文件的内网服务器上都能打开。
+package site
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"html/template"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/i18n"
+	"study/internal/lesson"
+	"study/internal/pptx"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(funcs(nil)).ParseFS(templateFS, "templates/*.html"))
+
+// funcs 返回模板函数，标题和页面上的文字按 t 的语言显示，t 为 nil 时只用于解析模板。
+func funcs(t *i18n.Texts) template.FuncMap {
+	return template.FuncMap{
+		"href":         Href,
+		"anchor":       anchor,
+		"chapter":      chapterOf,
+		"title":        func(l *course.Lesson) string { return t.Title(l) },
+		"chapterTitle": func(ch *course.Chapter) string { return t.ChapterTitle(ch) },
+		"lang":         func() string { return t.Lang },
+		"label": func(zh string, args ...interface{}) string {
+			if s, ok := labels[t.Lang][zh]; ok {
+				zh = s
+			}
+			return fmt.Sprintf(zh, args...)
+		},
+	}
+}
+
+// labels 页面上固定文字的译文，键是中文。
+var labels = map[string]map[string]string{
+	"en": {
+		"目录":     "Contents",
+		"章节":     "Chapters",
+		"讲义：":    "Slides: ",
+		"相关示例：":  "Related: ",
+		"练习":     "exercise",
+		"第 %d 页": "slide %d",
+		"zh-CN":  "en",
+	},
+}
+
+// maxRelated 每个示例最多列出的相关示例数。
+const maxRelated = 5
+
+// Href 返回示例在网站中的地址，例如 c3.html#c3-5.slice-Test_S9。
+func Href(l *course.Lesson) string {
+	return chapterOf(l.Package.Dir) + ".html#" + anchor(l.Path())
+}
+
+// anchor 把包目录或示例的路径转成页面内的锚点，/ 在锚点中会被转义，换成 -。
+func anchor(path string) string {
+	return strings.ReplaceAll(path, "/", "-")
+}
+
+func chapterOf(dir string) string {
+	if i := strings.Index(dir, "/"); i >= 0 {
+		return dir[:i]
+	}
+	return dir
+}
+
+// Build 把课程 c 生成到 dir，返回生成的页面（相对 dir）。标题和说明注释使用 t 的语言，
+// 没有译文的部分显示中文原文；t 为 nil 时使用中文。
+// 页面只取决于仓库中的源码和文档，重复生成的结果相同，修改课程后重新生成即可。
+func Build(c *course.Course, dir string, t *i18n.Texts) ([]string, error) {
+	if t == nil {
+		t = &i18n.Texts{Lang: i18n.Source}
+	}
+	tmpl, err := templates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	b := &builder{c: c, dir: dir, lessons: c.Lessons(), texts: t, tmpl: tmpl.Funcs(funcs(t))}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	decks, err := pptx.Load(c)
+	if err != nil {
+		return nil, err
+	}
+	b.slides = pptx.Match(decks, b.lessons)
+
+	readme, err := os.ReadFile(filepath.Join(c.Root, "README.md"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := b.write("index.html", "index.html", &indexPage{Course: c, Readme: Markdown(string(readme), b.image(c.Root, nil))}); err != nil {
+		return nil, err
+	}
+	for i, ch := range c.Chapters {
+		pg, err := b.chapter(ch)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			pg.Prev = c.Chapters[i-1]
+		}
+		if i+1 < len(c.Chapters) {
+			pg.Next = c.Chapters[i+1]
+		}
+		if err := b.write(ch.Name+".html", "chapter.html", pg); err != nil {
+			return nil, err
+		}
+	}
+	return b.pages, nil
+}
+
+type builder struct {
+	c       *course.Course
+	dir     string
+	lessons []*course.Lesson
+	slides  []pptx.Link
+	texts   *i18n.Texts
+	tmpl    *template.Template
+	pages   []string
+}
+
+// indexPage index.html 的数据。
+type indexPage struct {
+	Course *course.Course
+	Readme template.HTML
+}
+
+// chapterPage cN.html 的数据。
+type chapterPage struct {
+	Course     *course.Course
+	Chapter    *course.Chapter
+	Prev, Next *course.Chapter
+	Assets     []string // 复制到 assets/ 的讲义，相对 dir
+	Packages   []*packagePage
+}
+
+type packagePage struct {
+	Package *course.Package
+	Docs    []template.HTML // 包目录下的 Markdown 文档
+	Images  []template.URL  // 文档中没有引用的图片
+	Files   []*filePage
+}
+
+type filePage struct {
+	Name  string
+	Items []item
+}
+
+// item 源文件中的一段：说明注释、普通代码或示例，按在文件中的顺序排列。
+type item struct {
+	Note   template.HTML
+	Code   template.HTML
+	Lesson *lessonItem
+}
+
+type lessonItem struct {
+	*course.Lesson
+	Code    template.HTML
+	Related []*course.Lesson
+	Slides  []slideRef
+}
+
+type slideRef struct {
+	Href   string
+	Number int
+}
+
+// write 用模板 tmpl 生成页面 name。
+func (b *builder) write(name, tmpl string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := b.tmpl.ExecuteTemplate(&buf, tmpl, data); err != nil {
+		return err
+	}
+	b.pages = append(b.pages, name)
+	return os.WriteFile(filepath.Join(b.dir, name), buf.Bytes(), 0o644)
+}
+
+func (b *builder) chapter(ch *course.Chapter) (*chapterPage, error) {
+	pg := &chapterPage{Course: b.c, Chapter: ch}
+	for _, a := range ch.Assets {
+		name := "assets/" + path.Base(a)
+		if err := copyFile(filepath.Join(b.c.Root, filepath.FromSlash(a)), filepath.Join(b.dir, filepath.FromSlash(name))); err != nil {
+			return nil, err
+		}
+		pg.Assets = append(pg.Assets, name)
+	}
+	for _, p := range ch.Packages {
+		pp, err := b.pkg(p)
+		if err != nil {
+			return nil, err
+		}
+		pg.Packages = append(pg.Packages, pp)
+	}
+	return pg, nil
+}
+
+func (b *builder) pkg(p *course.Package) (*packagePage, error) {
+	pp := &packagePage{Package: p}
+	dir := filepath.Join(b.c.Root, filepath.FromSlash(p.Dir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(path.Ext(e.Name()), ".md") {
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			pp.Docs = append(pp.Docs, Markdown(string(data), b.image(dir, used)))
+		}
+	}
+	for _, e := range entries {
+		if !e.IsDir() && isImage(e.Name()) && !used[e.Name()] {
+			if u, err := dataURL(filepath.Join(dir, e.Name())); err == nil {
+				pp.Images = append(pp.Images, template.URL(u))
+			}
+		}
+	}
+	notes, err := b.texts.Notes(b.c.Root, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range p.Files {
+		fp, err := b.file(p, name, notes)
+		if err != nil {
+			return nil, err
+		}
+		if fp != nil {
+			pp.Files = append(pp.Files, fp)
+		}
+	}
+	return pp, nil
+}
+
+// image 返回 Markdown 中的图片地址转换函数：dir 中的图片内嵌为 data: URL，并记录到 used 中。
+func (b *builder) image(dir string, used map[string]bool) func(string) string {
+	return func(src string) string {
+		if strings.Contains(src, "://") || !isImage(src) {
+			return src
+		}
+		u, err := dataURL(filepath.Join(dir, filepath.FromSlash(src)))
+		if err != nil {
+			return src
+		}
+		if used != nil {
+			used[path.Clean(src)] = true
+		}
+		return u
+	}
+}
+
+// file 把源文件切成说明注释、代码和示例。包中有示例、而这个文件没有时返回 nil，
+// 例如只登记示例的 lessons.go。
+func (b *builder) file(p *course.Package, name string, notes []*course.Note) (*filePage, error) {
+	lessons := map[string]*course.Lesson{}
+	for _, l := range p.Lessons {
+		if l.File == name {
+			lessons[l.Name] = l
+		}
+	}
+	if len(lessons) == 0 && len(p.Lessons) > 0 {
+		return nil, nil
+	}
+
+	src, err := os.ReadFile(filepath.Join(b.c.Root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+
+	type lineItem struct {
+		line int
+		item
+	}
+	var items []lineItem
+	for _, d := range f.Decls {
+		if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			continue
+		}
+		end := d.End() // 包括写在结尾同一行的注释
+		for _, cg := range f.Comments {
+			if cg.Pos() >= end && line(cg.Pos()) == line(d.End()) {
+				end = cg.End()
+			}
+		}
+		code := string(src[offset(d.Pos()):offset(end)])
+		it := item{Code: Highlight(code)}
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && lessons[fn.Name.Name] != nil {
+			it = item{Lesson: b.lesson(lessons[fn.Name.Name], code)}
+		}
+		items = append(items, lineItem{line(d.Pos()), it})
+	}
+	for _, n := range notes {
+		if n.File == name {
+			items = append(items, lineItem{n.Line, item{Note: template.HTML(Autolink(n.Text))}})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].line < items[j].line })
+
+	fp := &filePage{Name: name}
+	for _, it := range items {
+		fp.Items = append(fp.Items, it.item)
+	}
+	return fp, nil
+}
+
+func (b *builder) lesson(l *course.Lesson, code string) *lessonItem {
+	li := &lessonItem{Lesson: l, Code: Highlight(code), Related: related(l, b.lessons)}
+	for _, k := range pptx.For(b.slides, l) {
+		if len(li.Slides) == 3 {
+			break
+		}
+		li.Slides = append(li.Slides, slideRef{"assets/" + path.Base(k.Deck.Path), k.Slide.Number})
+	}
+	return li
+}
+
+// stabilityTags 描述输出是否稳定的标签，不代表示例的主题，不用来找相关示例。
+var stabilityTags = map[string]bool{
+	lesson.TagUnordered: true, lesson.TagAddress: true, lesson.TagRacy: true,
+	lesson.TagSlow: true, lesson.TagPuzzle: true,
+}
+
+// related 返回其他课程包中与 l 有相同主题标签的示例，相同的标签多的在前。
+func related(l *course.Lesson, all []*course.Lesson) []*course.Lesson {
+	if l.Meta == nil {
+		return nil
+	}
+	type scored struct {
+		l     *course.Lesson
+		score int
+	}
+	var found []scored
+	for _, o := range all {
+		if o.Package == l.Package || o.Meta == nil {
+			continue
+		}
+		score := 0
+		for _, t := range l.Meta.Tags {
+			if !stabilityTags[t] && o.Meta.HasTag(t) {
+				score++
+			}
+		}
+		if score > 0 {
+			found = append(found, scored{o, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	var ls []*course.Lesson
+	for i := 0; i < len(found) && i < maxRelated; i++ {
+		ls = append(ls, found[i].l)
+	}
+	return ls
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg":
+		return true
+	}
+	return false
+}
+
+func dataURL(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	typ := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	if i := strings.Index(typ, ";"); i >= 0 {
+		typ = typ[:i]
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
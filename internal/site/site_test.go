@@ -0,0 +1,112 @@
+package site
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+func TestMarkdown(t *testing.T) {
+	src := "### 2. 初始化\n" +
+		"1. 创建 hmap 对象\n" +
+		"2. 计算 B\n" +
+		"    ```text\n" +
+		"    hint    B\n" +
+		"    0-8     0\n" +
+		"    ```\n" +
+		"3. 创建桶\n" +
+		"   - 当 B < 4, 创建 2^B^ 个标准桶。\n" +
+		"\n" +
+		"4. 见 ![map.png](map.png) 和 `h.extra`\n"
+	got := string(Markdown(src, func(s string) string { return "data:" + s }))
+	want := "<h3>2. 初始化</h3>\n" +
+		"<ol>\n<li>创建 hmap 对象</li>\n" +
+		"<li>计算 B<pre class=\"text\">hint    B\n0-8     0</pre>\n</li>\n" +
+		"<li>创建桶<ul>\n<li>当 B &lt; 4, 创建 2<sup>B</sup> 个标准桶。</li></ul>\n</li>\n" +
+		"<li>见 <img src=\"data:map.png\" alt=\"map.png\"> 和 <code>h.extra</code></li></ol>\n"
+	if got != want {
+		t.Errorf("Markdown:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = string(Markdown("3. 查找\n", nil))
+	if !strings.HasPrefix(got, `<ol start="3">`) {
+		t.Errorf("Markdown(3. ...) = %q", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := string(Highlight("m := make(map[string]int, 8) // 容量\ns := \"<a>\""))
+	want := `m := <span class="bi">make</span>(<span class="kw">map</span>[<span class="bi">string</span>]<span class="bi">int</span>, ` +
+		`<span class="num">8</span>) <span class="com">// 容量</span>` + "\n" +
+		`s := <span class="str">&#34;&lt;a&gt;&#34;</span>`
+	if got != want {
+		t.Errorf("Highlight:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	c := coursetest.Load(t)
+	dir := t.TempDir()
+	pages, err := Build(c, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != len(c.Chapters)+1 {
+		t.Errorf("pages = %v", pages)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	c4 := read("c4.html")
+	for _, want := range []string{
+		`<section class="lesson" id="c4-2.map-TestM1">`,
+		"<h3>1. Map存储结构</h3>",              // map.md
+		`<img src="data:image/png;base64,`, // 内嵌的图片
+		"map是一种无序的基于 key-value 的数据结构",      // 源码中的说明注释
+		`<span class="kw">func</span> TestM2`,
+	} {
+		if !strings.Contains(c4, want) {
+			t.Errorf("c4.html does not contain %q", want)
+		}
+	}
+	if strings.Contains(c4, "@description") {
+		t.Errorf("c4.html contains the editor comment template")
+	}
+	if !strings.Contains(read("c6.html"), `.pptx">第 7 页</a>`) {
+		t.Errorf("c6.html does not link TestG1 to slide 7")
+	}
+	if !strings.Contains(read("c3.html"), "%v	按值的本来值输出") {
+		t.Errorf("c3.html does not contain the comments of c3/1.internal")
+	}
+	if !strings.Contains(read("c5.html"), `<a href="c3.html#c3-3.pointer-`) {
+		t.Errorf("c5.html does not link to the related pointer lessons")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "assets", "1.Go语言初识.pptx")); err != nil {
+		t.Error(err)
+	}
+
+	// 重新生成的结果完全相同
+	again := t.TempDir()
+	if _, err := Build(c, again, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range pages {
+		data, err := os.ReadFile(filepath.Join(again, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, []byte(read(name))) {
+			t.Errorf("%s differs between two builds", name)
+		}
+	}
+}
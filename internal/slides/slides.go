@@ -0,0 +1,585 @@
+// Package slides 把课程包生成为 reveal.js 幻灯片：说明注释和 .md 文档变成文字页，
+// 每个示例变成一页代码，重点的行高亮显示，包目录中的图片各占一页。
+// 图片以 data: URL 内嵌，reveal.js 内联 reveal/ 中的副本，整套幻灯片是一个 HTML 文件；
+// 没有 reveal.js 时页面内的脚本也能用方向键翻页。
+package slides
+
+import (
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"html/template"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"study/internal/course"
+	"study/internal/i18n"
+	"study/internal/lesson"
+	"study/internal/site"
+)
+
+// 幻灯片的类型。
+const (
+	Text  = "text"
+	Code  = "code"
+	Image = "image"
+)
+
+// Deck 一个课程包的幻灯片。
+type Deck struct {
+	Title  string
+	Lang   string
+	Slides []*Slide
+}
+
+// Slide 一页幻灯片。
+type Slide struct {
+	Kind     string
+	Title    string
+	Subtitle string        // 代码页：示例上方的简短注释
+	Body     template.HTML // 文字页的内容
+	Lines    []Line        // 代码页的代码
+	Image    string        // 图片页的 data: URL
+	Notes    string        // 演讲者备注，reveal.js 中按 S 键查看
+}
+
+// Line 代码页的一行。
+type Line struct {
+	HTML template.HTML
+	Mark bool // 高亮的行
+}
+
+// FileName 返回课程包幻灯片的文件名，例如 c6_3.concurrencyControl.html。
+func FileName(p *course.Package) string {
+	return strings.ReplaceAll(p.Dir, "/", "_") + ".html"
+}
+
+// labels 幻灯片中固定文字的译文，键是中文。
+var labels = map[string]map[string]string{
+	"en": {
+		"（续）":                " (cont.)",
+		"（练习）":               " (exercise)",
+		"辅助代码":               "Helpers",
+		"图示":                 "Diagram",
+		"运行：study run %s %s": "Run: study run %s %s",
+		"这个示例会%s，请在终端中演示。": "This lesson %s; demo it in a terminal.",
+		"一直阻塞":     "blocks forever",
+		"触发 panic": "panics",
+		"让进程崩溃":    "crashes the process",
+	},
+}
+
+// deckBuilder 生成一套幻灯片时的状态。
+type deckBuilder struct {
+	root  string
+	p     *course.Package
+	texts *i18n.Texts
+	deck  *Deck
+	used  map[string]bool // 已经放进幻灯片的图片
+}
+
+func (b *deckBuilder) label(zh string, args ...interface{}) string {
+	if s, ok := labels[b.texts.Lang][zh]; ok {
+		zh = s
+	}
+	return fmt.Sprintf(zh, args...)
+}
+
+func (b *deckBuilder) add(s *Slide) {
+	b.deck.Slides = append(b.deck.Slides, s)
+}
+
+// Build 生成课程包 p 的幻灯片，标题和说明注释按 t 的语言显示。
+func Build(c *course.Course, p *course.Package, t *i18n.Texts) (*Deck, error) {
+	b := &deckBuilder{root: c.Root, p: p, texts: t, deck: &Deck{Title: p.Dir, Lang: t.Lang}, used: map[string]bool{}}
+	if t.Lang == i18n.Source {
+		b.deck.Lang = "zh-CN"
+	}
+
+	cover := &Slide{Kind: Text, Title: p.Dir}
+	for _, ch := range c.Chapters {
+		for _, cp := range ch.Packages {
+			if cp == p && ch.Title != "" {
+				cover.Body = template.HTML("<p>" + template.HTMLEscapeString(ch.Name+" "+t.ChapterTitle(ch)) + "</p>")
+			}
+		}
+	}
+	b.add(cover)
+
+	dir := filepath.Join(c.Root, filepath.FromSlash(p.Dir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, ent := range entries {
+		if !ent.IsDir() && strings.EqualFold(path.Ext(ent.Name()), ".md") {
+			data, err := os.ReadFile(filepath.Join(dir, ent.Name()))
+			if err != nil {
+				return nil, err
+			}
+			b.markdown(string(data))
+		}
+	}
+
+	notes, err := t.Notes(c.Root, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range p.Files {
+		if err := b.file(name, notes); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ent := range entries {
+		if !ent.IsDir() && isImage(ent.Name()) && !b.used[ent.Name()] {
+			b.image(b.label("图示"), ent.Name())
+		}
+	}
+	return b.deck, nil
+}
+
+var (
+	heading    = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	imageLine  = regexp.MustCompile(`^!\[([^\]]*)\]\(([^)\s]+)\)$`)
+	blankLines = regexp.MustCompile(`\n\s*\n`)
+)
+
+// markdown 按标题把 Markdown 文档切成文字页，单独一行的图片各占一页。
+func (b *deckBuilder) markdown(text string) {
+	var (
+		title string
+		lines []string
+		fence bool
+		shown bool // 这一节已经有一页了
+	)
+	flush := func() {
+		if body := strings.TrimSpace(strings.Join(lines, "\n")); body != "" || title != "" && !shown {
+			b.add(&Slide{Kind: Text, Title: title, Body: site.Markdown(body, b.inline)})
+			shown = true
+		}
+		lines = nil
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fence = !fence
+		}
+		switch m := imageLine.FindStringSubmatch(trimmed); {
+		case fence:
+		case heading.MatchString(trimmed):
+			flush()
+			title, shown = heading.FindStringSubmatch(trimmed)[1], false
+			continue
+		case m != nil && !strings.Contains(m[2], "://") && isImage(m[2]):
+			flush()
+			b.image(title, path.Clean(m[2]))
+			shown = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+}
+
+// inline 把文字页中的图片内嵌为 data: URL。
+func (b *deckBuilder) inline(src string) string {
+	if strings.Contains(src, "://") || !isImage(src) {
+		return src
+	}
+	u, err := dataURL(filepath.Join(b.root, filepath.FromSlash(b.p.Dir), filepath.FromSlash(src)))
+	if err != nil {
+		return src
+	}
+	b.used[path.Clean(src)] = true
+	return u
+}
+
+// image 添加一页图片，src 是相对包目录的路径。
+func (b *deckBuilder) image(title, src string) {
+	u, err := dataURL(filepath.Join(b.root, filepath.FromSlash(b.p.Dir), filepath.FromSlash(src)))
+	if err != nil {
+		return
+	}
+	b.used[src] = true
+	b.add(&Slide{Kind: Image, Title: title, Image: u, Notes: src})
+}
+
+// maxNoteLines 一页文字最多几行，更长的说明注释在空行处分成几页。
+const maxNoteLines = 14
+
+// maxSubtitle 示例上方的注释不超过几行时，作为代码页的副标题，不单独占一页。
+const maxSubtitle = 3
+
+// note 添加说明注释的文字页。第一行像标题时作为标题。
+func (b *deckBuilder) note(text string) {
+	title := ""
+	if i := strings.Index(text, "\n"); i > 0 {
+		first := strings.TrimSpace(text[:i])
+		if utf8.RuneCountInString(first) <= 30 && !strings.HasSuffix(first, "。") && !strings.HasSuffix(first, ".") {
+			title = strings.TrimRight(first, "：:")
+			text = strings.TrimSpace(text[i:])
+		}
+	}
+	var pages []string
+	n := 0
+	for _, para := range blankLines.Split(text, -1) {
+		lines := strings.Count(para, "\n") + 1
+		if len(pages) > 0 && n+lines <= maxNoteLines {
+			pages[len(pages)-1] += "\n\n" + para
+			n += lines + 1
+			continue
+		}
+		pages = append(pages, para)
+		n = lines
+	}
+	for i, page := range pages {
+		t := title
+		if i > 0 && t != "" {
+			t += b.label("（续）")
+		}
+		b.add(&Slide{Kind: Text, Title: t, Body: template.HTML(`<pre class="note">` + site.Autolink(dedent(page)) + "</pre>")})
+	}
+}
+
+// file 按源码的顺序添加说明注释、示例和其他声明。包中有示例、而这个文件没有时跳过，
+// 例如只登记示例的 lessons.go。
+func (b *deckBuilder) file(name string, notes []*course.Note) error {
+	lessons := map[string]*course.Lesson{}
+	for _, l := range b.p.Lessons {
+		if l.File == name {
+			lessons[l.Name] = l
+		}
+	}
+	if len(lessons) == 0 && len(b.p.Lessons) > 0 {
+		return nil
+	}
+	src, err := os.ReadFile(filepath.Join(b.root, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+
+	type item struct {
+		line   int
+		note   string
+		lesson *course.Lesson
+		decl   ast.Decl
+		start  token.Pos // 其他声明包括它自己的注释和写在结尾同一行的注释
+		end    token.Pos
+	}
+	var items []item
+	docs := map[int]bool{} // 其他声明自己的注释，跟声明的代码放在一起
+	for _, d := range f.Decls {
+		if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			continue
+		}
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && lessons[fn.Name.Name] != nil {
+			items = append(items, item{line: line(d.Pos()), lesson: lessons[fn.Name.Name], decl: d, start: d.Pos(), end: d.End()})
+			continue
+		}
+		start, end := d.Pos(), d.End()
+		if doc := declDoc(d); doc != nil {
+			start = doc.Pos()
+			docs[line(doc.Pos())] = true
+		}
+		for _, cg := range f.Comments {
+			if cg.Pos() >= end && line(cg.Pos()) == line(d.End()) {
+				end = cg.End()
+			}
+		}
+		items = append(items, item{line: line(start), decl: d, start: start, end: end})
+	}
+	for _, n := range notes {
+		if n.File == name && !docs[n.Line] {
+			items = append(items, item{line: n.Line, note: n.Text})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].line < items[j].line })
+
+	syncVars := SyncVars(f)
+	lines := func(it item) []Line {
+		from := fset.Position(it.start)
+		marks := map[int]bool{}
+		for _, n := range Marks(fset, it.decl, syncVars) {
+			marks[n-from.Line] = true
+		}
+		return highlight(string(src[from.Offset:fset.Position(it.end).Offset]), marks)
+	}
+	var helpers []Line
+	flushHelpers := func() {
+		if len(helpers) > 0 {
+			b.add(&Slide{Kind: Code, Title: b.label("辅助代码"), Lines: helpers})
+			helpers = nil
+		}
+	}
+	for i, it := range items {
+		switch {
+		case it.lesson != nil:
+			flushHelpers()
+			subtitle := ""
+			if i > 0 && items[i-1].note != "" && strings.Count(items[i-1].note, "\n") < maxSubtitle {
+				subtitle = items[i-1].note
+			}
+			b.lesson(it.lesson, lines(it), subtitle)
+		case it.note != "":
+			if i+1 < len(items) && items[i+1].lesson != nil && strings.Count(it.note, "\n") < maxSubtitle {
+				continue // 作为下一页代码的副标题
+			}
+			flushHelpers()
+			b.note(it.note)
+		default:
+			if len(helpers) > 0 {
+				helpers = append(helpers, Line{})
+			}
+			helpers = append(helpers, lines(it)...)
+		}
+	}
+	flushHelpers()
+	return nil
+}
+
+func declDoc(d ast.Decl) *ast.CommentGroup {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// lesson 添加示例的代码页，演讲者备注中是运行示例的命令。
+func (b *deckBuilder) lesson(l *course.Lesson, lines []Line, subtitle string) {
+	if strings.TrimSpace(subtitle) == b.texts.Title(l) {
+		subtitle = ""
+	}
+	title := l.Name + " " + b.texts.Title(l)
+	notes := b.label("运行：study run %s %s", b.p.Dir, l.Name)
+	if m := l.Meta; m != nil {
+		if m.Exercise {
+			title += b.label("（练习）")
+		}
+		switch m.Expect {
+		case lesson.Blocks:
+			notes += "\n" + b.label("这个示例会%s，请在终端中演示。", b.label("一直阻塞"))
+		case lesson.Panics:
+			notes += "\n" + b.label("这个示例会%s，请在终端中演示。", b.label("触发 panic"))
+		case lesson.Fatal:
+			notes += "\n" + b.label("这个示例会%s，请在终端中演示。", b.label("让进程崩溃"))
+		}
+	}
+	b.add(&Slide{
+		Kind:     Code,
+		Title:    title,
+		Subtitle: subtitle,
+		Lines:    lines,
+		Notes:    fmt.Sprintf("%s\n%s:%d", notes, l.File, l.Line),
+	})
+}
+
+// syncPackages 这些包中的类型的方法调用值得高亮，例如 wg.Wait()、once.Do(f)。
+var syncPackages = map[string]bool{"sync": true, "atomic": true}
+
+// SyncVars 返回 f 中类型为 sync 或 sync/atomic 包中类型的变量和字段的名字。
+// 课程代码中没有同名的其他变量，只按名字判断就够了。
+func SyncVars(f *ast.File) map[string]bool {
+	names := map[string]bool{}
+	add := func(typ ast.Expr, ids []*ast.Ident) {
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+		sel, ok := typ.(*ast.SelectorExpr)
+		if !ok {
+			return
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && syncPackages[x.Name] {
+			for _, id := range ids {
+				names[id.Name] = true
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			add(n.Type, n.Names)
+		case *ast.Field:
+			add(n.Type, n.Names)
+		}
+		return true
+	})
+	return names
+}
+
+// Marks 返回声明 d 中值得高亮的行：启动 goroutine、defer、select 和通道的收发，
+// 调用 append、copy、make、new、delete、close、panic、recover，取地址，类型断言，
+// 以及 syncVars 中的变量的方法调用和 atomic 包的函数调用。
+func Marks(fset *token.FileSet, d ast.Decl, syncVars map[string]bool) []int {
+	fn, ok := d.(*ast.FuncDecl)
+	if !ok || fn.Body == nil {
+		return nil
+	}
+	seen := map[int]bool{}
+	mark := func(pos token.Pos) { seen[fset.Position(pos).Line] = true }
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt, *ast.DeferStmt, *ast.SelectStmt, *ast.CommClause, *ast.SendStmt,
+			*ast.TypeAssertExpr, *ast.TypeSwitchStmt:
+			mark(n.Pos())
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW || n.Op == token.AND {
+				mark(n.Pos())
+			}
+		case *ast.CallExpr:
+			switch fun := n.Fun.(type) {
+			case *ast.Ident:
+				switch fun.Name {
+				case "append", "copy", "make", "new", "delete", "close", "panic", "recover":
+					mark(n.Pos())
+				}
+			case *ast.SelectorExpr:
+				if x, ok := fun.X.(*ast.Ident); ok && (syncVars[x.Name] || x.Name == "atomic") {
+					mark(n.Pos())
+				}
+			}
+		}
+		return true
+	})
+	lines := make([]int, 0, len(seen))
+	for n := range seen {
+		lines = append(lines, n)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+var openSpan = regexp.MustCompile(`<span class="[a-z]+">`)
+
+// highlight 给代码加上语法高亮，按行拆开。跨行的注释和字符串在行尾关闭 <span>，下一行重新打开。
+// marks 中是要高亮的行，从 0 开始。
+func highlight(code string, marks map[int]bool) []Line {
+	var lines []Line
+	open := ""
+	for i, s := range strings.Split(string(site.Highlight(code)), "\n") {
+		s = open + s
+		open = ""
+		if o := openSpan.FindAllString(s, -1); len(o) > strings.Count(s, "</span>") {
+			open = o[len(o)-1]
+			s += "</span>"
+		}
+		lines = append(lines, Line{HTML: template.HTML(s), Mark: marks[i]})
+	}
+	return lines
+}
+
+// dedent 去掉各行共同的缩进。
+func dedent(text string) string {
+	lines := strings.Split(text, "\n")
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		ws := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = ws, false
+		}
+		for !strings.HasPrefix(ws, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+//go:embed deck.html
+var templateFS embed.FS
+
+var deckTemplate = template.Must(template.New("deck.html").Funcs(template.FuncMap{
+	"marked": func(lines []Line) bool {
+		for _, l := range lines {
+			if l.Mark {
+				return true
+			}
+		}
+		return false
+	},
+}).ParseFS(templateFS, "deck.html"))
+
+// revealFS reveal.js 的副本，见 reveal/README.md。
+//
+//go:embed reveal
+var revealFS embed.FS
+
+// Write 把幻灯片写成一个 HTML 文件。reveal 为空时内联 reveal/ 中的 reveal.js，
+// 副本不全时返回错误；否则从 reveal 这个地址加载。
+func (d *Deck) Write(w io.Writer, reveal string) error {
+	data := struct {
+		*Deck
+		Reveal string
+		JS     template.JS
+		CSS    template.CSS
+	}{Deck: d, Reveal: strings.TrimRight(reveal, "/")}
+	if reveal == "" {
+		var err error
+		if data.JS, data.CSS, err = inlineReveal(); err != nil {
+			return err
+		}
+	}
+	return deckTemplate.Execute(w, data)
+}
+
+// revealFiles 内联的 reveal.js 文件，相对 revealFS。
+var revealFiles = []string{"reveal/dist/reveal.js", "reveal/dist/reveal.css", "reveal/dist/theme/white.css"}
+
+// inlineReveal 返回要内联的 reveal.js 和样式，副本不全时返回错误。
+func inlineReveal() (template.JS, template.CSS, error) {
+	var files [3][]byte
+	for i, name := range revealFiles {
+		data, err := revealFS.ReadFile(name)
+		if err != nil || len(data) == 0 {
+			return "", "", fmt.Errorf("slides: %s is not vendored in internal/slides (see internal/slides/reveal/README.md), or pass -reveal url", name)
+		}
+		files[i] = data
+	}
+	js := strings.ReplaceAll(string(files[0]), "</script", `<\/script`)
+	return template.JS(js), template.CSS(string(files[1]) + "\n" + string(files[2])), nil
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg":
+		return true
+	}
+	return false
+}
+
+func dataURL(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	typ := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	if i := strings.Index(typ, ";"); i >= 0 {
+		typ = typ[:i]
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
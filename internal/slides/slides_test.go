@@ -0,0 +1,180 @@
+package slides
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+	"study/internal/i18n"
+	_ "study/internal/lesson/all"
+)
+
+func TestConcurrencyControl(t *testing.T) {
+	c := coursetest.Load(t)
+	p := c.Package("c6/3.concurrencyControl")
+	texts, err := i18n.Load(c.Root, i18n.Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deck, err := Build(c, p, texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := map[string]*Slide{}
+	for _, s := range deck.Slides {
+		if s.Kind == Code {
+			code[strings.Fields(s.Title)[0]] = s
+		}
+	}
+	for _, l := range p.Lessons {
+		if code[l.Name] == nil {
+			t.Errorf("no code slide for %s", l.Name)
+		}
+	}
+	if s := code["TestC1"]; s != nil {
+		if s.Subtitle != "select可以同时监听一个或多个channel，直到其中一个channel ready" {
+			t.Errorf("TestC1 subtitle = %q", s.Subtitle)
+		}
+		var marked []string
+		for _, l := range s.Lines {
+			if l.Mark {
+				marked = append(marked, strings.TrimSpace(regexp.MustCompile(`<[^>]+>`).ReplaceAllString(string(l.HTML), "")))
+			}
+		}
+		want := []string{
+			"output1 := make(chan string)", "output2 := make(chan string)",
+			"go test1(output1)", "go test2(output2)",
+			"select {", "case s1 := &lt;-output1:", "case s2 := &lt;-output2:",
+		}
+		if !reflect.DeepEqual(marked, want) {
+			t.Errorf("TestC1 marked lines = %q\nwant %q", marked, want)
+		}
+	}
+
+	const url = "http://localhost:8000/reveal.js"
+	var b bytes.Buffer
+	if err := deck.Write(&b, url+"/"); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if n := strings.Count(out, "<section"); n != len(deck.Slides) {
+		t.Errorf("%d sections, want %d", n, len(deck.Slides))
+	}
+	for _, s := range []string{`<html lang="zh-CN">`, `src="` + url + `/dist/reveal.js"`, "Reveal.initialize", `<aside class="notes">`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("deck has no %s", s)
+		}
+	}
+
+}
+
+// TestReveal 默认内联 reveal/ 中的 reveal.js，不从外部加载任何东西；副本不全时报错。
+func TestReveal(t *testing.T) {
+	c := coursetest.Load(t)
+	p := c.Package("c3/3.pointer")
+	if p == nil {
+		t.Fatal("no package c3/3.pointer")
+	}
+	texts, err := i18n.Load(c.Root, i18n.Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deck, err := Build(c, p, texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	err = deck.Write(&b, "")
+	for _, name := range revealFiles {
+		data, rerr := revealFS.ReadFile(name)
+		if rerr != nil || len(data) == 0 {
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("Write without %s: err = %v, want an error naming it", name, err)
+			}
+			t.Skipf("%s is not vendored, see reveal/README.md", name)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if strings.Contains(out, "<script src=") || strings.Contains(out, `<link rel="stylesheet"`) {
+		t.Error("the default deck loads external files")
+	}
+	js, css, _ := inlineReveal()
+	if !strings.Contains(out, string(js[:100])) || !strings.Contains(out, string(css[:100])) {
+		t.Error("the vendored reveal.js is not inlined")
+	}
+}
+
+func TestImages(t *testing.T) {
+	c := coursetest.Load(t)
+	texts, err := i18n.Load(c.Root, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for dir, want := range map[string][]string{
+		"c4/2.map":     {"map.png", "img_1.png", "img_2.png", "img.png"},
+		"c6/2.channel": {"img.png", "img_1.png"},
+	} {
+		deck, err := Build(c, c.Package(dir), texts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range deck.Slides {
+			if s.Kind == Image {
+				got = append(got, s.Notes)
+				if !strings.HasPrefix(s.Image, "data:image/png;base64,") {
+					t.Errorf("%s: %s is not embedded", dir, s.Notes)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: image slides %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	lines := highlight("/* a\nb */\nx := `1\n2`", map[int]bool{2: true})
+	want := []Line{
+		{HTML: `<span class="com">/* a</span>`},
+		{HTML: `<span class="com">b */</span>`},
+		{HTML: `x := <span class="str">` + "`1</span>", Mark: true},
+		{HTML: `<span class="str">2` + "`</span>"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("highlight = %v\nwant %v", lines, want)
+	}
+}
+
+func TestMarks(t *testing.T) {
+	src := `package p
+
+var once sync.Once
+
+func f(wg *sync.WaitGroup, m map[string]int) {
+	fmt.Println("x")
+	defer wg.Done()
+	once.Do(g)
+	delete(m, "a")
+	p := &m
+	_ = p
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Marks(fset, f.Decls[1], SyncVars(f)), []int{7, 8, 9, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Marks = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package snippet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// Problem 片段中的一个编译错误。
+type Problem struct {
+	Snippet *Snippet
+	Line    int // 在文件中的行号
+	Msg     string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.Snippet.File, p.Line, p.Msg)
+}
+
+// stdImports 片段中常用、但通常省略 import 的标准库包。
+var stdImports = map[string]string{
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"json":    "encoding/json",
+	"math":    "math",
+	"os":      "os",
+	"sort":    "sort",
+	"strconv": "strconv",
+	"strings": "strings",
+	"sync":    "sync",
+	"time":    "time",
+	"unsafe":  "unsafe",
+}
+
+// Checker 检查片段能否编译。各片段共用导入的包。
+type Checker struct {
+	imp types.Importer
+}
+
+// NewChecker 返回一个 Checker。
+func NewChecker() *Checker {
+	return &Checker{imp: importer.Default()}
+}
+
+// Check 把片段包装成一个源文件做语法和类型检查，伪代码不检查。
+//
+// 片段以 package 开头时原样检查；以 func、type、import 开头时放在文件顶层；其他的放进一个函数体。
+// 片段不写 import 时按 stdImports 补上。片段通常引用上下文中的变量和类型，
+// 所以未定义的名字不算错误，声明了但没有使用的变量和包也不算。
+func (c *Checker) Check(s *Snippet) []*Problem {
+	if s.Pseudo {
+		return nil
+	}
+	src, offset := wrap(s.Code, nil)
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "snippet.go", src, 0)
+	if err != nil {
+		// 只报告第一个语法错误，后面的通常是它引起的
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			return []*Problem{s.problem(list[0].Pos.Line-offset, list[0].Msg)}
+		}
+		return []*Problem{s.problem(1, err.Error())}
+	}
+	if !strings.HasPrefix(s.Code, "package ") {
+		if imports := MissingImports(f); len(imports) > 0 {
+			src, offset = wrap(s.Code, imports)
+			if f, err = parser.ParseFile(fset, "snippet.go", src, 0); err != nil {
+				return []*Problem{s.problem(1, err.Error())}
+			}
+		}
+	}
+
+	var problems []*Problem
+	conf := types.Config{
+		Importer: c.imp,
+		Error: func(err error) {
+			e := err.(types.Error)
+			// 以 tab 开头的是上一个错误的补充说明，例如 other declaration of bmap
+			if e.Soft || undefinedName(e.Msg) || strings.HasPrefix(e.Msg, "\t") {
+				return
+			}
+			problems = append(problems, s.problem(e.Fset.Position(e.Pos).Line-offset, e.Msg))
+		},
+	}
+	conf.Check("snippet", fset, []*ast.File{f}, nil)
+	return problems
+}
+
+func (s *Snippet) problem(line int, msg string) *Problem {
+	if line < 1 {
+		line = 1
+	}
+	return &Problem{Snippet: s, Line: s.Line + line - 1, Msg: msg}
+}
+
+// wrap 把片段包装成源文件，返回源码和片段前面多出的行数。
+// package 子句和补上的 import 写在同一行，片段之前只多出一行。
+func wrap(code string, imports []string) (string, int) {
+	if strings.HasPrefix(code, "package ") {
+		return code, 0
+	}
+	head := "package snippet"
+	for _, path := range imports {
+		head += "; import " + fmt.Sprintf("%q", path)
+	}
+	head += "\n"
+	switch strings.SplitN(strings.TrimSpace(code), " ", 2)[0] {
+	case "func", "type", "import":
+		return head + code + "\n", 1
+	}
+	return head + "func _() {\n" + code + "\n}\n", 2
+}
+
+// MissingImports 返回 f 用到、但没有导入的常用标准库包（见 stdImports），按出现的顺序排列。
+func MissingImports(f *ast.File) []string {
+	imported := map[string]bool{}
+	for _, spec := range f.Imports {
+		imported[strings.Trim(spec.Path.Value, `"`)] = true
+	}
+	seen := map[string]bool{}
+	var paths []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if path, ok := stdImports[id.Name]; ok && !imported[path] && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+		return true
+	})
+	return paths
+}
+
+// undefinedRe 匹配未定义的名字，例如 undefined: x、undefined array length bucketCnt or ...。
+var undefinedRe = regexp.MustCompile(`^undefined(?: array length)?:? (\S+)`)
+
+// undefinedName 判断错误是不是引用了片段中没有定义的名字。
+// 包中不存在的成员（undefined: fmt.Printx）仍然是错误。
+func undefinedName(msg string) bool {
+	m := undefinedRe.FindStringSubmatch(msg)
+	return m != nil && !strings.Contains(m[1], ".")
+}
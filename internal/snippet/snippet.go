@@ -0,0 +1,195 @@
+// Package snippet 找出课程注释和 Markdown 文档中的 Go 代码片段，并检查它们能否编译。
+//
+// Markdown 中的片段是 ```go 代码块；注释中的片段是以 func、type、switch 等关键字开头、
+// 以 { 或 ( 结尾的一行，直到括号配对结束为止。只是示意语法的片段（伪代码）不检查：
+// Markdown 中写成 ```go pseudo，注释中在片段的上一行写上 “伪代码”。
+package snippet
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"study/internal/course"
+)
+
+// PseudoMarker 写在注释片段上一行，表示片段是伪代码。
+const PseudoMarker = "伪代码"
+
+// Snippet 一个代码片段。
+type Snippet struct {
+	File   string // 相对仓库根目录
+	Line   int    // 片段第一行在文件中的行号
+	Code   string
+	Pseudo bool
+}
+
+// Extract 返回课程包 p 的注释和目录下 Markdown 文档中的代码片段，按文件和行排列。
+func Extract(root string, p *course.Package) ([]*Snippet, error) {
+	var snippets []*Snippet
+	for _, name := range p.Files {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(name)), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, cg := range f.Comments {
+			snippets = append(snippets, FromComment(name, commentLines(fset, cg))...)
+		}
+	}
+
+	dir := filepath.Join(root, filepath.FromSlash(p.Dir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, FromMarkdown(path.Join(p.Dir, e.Name()), string(data))...)
+	}
+	sort.SliceStable(snippets, func(i, j int) bool {
+		if snippets[i].File != snippets[j].File {
+			return snippets[i].File < snippets[j].File
+		}
+		return snippets[i].Line < snippets[j].Line
+	})
+	return snippets, nil
+}
+
+// Line 注释中的一行文字和它在文件中的行号。
+type Line struct {
+	Num  int
+	Text string
+}
+
+// commentLines 去掉 // 和 /* */，返回注释组的每一行。
+func commentLines(fset *token.FileSet, cg *ast.CommentGroup) []Line {
+	var lines []Line
+	for _, c := range cg.List {
+		num := fset.Position(c.Pos()).Line
+		if strings.HasPrefix(c.Text, "//") {
+			lines = append(lines, Line{num, c.Text[2:]})
+			continue
+		}
+		text := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+		for i, s := range strings.Split(text, "\n") {
+			lines = append(lines, Line{num + i, s})
+		}
+	}
+	return lines
+}
+
+// fragmentStart 匹配注释中片段的第一行。
+var fragmentStart = regexp.MustCompile(`^\s*(func|type|switch|select|for|if|var|const|import|go|defer)\b.*[{(]\s*$`)
+
+// FromComment 从注释的各行中找出代码片段。
+func FromComment(file string, lines []Line) []*Snippet {
+	var snippets []*Snippet
+	for i := 0; i < len(lines); i++ {
+		if !fragmentStart.MatchString(lines[i].Text) {
+			continue
+		}
+		s := &Snippet{File: file, Line: lines[i].Num}
+		for j := i - 1; j >= 0; j-- {
+			if prev := strings.TrimSpace(lines[j].Text); prev != "" {
+				s.Pseudo = strings.Contains(prev, PseudoMarker)
+				break
+			}
+		}
+		var code []string
+		depth := 0
+		for ; i < len(lines); i++ {
+			code = append(code, lines[i].Text)
+			depth += bracketDepth(lines[i].Text)
+			if depth <= 0 {
+				break
+			}
+		}
+		s.Code = strings.Join(trimIndent(code), "\n")
+		snippets = append(snippets, s)
+	}
+	return snippets
+}
+
+// bracketDepth 返回一行中 { ( [ 比 } ) ] 多出的个数，不计字符串和行尾注释中的括号。
+func bracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '/' && strings.HasPrefix(line[i:], "//"):
+			return depth
+		case r == '{' || r == '(' || r == '[':
+			depth++
+		case r == '}' || r == ')' || r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// FromMarkdown 返回 Markdown 文档中的 ```go 代码块。列表项中缩进的代码块也算。
+func FromMarkdown(file, text string) []*Snippet {
+	var snippets []*Snippet
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		info := strings.Fields(strings.TrimPrefix(trimmed, "```"))
+		start := i + 1
+		for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+		}
+		if len(info) == 0 || info[0] != "go" {
+			continue
+		}
+		s := &Snippet{File: file, Line: start + 1, Code: strings.Join(trimIndent(lines[start:i]), "\n")}
+		for _, f := range info[1:] {
+			s.Pseudo = s.Pseudo || f == "pseudo"
+		}
+		snippets = append(snippets, s)
+	}
+	return snippets
+}
+
+// trimIndent 去掉所有非空行共同的前导空白。
+func trimIndent(lines []string) []string {
+	prefix, first := "", true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimRight(strings.TrimPrefix(line, prefix), " \t")
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package snippet
+
+import (
+	"strings"
+	"testing"
+
+	"study/internal/course/coursetest"
+)
+
+func lines(text string) []Line {
+	var out []Line
+	for i, s := range strings.Split(text, "\n") {
+		out = append(out, Line{Num: 10 + i, Text: s})
+	}
+	return out
+}
+
+func TestFromComment(t *testing.T) {
+	got := FromComment("a_test.go", lines("switch 分支表达式可以是任意类型。\n"+
+		"语法（伪代码）：\n"+
+		"switch var1 {\n"+
+		"    case val1:\n"+
+		"        ...\n"+
+		"}\n"+
+		"\n"+
+		"for 循环的 range 格式：\n"+
+		"\tfor key, value := range oldMap { // {\n"+
+		"\t    newMap[key] = value\n"+
+		"\t}\n"+
+		"数组定义：var a [len]int"))
+	if len(got) != 2 {
+		t.Fatalf("FromComment found %d snippets, want 2", len(got))
+	}
+	if s := got[0]; s.Line != 12 || !s.Pseudo || !strings.HasSuffix(s.Code, "...\n}") {
+		t.Errorf("first snippet = %+v", s)
+	}
+	want := "for key, value := range oldMap { // {\n    newMap[key] = value\n}"
+	if s := got[1]; s.Line != 18 || s.Pseudo || s.Code != want {
+		t.Errorf("second snippet = %+v, want code %q", s, want)
+	}
+}
+
+func TestFromMarkdown(t *testing.T) {
+	got := FromMarkdown("m.md", "# map\n```go\ntype hmap struct{}\n```\n```text\nhint B\n```\n"+
+		"1. 迁移\n   ```go pseudo\n   y := &xy[1]\n   ```\n")
+	if len(got) != 2 {
+		t.Fatalf("FromMarkdown found %d snippets, want 2", len(got))
+	}
+	if s := got[0]; s.Line != 3 || s.Pseudo || s.Code != "type hmap struct{}" {
+		t.Errorf("first snippet = %+v", s)
+	}
+	if s := got[1]; s.Line != 10 || !s.Pseudo || s.Code != "y := &xy[1]" {
+		t.Errorf("second snippet = %+v", s)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := NewChecker()
+	for _, tt := range []struct {
+		code string
+		want string // 第一个错误，空表示没有错误
+	}{
+		// 引用上下文中的名字、未使用的变量都可以
+		{"switch i := x.(type) {\ncase int:\n}", ""},
+		{"m := make(map[string]string, 10)", ""},
+		{"type bmap struct {\n    tophash [bucketCnt]uint8\n}", ""},
+		{"type writer interface{\n    Write([]byte) error\n}", ""},
+		// 省略的 import 自动补上
+		{"fmt.Println(unsafe.Sizeof(1))", ""},
+		{"package main\n\nfunc main() {}", ""},
+
+		{"switch x.(type){\n\tcase type:\n}", "11: expected operand, found 'type'"},
+		{"type bmap struct{}\n\ntype bmap struct{}", "12: bmap redeclared in this block"},
+		{"fmt.Printx(1)", "10: undefined: fmt.Printx"},
+		{"func (p Person) Name() string {\n\tp.name = 1\n}", "12: missing return"},
+	} {
+		got := c.Check(&Snippet{File: "a.go", Line: 10, Code: tt.code})
+		msg := ""
+		if len(got) > 0 {
+			msg = strings.TrimPrefix(got[0].String(), "a.go:")
+		}
+		if msg != tt.want {
+			t.Errorf("Check(%q) = %q, want %q", tt.code, msg, tt.want)
+		}
+	}
+
+	if got := c.Check(&Snippet{Code: "switch var1 {\n...\n}", Pseudo: true}); got != nil {
+		t.Errorf("Check(pseudo-code) = %v", got)
+	}
+}
+
+// TestCourse 课程中的片段都要能编译，只是示意语法的片段要标成伪代码。
+func TestCourse(t *testing.T) {
+	c := coursetest.Load(t)
+	checker := NewChecker()
+	n := 0
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			snippets, err := Extract(c.Root, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range snippets {
+				n++
+				for _, pr := range checker.Check(s) {
+					t.Error(pr)
+				}
+			}
+		}
+	}
+	if n < 10 {
+		t.Errorf("found only %d snippets in the course", n)
+	}
+}
@@ -0,0 +1,253 @@
+package syllabus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"study/internal/course"
+	"study/internal/lesson"
+)
+
+var (
+	asciiWord = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_.-]*`)
+	asciiOnly = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+)
+
+// keywords 返回用来匹配主题的关键词：标签的别名（见 lesson.Aliases），以及标题中的英文单词（append、goto、sync.Once 等）。
+func keywords(l *course.Lesson) []string {
+	var kws []string
+	if l.Meta != nil {
+		for _, tag := range l.Meta.Tags {
+			kws = append(kws, lesson.Aliases[tag]...)
+		}
+	}
+	for _, w := range asciiWord.FindAllString(l.Title, -1) {
+		if !lesson.StopWords[strings.ToLower(w)] {
+			kws = append(kws, w)
+		}
+	}
+	return kws
+}
+
+// mentions 判断 text 是否提到了 kw：中文要和主题标题完全相同，英文按单词匹配。
+func mentions(text, kw string) bool {
+	if !asciiOnly.MatchString(kw) {
+		return text == kw
+	}
+	for _, w := range asciiWord.FindAllString(text, -1) {
+		if strings.EqualFold(w, kw) || strings.EqualFold(w, kw+"s") {
+			return true
+		}
+		// if-else 这样的词，按 - 拆开再比较
+		for _, part := range strings.Split(w, "-") {
+			if strings.EqualFold(part, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// short 判断主题是不是足够短的标题。长句子一般是知识点的描述或代码，
+// 跟着所属的小节算，不单独匹配示例。
+func short(t *Topic) bool {
+	return utf8.RuneCountInString(t.Title) <= 20
+}
+
+// heading 判断主题是不是小节标题：有子主题，或者足够短。
+func heading(t *Topic) bool {
+	return len(t.Children) > 0 || short(t)
+}
+
+// Match 为每个小节标题找出讲它的示例，填写 Topic.Lessons。
+func Match(sheets []*Sheet, lessons []*course.Lesson) {
+	kws := map[*course.Lesson][]string{}
+	for _, l := range lessons {
+		kws[l] = keywords(l)
+	}
+	var walk func(t *Topic)
+	walk = func(t *Topic) {
+		t.Lessons = nil
+		if short(t) {
+			for _, l := range lessons {
+				for _, kw := range kws[l] {
+					if mentions(t.Title, kw) {
+						t.Lessons = append(t.Lessons, l.Path())
+						break
+					}
+				}
+			}
+		}
+		for _, c := range t.Children {
+			walk(c)
+		}
+	}
+	for _, s := range sheets {
+		walk(s.Root)
+	}
+}
+
+// Coverage 大纲和示例的对照结果。
+type Coverage struct {
+	Empty    []*course.Chapter // 没有任何示例的章节
+	Missing  [][]string        // 没有示例的小节，每项是从第一层主题开始的标题路径
+	Extras   []Extra           // 代码注释中“扩展内容”列出、但没有示例的主题
+	Unlisted []*course.Lesson  // 大纲中找不到的示例
+}
+
+// Extra 代码注释中列出的扩展主题。
+type Extra struct {
+	Title string
+	File  string
+	Line  int
+}
+
+// Check 对照大纲和课程。sheets 会先经过 Match。
+func Check(c *course.Course, sheets []*Sheet) (*Coverage, error) {
+	lessons := c.Lessons()
+	Match(sheets, lessons)
+	cov := &Coverage{}
+
+	for _, ch := range c.Chapters {
+		n := 0
+		for _, p := range ch.Packages {
+			n += len(p.Lessons)
+		}
+		if n == 0 {
+			cov.Empty = append(cov.Empty, ch)
+		}
+	}
+
+	// 主题本身、祖先或子孙有示例就算覆盖了。没有覆盖的主题，子孙也都没有覆盖，
+	// 所以只列出最外层的：父主题覆盖了、自己却没有覆盖的小节。
+	listed := map[string]bool{}
+	covered := map[*Topic]bool{}
+	var mark func(t *Topic, inherited bool) bool
+	mark = func(t *Topic, inherited bool) bool {
+		for _, id := range t.Lessons {
+			listed[id] = true
+		}
+		self := inherited || len(t.Lessons) > 0
+		below := false
+		for _, c := range t.Children {
+			if mark(c, self) {
+				below = true
+			}
+		}
+		covered[t] = self || below
+		return covered[t]
+	}
+	var missing func(t *Topic, path []string)
+	missing = func(t *Topic, path []string) {
+		if !covered[t] {
+			if heading(t) {
+				cov.Missing = append(cov.Missing, path)
+			}
+			return
+		}
+		for _, c := range t.Children {
+			missing(c, append(path[:len(path):len(path)], c.Title))
+		}
+	}
+	for _, s := range sheets {
+		mark(s.Root, false)
+		for _, t := range s.Root.Children {
+			missing(t, []string{t.Title})
+		}
+	}
+
+	for _, l := range lessons {
+		if !listed[l.Path()] && l.Name != course.MainLesson {
+			cov.Unlisted = append(cov.Unlisted, l)
+		}
+	}
+
+	extras, err := extras(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range extras {
+		found := false
+		for _, l := range lessons {
+			for _, kw := range keywords(l) {
+				if mentions(e.Title, kw) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			cov.Extras = append(cov.Extras, e)
+		}
+	}
+	return cov, nil
+}
+
+var extrasHeader = regexp.MustCompile(`^\s*//\s*扩展内容\s*[:：]?\s*$`)
+
+// extras 找出课程代码中以“// 扩展内容：”开头的注释列表。
+func extras(c *course.Course) ([]Extra, error) {
+	var out []Extra
+	for _, ch := range c.Chapters {
+		for _, p := range ch.Packages {
+			for _, file := range p.Files {
+				f, err := os.Open(filepath.Join(c.Root, filepath.FromSlash(file)))
+				if err != nil {
+					return nil, err
+				}
+				s := bufio.NewScanner(f)
+				in := false
+				for n := 1; s.Scan(); n++ {
+					line := strings.TrimSpace(s.Text())
+					switch {
+					case extrasHeader.MatchString(line):
+						in = true
+					case in && strings.HasPrefix(line, "//"):
+						if item := strings.TrimSpace(strings.TrimPrefix(line, "//")); item != "" {
+							out = append(out, Extra{Title: item, File: file, Line: n})
+						}
+					default:
+						in = false
+					}
+				}
+				f.Close()
+				if err := s.Err(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// WriteReport 以 Markdown 输出对照结果。
+func (cov *Coverage) WriteReport(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "## 没有示例的章节（%d）\n\n", len(cov.Empty))
+	for _, ch := range cov.Empty {
+		fmt.Fprintf(b, "- %s %s", ch.Name, ch.Title)
+		if len(ch.Assets) > 0 {
+			fmt.Fprintf(b, "（只有 %s）", strings.Join(ch.Assets, "、"))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "\n## 大纲中没有示例的主题（%d）\n\n", len(cov.Missing))
+	for _, p := range cov.Missing {
+		fmt.Fprintf(b, "- %s\n", strings.Join(p, " > "))
+	}
+	fmt.Fprintf(b, "\n## 代码注释中“扩展内容”列出、没有示例的主题（%d）\n\n", len(cov.Extras))
+	for _, e := range cov.Extras {
+		fmt.Fprintf(b, "- %s（%s:%d）\n", e.Title, e.File, e.Line)
+	}
+	fmt.Fprintf(b, "\n## 大纲中找不到的示例（%d）\n\n", len(cov.Unlisted))
+	for _, l := range cov.Unlisted {
+		fmt.Fprintf(b, "- %s %s\n", l.Path(), l.Title)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
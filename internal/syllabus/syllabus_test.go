@@ -0,0 +1,150 @@
+package syllabus
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+)
+
+func load(t *testing.T) (*course.Course, []*Sheet) {
+	t.Helper()
+	c := coursetest.Load(t)
+	sheets, err := Read(filepath.Join(c.Root, "Go语言基础.xmind"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, sheets
+}
+
+func TestRead(t *testing.T) {
+	_, sheets := load(t)
+	if len(sheets) != 1 {
+		t.Fatalf("got %d sheets, want 1", len(sheets))
+	}
+	root := sheets[0].Root
+	if root.Title != "Go语言基础" || len(root.Children) != 7 {
+		t.Fatalf("root = %q with %d children", root.Title, len(root.Children))
+	}
+	if got := root.Children[2].Title; got != "Go基本结构" {
+		t.Errorf("third topic = %q, want Go基本结构", got)
+	}
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, sheets); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Go语言基础\n", "## 并发编程\n", "### 起源与发展\n", "- switch 结构\n", "- 1983年获图领奖\n"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown does not contain %q", want)
+		}
+	}
+}
+
+func TestReadXML(t *testing.T) {
+	const content = `<?xml version="1.0" encoding="UTF-8"?>
+<xmap-content xmlns="urn:xmind:xmap:xmlns:content:2.0">
+  <sheet><title>Sheet 1</title>
+    <topic><title>Go
+语言</title>
+      <children>
+        <topics type="attached">
+          <topic><title>切片</title></topic>
+          <topic><title>map &amp; 通道</title></topic>
+        </topics>
+        <topics type="detached"><topic><title>浮动主题</title></topic></topics>
+      </children>
+    </topic>
+  </sheet>
+</xmap-content>`
+	path := filepath.Join(t.TempDir(), "old.xmind")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("content.xml")
+	w.Write([]byte(content))
+	zw.Close()
+	f.Close()
+
+	sheets, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := sheets[0].Root
+	if sheets[0].Title != "Sheet 1" || root.Title != "Go 语言" || len(root.Children) != 2 || root.Children[1].Title != "map & 通道" {
+		t.Errorf("got sheet %q root %+v", sheets[0].Title, root)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text, kw string
+		want     bool
+	}{
+		{"for 结构", "for", true},
+		{"format 打印", "for", false},
+		{"if-else 结构", "if", true},
+		{"goroutines和chanel", "goroutine", true},
+		{"sync.Cond 用法", "sync.Once", false},
+		{"切片", "切片", true},
+		{"元素为切片的 map", "切片", false},
+	}
+	for _, tt := range tests {
+		if got := mentions(tt.text, tt.kw); got != tt.want {
+			t.Errorf("mentions(%q, %q) = %v, want %v", tt.text, tt.kw, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c, sheets := load(t)
+	cov, err := Check(c, sheets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cov.Empty) != 1 || cov.Empty[0].Name != "c2" {
+		t.Errorf("chapters without lessons = %v, want [c2]", cov.Empty)
+	}
+	missing := map[string]bool{}
+	for _, p := range cov.Missing {
+		missing[strings.Join(p, " > ")] = true
+	}
+	for _, want := range []string{"Go基本结构 > 关键字", "Go基本结构 > 常量", "接口和反射 > 反射"} {
+		if !missing[want] {
+			t.Errorf("%q is not reported as missing", want)
+		}
+	}
+	if missing["内置数据结构和其他 > 数据结构 > 切片"] {
+		t.Errorf("切片 is reported as missing")
+	}
+	if len(cov.Extras) != 9 || !strings.HasPrefix(cov.Extras[0].Title, "互斥锁") {
+		t.Errorf("extras = %+v", cov.Extras)
+	}
+	for _, l := range cov.Unlisted {
+		t.Errorf("%s is not in the outline", l.Path())
+	}
+
+	var slice *Topic
+	var find func(t *Topic)
+	find = func(t *Topic) {
+		if t.Title == "切片" && len(t.Children) > 0 {
+			slice = t
+		}
+		for _, c := range t.Children {
+			find(c)
+		}
+	}
+	find(sheets[0].Root)
+	if slice == nil || !strings.Contains(strings.Join(slice.Lessons, " "), "c3/5.slice/Test_S9") {
+		t.Errorf("切片 topic does not list Test_S9: %+v", slice)
+	}
+}
@@ -0,0 +1,188 @@
+// Package syllabus 读取课程大纲（XMind 思维导图），输出主题树，
+// 并检查大纲中的主题和课程中的示例是否对得上。
+package syllabus
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Sheet 思维导图中的一张画布。
+type Sheet struct {
+	Title string `json:"title"`
+	Root  *Topic `json:"root"`
+}
+
+// Topic 一个主题。
+type Topic struct {
+	Title    string   `json:"title"`
+	Children []*Topic `json:"children,omitempty"`
+	Lessons  []string `json:"lessons,omitempty"` // 讲这个主题的示例，由 Match 填写
+}
+
+// Read 读取 .xmind 文件。新版 XMind 把内容存在 content.json 中，
+// 此时 content.xml 只是提示用新版打开的占位内容；没有 content.json 时才读 content.xml（XMind 8）。
+func Read(path string) ([]*Sheet, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	var sheets []*Sheet
+	switch {
+	case files["content.json"] != nil:
+		err = decode(files["content.json"], func(r io.Reader) error {
+			sheets, err = parseJSON(r)
+			return err
+		})
+	case files["content.xml"] != nil:
+		err = decode(files["content.xml"], func(r io.Reader) error {
+			sheets, err = parseXML(r)
+			return err
+		})
+	default:
+		err = errors.New("no content.json or content.xml")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("syllabus: %s: %v", path, err)
+	}
+	return sheets, nil
+}
+
+func decode(f *zip.File, parse func(r io.Reader) error) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return parse(rc)
+}
+
+type jsonTopic struct {
+	Title    string `json:"title"`
+	Children struct {
+		Attached []*jsonTopic `json:"attached"`
+	} `json:"children"`
+}
+
+func parseJSON(r io.Reader) ([]*Sheet, error) {
+	var doc []struct {
+		Title     string     `json:"title"`
+		RootTopic *jsonTopic `json:"rootTopic"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var sheets []*Sheet
+	for _, s := range doc {
+		if s.RootTopic != nil {
+			sheets = append(sheets, &Sheet{Title: s.Title, Root: s.RootTopic.topic()})
+		}
+	}
+	return sheets, nil
+}
+
+func (t *jsonTopic) topic() *Topic {
+	out := &Topic{Title: cleanTitle(t.Title)}
+	for _, c := range t.Children.Attached {
+		out.Children = append(out.Children, c.topic())
+	}
+	return out
+}
+
+type xmlTopic struct {
+	Title  string `xml:"title"`
+	Groups []struct {
+		Type   string      `xml:"type,attr"`
+		Topics []*xmlTopic `xml:"topic"`
+	} `xml:"children>topics"`
+}
+
+func parseXML(r io.Reader) ([]*Sheet, error) {
+	var doc struct {
+		Sheets []struct {
+			Title string    `xml:"title"`
+			Topic *xmlTopic `xml:"topic"`
+		} `xml:"sheet"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var sheets []*Sheet
+	for _, s := range doc.Sheets {
+		if s.Topic != nil {
+			sheets = append(sheets, &Sheet{Title: s.Title, Root: s.Topic.topic()})
+		}
+	}
+	return sheets, nil
+}
+
+func (t *xmlTopic) topic() *Topic {
+	out := &Topic{Title: cleanTitle(t.Title)}
+	for _, g := range t.Groups {
+		if g.Type != "attached" {
+			continue // detached、summary 等不属于主题树
+		}
+		for _, c := range g.Topics {
+			out.Children = append(out.Children, c.topic())
+		}
+	}
+	return out
+}
+
+// cleanTitle 把主题中的换行和连续空白合并成一个空格，并还原 &amp; 等转义。
+func cleanTitle(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// WriteMarkdown 把主题树写成 Markdown：前三层是标题，更深的是列表。
+// 主题对应的示例所在的包目录写在标题后面。
+func WriteMarkdown(w io.Writer, sheets []*Sheet) error {
+	var err error
+	var write func(t *Topic, depth int)
+	write = func(t *Topic, depth int) {
+		title := t.Title + packages(t)
+		if depth < 3 {
+			_, err = fmt.Fprintf(w, "%s %s\n\n", strings.Repeat("#", depth+1), title)
+		} else {
+			_, err = fmt.Fprintf(w, "%s- %s\n", strings.Repeat("  ", depth-3), title)
+		}
+		for _, c := range t.Children {
+			write(c, depth+1)
+		}
+		if depth == 2 && len(t.Children) > 0 {
+			fmt.Fprintln(w)
+		}
+	}
+	for _, s := range sheets {
+		write(s.Root, 0)
+	}
+	return err
+}
+
+func packages(t *Topic) string {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, id := range t.Lessons {
+		dir := id[:strings.LastIndex(id, "/")]
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, "`"+dir+"`")
+		}
+	}
+	if len(dirs) == 0 {
+		return ""
+	}
+	return "（" + strings.Join(dirs, "、") + "）"
+}
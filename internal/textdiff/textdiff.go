@@ -0,0 +1,212 @@
+// Package textdiff 按行比较两段文本，输出类似 diff -u 的结果（不带行号头）。
+package textdiff
+
+import (
+	"strings"
+)
+
+// Op 一行的比较结果。
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Delete Op = '-'
+	Insert Op = '+'
+)
+
+// Line diff 中的一行。
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines 用最长公共子序列比较 a 和 b 的每一行。
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+	n, m := len(x), len(y)
+
+	// lcs[i][j] 是 x[i:] 和 y[j:] 的最长公共子序列长度
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []Line
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Delete, x[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, Line{Delete, x[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, Line{Insert, y[j]})
+	}
+	return out
+}
+
+// Diff 返回 a 到 b 的差异，a 和 b 相同时返回空字符串。
+func Diff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	var sb strings.Builder
+	for _, l := range Lines(a, b) {
+		sb.WriteByte(byte(l.Op))
+		sb.WriteString(l.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Compact 与 Diff 相同，但只保留改动的行和前后各 context 行不变的行，
+// 省略的部分用一行 " …" 表示。输出很长、只改了几行时使用。
+func Compact(a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	lines := Lines(a, b)
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.Op == Equal {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+	var sb strings.Builder
+	skipped := false
+	for i, l := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			sb.WriteString(" …\n")
+			skipped = false
+		}
+		sb.WriteByte(byte(l.Op))
+		sb.WriteString(l.Text)
+		sb.WriteByte('\n')
+	}
+	if skipped {
+		sb.WriteString(" …\n")
+	}
+	return sb.String()
+}
+
+// SideBySide 把 a 和 b 左右并排输出，类似 sdiff：左边一栏宽 width 个字符，
+// 中间的标记 | 表示这一行不同，< 表示只在左边，> 表示只在右边。
+func SideBySide(a, b string, width int) string {
+	var (
+		sb       strings.Builder
+		del, ins []string
+	)
+	row := func(left, mark, right string) {
+		sb.WriteString(left)
+		if right == "" && mark == " " {
+			sb.WriteString("\n")
+			return
+		}
+		sb.WriteString(strings.Repeat(" ", width-Width(left)+1))
+		sb.WriteString(mark)
+		if right != "" {
+			sb.WriteString(" " + right)
+		}
+		sb.WriteString("\n")
+	}
+	flush := func() {
+		for i := 0; i < len(del) || i < len(ins); i++ {
+			switch {
+			case i >= len(ins):
+				row(clip(del[i], width), "<", "")
+			case i >= len(del):
+				row("", ">", ins[i])
+			default:
+				row(clip(del[i], width), "|", ins[i])
+			}
+		}
+		del, ins = nil, nil
+	}
+	for _, l := range Lines(a, b) {
+		switch l.Op {
+		case Delete:
+			del = append(del, l.Text)
+		case Insert:
+			ins = append(ins, l.Text)
+		default:
+			flush()
+			row(clip(l.Text, width), " ", l.Text)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+// Width 返回 s 在终端中占的列数，中文等全角字符占两列。
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if wide(r) {
+			n++
+		}
+	}
+	return n
+}
+
+func wide(r rune) bool {
+	return r >= 0x1100 && r <= 0x115F || r >= 0x2E80 && r <= 0xA4CF || r >= 0xAC00 && r <= 0xD7A3 ||
+		r >= 0xF900 && r <= 0xFAFF || r >= 0xFE30 && r <= 0xFE4F || r >= 0xFF00 && r <= 0xFF60 || r >= 0xFFE0 && r <= 0xFFE6
+}
+
+// clip 截断 s，使它不超过 width 列。
+func clip(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	n := 0
+	for i, r := range s {
+		w := 1
+		if wide(r) {
+			w = 2
+		}
+		if n+w > width-1 {
+			return s[:i] + "…"
+		}
+		n += w
+	}
+	return s
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
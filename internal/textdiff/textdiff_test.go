@@ -0,0 +1,46 @@
+package textdiff
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nb\nc\n", "a\nc\n", " a\n-b\n c\n"},
+		{"4\n4\n", "4\n3\n", " 4\n-4\n+3\n"},
+		{"", "x\n", "+x\n"},
+	}
+	for _, tt := range tests {
+		if got := Diff(tt.a, tt.b); got != tt.want {
+			t.Errorf("Diff(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n"
+	want := " …\n 4\n-5\n+five\n 6\n …\n"
+	if got := Compact(a, b, 1); got != want {
+		t.Errorf("Compact = %q, want %q", got, want)
+	}
+	if got := Compact(a, a, 1); got != "" {
+		t.Errorf("Compact(a, a) = %q", got)
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	a := "4\n4\n通道\nx\n"
+	b := "4\n3\n通道\n"
+	want := "4      4\n" +
+		"4    | 3\n" +
+		"通道   通道\n" +
+		"x    <\n"
+	if got := SideBySide(a, b, 4); got != want {
+		t.Errorf("SideBySide =\n%s\nwant\n%s", got, want)
+	}
+	if got := clip("abcdef", 4); got != "abc…" {
+		t.Errorf("clip = %q", got)
+	}
+}
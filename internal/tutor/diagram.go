@@ -0,0 +1,183 @@
+package tutor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"study/internal/tutor/trace"
+)
+
+// Diagram 一步的内存图：左边是变量，右边是变量以外的内存（例如 append 重新分配的底层数组），
+// 指针和切片的 ptr 用箭头连到它们指向的格子。
+type Diagram struct {
+	Line   int
+	Output string // 到这一步为止的标准输出
+	Vars   []*Box
+	Heap   []*Box
+	Arrows []*Arrow
+}
+
+// Box 一个变量或者一块内存。
+type Box struct {
+	ID    string
+	Name  string // 变量名，不是变量时为空
+	Type  string
+	Addr  string
+	Cells []*Cell
+}
+
+// Cell 方框中的一格：数组的一个元素、结构体的一个字段、切片的 ptr、len 或 cap，
+// 或者一个普通变量的值。
+type Cell struct {
+	ID      string
+	Label   string // 下标、字段名、ptr、len、cap，普通变量为空
+	Text    string
+	Changed bool // 和上一步相比值变了
+}
+
+// Arrow 从一个格子指向一个方框或格子。
+type Arrow struct {
+	From, To string
+}
+
+// region 一个方框或格子占用的内存，用来确定指针指向哪里。
+type region struct {
+	id         string
+	addr, size uint64
+	typ        string
+}
+
+// Diagrams 把记录的每一步画成内存图。
+func Diagrams(t *trace.Trace) []*Diagram {
+	var out []*Diagram
+	prev := map[string]string{}
+	for _, s := range t.Steps {
+		d := layout(s)
+		if s.Out <= len(t.Output) {
+			d.Output = t.Output[:s.Out]
+		}
+		cur := map[string]string{}
+		for _, boxes := range [][]*Box{d.Vars, d.Heap} {
+			for _, b := range boxes {
+				for _, c := range b.Cells {
+					cur[c.ID] = c.Text
+					if old, ok := prev[c.ID]; ok && old != c.Text {
+						c.Changed = true
+					}
+				}
+			}
+		}
+		prev = cur
+		out = append(out, d)
+	}
+	return out
+}
+
+// pointer 一个还没有连上箭头的指针或切片。
+type pointer struct {
+	from string
+	ptr  uint64
+	elem string // 指向的值的类型
+}
+
+type layoutState struct {
+	regions  []region
+	pointers []pointer
+}
+
+// layout 画出一步的内存图。
+func layout(s *trace.Step) *Diagram {
+	d := &Diagram{Line: s.Line}
+	st := &layoutState{}
+	for _, v := range s.Vars {
+		d.Vars = append(d.Vars, st.box("v-"+v.Name+"-"+hex(v.Addr), v.Name, v))
+	}
+	for _, o := range s.Objects {
+		d.Heap = append(d.Heap, st.box("h-"+hex(o.Addr), "", o))
+	}
+	for _, p := range st.pointers {
+		if to := st.resolve(p); to != "" {
+			d.Arrows = append(d.Arrows, &Arrow{From: p.from, To: to})
+		}
+	}
+	return d
+}
+
+// box 画出值 v 的方框。
+func (st *layoutState) box(id, name string, v *trace.Value) *Box {
+	b := &Box{ID: id, Name: name, Type: v.Type, Addr: hex(v.Addr)}
+	st.regions = append(st.regions, region{id, v.Addr, v.Size, v.Type})
+	switch v.Kind {
+	case "slice":
+		ptr := &Cell{ID: id + "-ptr", Label: "ptr", Text: "nil"}
+		if !v.Nil {
+			ptr.Text = hex(v.Ptr)
+			st.pointers = append(st.pointers, pointer{ptr.ID, v.Ptr, strings.TrimPrefix(v.Type, "[]")})
+		}
+		b.Cells = []*Cell{
+			ptr,
+			{ID: id + "-len", Label: "len", Text: strconv.Itoa(v.Len)},
+			{ID: id + "-cap", Label: "cap", Text: strconv.Itoa(v.Cap)},
+		}
+	case "array", "struct":
+		for i, e := range v.Elems {
+			label := e.Name
+			if v.Kind == "array" {
+				label = strconv.Itoa(i)
+			}
+			b.Cells = append(b.Cells, st.cell(id+"-"+label, label, e))
+		}
+		if v.Kind == "array" && len(v.Elems) < v.Len {
+			b.Cells = append(b.Cells, &Cell{ID: id + "-more", Label: "…", Text: fmt.Sprintf("%d more", v.Len-len(v.Elems))})
+		}
+	default:
+		b.Cells = []*Cell{st.cell(id+"-value", "", v)}
+	}
+	return b
+}
+
+// cell 画出方框中的一格。格子中的切片只显示 len 和 cap，箭头从这一格出发。
+func (st *layoutState) cell(id, label string, v *trace.Value) *Cell {
+	c := &Cell{ID: id, Label: label, Text: v.Text}
+	st.regions = append(st.regions, region{id, v.Addr, v.Size, v.Type})
+	switch v.Kind {
+	case "ptr":
+		c.Text = "nil"
+		if !v.Nil {
+			c.Text = hex(v.Ptr)
+			st.pointers = append(st.pointers, pointer{id, v.Ptr, strings.TrimPrefix(v.Type, "*")})
+		}
+	case "slice":
+		c.Text = "nil"
+		if !v.Nil {
+			c.Text = fmt.Sprintf("len %d cap %d", v.Len, v.Cap)
+			st.pointers = append(st.pointers, pointer{id, v.Ptr, strings.TrimPrefix(v.Type, "[]")})
+		}
+	case "array", "struct":
+		var parts []string
+		for _, e := range v.Elems {
+			parts = append(parts, e.Text)
+		}
+		c.Text = "{" + strings.Join(parts, " ") + "}"
+	}
+	return c
+}
+
+// resolve 返回 p 指向的方框或格子：地址和类型都相同的优先，否则是包含这个地址的最小的一块。
+func (st *layoutState) resolve(p pointer) string {
+	best, size := "", uint64(0)
+	for _, r := range st.regions {
+		if r.addr == p.ptr && r.typ == p.elem {
+			return r.id
+		}
+		if r.addr <= p.ptr && p.ptr < r.addr+r.size && (best == "" || r.size < size) {
+			best, size = r.id, r.size
+		}
+	}
+	return best
+}
+
+func hex(addr uint64) string {
+	return fmt.Sprintf("%#x", addr)
+}
@@ -0,0 +1,50 @@
+package tutor
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/site"
+	"study/internal/tutor/trace"
+)
+
+// FileName 返回示例的时间线页面的文件名，例如 c3_5.slice.Test_S9.html。
+func FileName(l *course.Lesson) string {
+	return strings.ReplaceAll(l.Package.Dir, "/", "_") + "." + l.Name + ".html"
+}
+
+// Line 页面上的一行源码。
+type Line struct {
+	Num  int
+	HTML template.HTML
+}
+
+//go:embed timeline.html
+var templateFS embed.FS
+
+var timelineTemplate = template.Must(template.ParseFS(templateFS, "timeline.html"))
+
+// Write 把 l 的记录 t 写成一个 HTML 页面：左边是示例的源码，右边是每一步的内存图，
+// 可以用按钮、滑块或方向键前后翻看。
+func Write(w io.Writer, root string, l *course.Lesson, t *trace.Trace) error {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(l.File)))
+	if err != nil {
+		return err
+	}
+	src := strings.Split(string(data), "\n")
+	var lines []Line
+	for n := l.Line; n <= l.EndLine && n <= len(src); n++ {
+		lines = append(lines, Line{n, site.Highlight(strings.Replace(src[n-1], "\t", "    ", -1))})
+	}
+	return timelineTemplate.Execute(w, struct {
+		Lesson *course.Lesson
+		Lines  []Line
+		Steps  []*Diagram
+		Trace  *trace.Trace
+	}{l, lines, Diagrams(t), t})
+}
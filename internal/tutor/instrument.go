@@ -0,0 +1,200 @@
+package tutor
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"study/internal/course"
+)
+
+// 插入的 import。包名加了前缀，不会和示例中的名字冲突。
+const (
+	traceName = "tutortrace"
+	tracePath = "study/internal/tutor/trace"
+)
+
+// Instrument 返回改写后的 l 所在的源文件：l 开头 defer trace.Done()，
+// 每条语句执行完后调用 trace.Record，传入这时能访问到的局部变量（*testing.T 除外）。
+// 函数字面量（goroutine、闭包）里面的语句不记录。
+func Instrument(root string, l *course.Lesson) ([]byte, error) {
+	fset := token.NewFileSet()
+	var (
+		files []*ast.File
+		file  *ast.File
+	)
+	for _, name := range l.Package.Files {
+		f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(name)), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+		if name == l.File {
+			file = f
+		}
+	}
+	var fn *ast.FuncDecl
+	if file != nil {
+		for _, d := range file.Decls {
+			if d, ok := d.(*ast.FuncDecl); ok && d.Recv == nil && d.Name.Name == l.Name {
+				fn = d
+			}
+		}
+	}
+	if fn == nil || fn.Body == nil {
+		return nil, fmt.Errorf("tutor: %s has no func %s", l.File, l.Name)
+	}
+
+	// 只需要作用域，课程包引用的其他包找不到时也不影响
+	info := &types.Info{Scopes: map[ast.Node]*types.Scope{}}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	conf.Check(l.Package.Name, fset, files, info)
+
+	in := &instrumenter{fset: fset, info: info, fn: info.Scopes[fn.Type]}
+	if in.fn == nil {
+		return nil, fmt.Errorf("tutor: cannot type-check %s", l.Package.Dir)
+	}
+	body := in.block(fn.Body, fn.Body.List)
+	start := []ast.Stmt{
+		&ast.DeferStmt{Call: call("Done")},
+		in.record(fn.Body, fn.Body.Lbrace, fset.Position(fn.Pos()).Line),
+	}
+	fn.Body.List = append(start, body...)
+
+	imp := &ast.ImportSpec{Name: ast.NewIdent(traceName), Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(tracePath)}}
+	file.Decls = append([]ast.Decl{&ast.GenDecl{Tok: token.IMPORT, Specs: []ast.Spec{imp}}}, file.Decls...)
+
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, fset, file); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+type instrumenter struct {
+	fset *token.FileSet
+	info *types.Info
+	fn   *types.Scope // 函数的作用域，包括参数
+}
+
+// block 改写语句列表 list，node 是列表所在的块（BlockStmt、CaseClause 或 CommClause）。
+func (in *instrumenter) block(node ast.Node, list []ast.Stmt) []ast.Stmt {
+	var out []ast.Stmt
+	for _, s := range list {
+		in.stmt(s)
+		out = append(out, s)
+		if recordable(s) {
+			out = append(out, in.record(node, s.End(), in.fset.Position(s.Pos()).Line))
+		}
+	}
+	return out
+}
+
+// stmt 改写 s 中嵌套的语句列表。
+func (in *instrumenter) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		s.List = in.block(s, s.List)
+	case *ast.LabeledStmt:
+		in.stmt(s.Stmt)
+	case *ast.IfStmt:
+		in.stmt(s.Body)
+		if s.Else != nil {
+			in.stmt(s.Else)
+		}
+	case *ast.ForStmt:
+		in.stmt(s.Body)
+	case *ast.RangeStmt:
+		in.stmt(s.Body)
+	case *ast.SwitchStmt:
+		in.clauses(s.Body)
+	case *ast.TypeSwitchStmt:
+		in.clauses(s.Body)
+	case *ast.SelectStmt:
+		in.clauses(s.Body)
+	}
+}
+
+func (in *instrumenter) clauses(body *ast.BlockStmt) {
+	for _, c := range body.List {
+		switch c := c.(type) {
+		case *ast.CaseClause:
+			c.Body = in.block(c, c.Body)
+		case *ast.CommClause:
+			c.Body = in.block(c, c.Body)
+		}
+	}
+}
+
+// recordable 判断 s 后面能不能插入语句：return、break、continue、goto、fallthrough 之后不能。
+func recordable(s ast.Stmt) bool {
+	switch s := s.(type) {
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return false
+	case *ast.LabeledStmt:
+		return recordable(s.Stmt)
+	}
+	return true
+}
+
+// record 返回记录第 line 行的调用，记录 node 中到 pos 为止已经声明的变量。
+func (in *instrumenter) record(node ast.Node, pos token.Pos, line int) ast.Stmt {
+	args := []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(line)}}
+	for _, v := range in.vars(node, pos) {
+		args = append(args, &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ast.NewIdent(traceName), Sel: ast.NewIdent("V")},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(v.Name())},
+				&ast.UnaryExpr{Op: token.AND, X: ast.NewIdent(v.Name())},
+			},
+		})
+	}
+	c := call("Record")
+	c.Args = args
+	return &ast.ExprStmt{X: c}
+}
+
+// vars 返回 node 中在 pos 之前声明的、能访问到的局部变量，外层的在前，被内层同名变量遮住的不算。
+func (in *instrumenter) vars(node ast.Node, pos token.Pos) []*types.Var {
+	scope := in.info.Scopes[node]
+	if scope == nil {
+		scope = in.fn // 函数体的块没有单独的作用域
+	}
+	var scopes []*types.Scope
+	for s := scope; s != nil && s != in.fn.Parent(); s = s.Parent() {
+		scopes = append(scopes, s)
+	}
+	seen := map[string]bool{}
+	var levels [][]*types.Var
+	for _, s := range scopes {
+		var level []*types.Var
+		for _, name := range s.Names() {
+			v, ok := s.Lookup(name).(*types.Var)
+			if !ok || name == "_" || seen[name] || !v.Pos().IsValid() || v.Pos() >= pos || v.Type().String() == "*testing.T" {
+				continue
+			}
+			seen[name] = true
+			level = append(level, v)
+		}
+		sort.Slice(level, func(i, j int) bool { return level[i].Pos() < level[j].Pos() })
+		levels = append(levels, level)
+	}
+	var out []*types.Var
+	for i := len(levels) - 1; i >= 0; i-- {
+		out = append(out, levels[i]...)
+	}
+	return out
+}
+
+// call 返回调用 trace 包中函数 name 的表达式。
+func call(name string) *ast.CallExpr {
+	return &ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent(traceName), Sel: ast.NewIdent(name)}}
+}
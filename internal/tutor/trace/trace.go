@@ -0,0 +1,261 @@
+// Package trace 是 study tutor 插入到示例中的记录器：示例的每条语句执行完后调用 Record，
+// 记下当时各个局部变量的值和地址、切片的 ptr/len/cap 以及它们指向的数组。
+// 示例结束时（Done）把记录写成 JSON，由 tutor 包画成内存图。
+//
+// 只有设置了环境变量 STUDY_TRACE（记录文件的路径）时才记录。
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Env 指定记录文件路径的环境变量。
+const Env = "STUDY_TRACE"
+
+// 记录的上限，超出时 Trace.Truncated 为 true。
+const (
+	MaxSteps = 500 // 步数，死循环时也能结束
+	MaxElems = 64  // 一个数组最多记录的元素
+	maxDepth = 4   // 指针、结构体嵌套的层数
+	maxText  = 80  // 值的文字最多几个字节
+)
+
+// Trace 一次运行的记录。
+type Trace struct {
+	Steps     []*Step
+	Output    string // 标准输出
+	Panic     string `json:",omitempty"`
+	Truncated bool   `json:",omitempty"`
+}
+
+// Step 一条语句执行完后的状态。
+type Step struct {
+	Line    int      // 刚执行完的语句所在的行
+	Out     int      // 到这时为止标准输出的字节数
+	Vars    []*Value // 能访问到的局部变量，外层的在前
+	Objects []*Value // 不是变量、但被指针或切片指向的内存，例如 append 重新分配的底层数组
+}
+
+// Value 内存中的一个值。
+type Value struct {
+	Name  string `json:",omitempty"` // 变量名、结构体的字段名，数组元素为空
+	Type  string
+	Kind  string   // reflect.Kind，例如 int、ptr、slice、array、struct
+	Addr  uint64   // 值所在的地址
+	Size  uint64   // 占用的字节数
+	Text  string   `json:",omitempty"` // 不是指针、切片、数组、结构体时的值
+	Ptr   uint64   `json:",omitempty"` // 指针的值，切片的 ptr
+	Len   int      `json:",omitempty"` // 切片、数组的长度，数组只记录前 MaxElems 个元素
+	Cap   int      `json:",omitempty"`
+	Elems []*Value `json:",omitempty"` // 数组的元素、结构体的字段；切片指向的数组是 Objects 中的一项
+	Nil   bool     `json:",omitempty"`
+}
+
+// Var 一个要记录的变量。
+type Var struct {
+	Name string
+	Ptr  interface{} // 指向变量的指针
+}
+
+// V 返回名为 name 的变量，p 是它的地址。
+func V(name string, p interface{}) Var {
+	return Var{name, p}
+}
+
+var (
+	mu     sync.Mutex
+	tr     *Trace
+	path   string
+	stdout *os.File // 真正的标准输出
+	out    *os.File // 记录期间代替 os.Stdout 的临时文件
+)
+
+func init() {
+	if path = os.Getenv(Env); path == "" {
+		return
+	}
+	f, err := os.CreateTemp("", "study-trace-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trace: %v\n", err)
+		path = ""
+		return
+	}
+	os.Remove(f.Name())
+	tr = &Trace{}
+	stdout, out = os.Stdout, f
+	os.Stdout = f
+}
+
+// Record 记录第 line 行的语句执行完后 vars 的状态。
+func Record(line int, vars ...Var) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tr == nil || tr.Truncated {
+		return
+	}
+	if len(tr.Steps) == MaxSteps {
+		tr.Truncated = true
+		return
+	}
+	n, _ := out.Seek(0, io.SeekCurrent)
+	r := &recorder{step: &Step{Line: line, Out: int(n)}}
+	for _, v := range vars {
+		r.step.Vars = append(r.step.Vars, r.value(v.Name, reflect.ValueOf(v.Ptr).Elem(), 0))
+	}
+	r.pointees()
+	tr.Steps = append(tr.Steps, r.step)
+}
+
+// Done 在示例结束时调用（示例开头的 defer），写出记录并把输出还给标准输出。
+// 示例 panic 时记下 panic 的值，再继续 panic。
+func Done() {
+	p := recover()
+	mu.Lock()
+	if tr != nil {
+		if p != nil {
+			tr.Panic = fmt.Sprint(p)
+		}
+		out.Seek(0, io.SeekStart)
+		data, _ := io.ReadAll(out)
+		tr.Output = string(data)
+		stdout.Write(data)
+		os.Stdout = stdout
+		if err := write(tr); err != nil {
+			fmt.Fprintf(os.Stderr, "trace: %v\n", err)
+		}
+		tr = nil
+	}
+	mu.Unlock()
+	if p != nil {
+		panic(p)
+	}
+}
+
+func write(t *Trace) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// recorder 记录一步。
+type recorder struct {
+	step    *Step
+	objects []*Value
+	pending []reflect.Value // 还没有确定指向哪里的指针和切片
+}
+
+// value 记录可寻址的值 v。
+func (r *recorder) value(name string, v reflect.Value, depth int) *Value {
+	t := v.Type()
+	x := &Value{Name: name, Type: t.String(), Kind: v.Kind().String(), Size: uint64(t.Size())}
+	if v.CanAddr() {
+		x.Addr = uint64(v.UnsafeAddr())
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			x.Nil = true
+			break
+		}
+		x.Ptr = uint64(v.Pointer())
+		if depth < maxDepth {
+			r.pending = append(r.pending, v)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			x.Nil = true
+			break
+		}
+		x.Ptr, x.Len, x.Cap = uint64(v.Pointer()), v.Len(), v.Cap()
+		if depth < maxDepth && v.Cap() > 0 && t.Elem().Size() > 0 {
+			r.pending = append(r.pending, v)
+		}
+	case reflect.Array:
+		x.Len = v.Len()
+		for i := 0; i < v.Len() && i < MaxElems && depth < maxDepth; i++ {
+			x.Elems = append(x.Elems, r.value("", v.Index(i), depth+1))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField() && depth < maxDepth; i++ {
+			x.Elems = append(x.Elems, r.value(t.Field(i).Name, v.Field(i), depth+1))
+		}
+	case reflect.String:
+		x.Text = clip(fmt.Sprintf("%q", v))
+	default:
+		x.Text = clip(fmt.Sprint(v))
+	}
+	return x
+}
+
+// pointees 记录指针和切片指向的、不在已记录的内存中的值，然后按地址排列 Objects。
+// 地址小的先记录，这样共用底层数组的几个切片只记录从最前面开始的那一段。
+func (r *recorder) pointees() {
+	for len(r.pending) > 0 {
+		sort.SliceStable(r.pending, func(i, j int) bool {
+			a, b := r.pending[i], r.pending[j]
+			if a.Pointer() != b.Pointer() {
+				return a.Pointer() < b.Pointer()
+			}
+			return a.Kind() == reflect.Slice && (b.Kind() != reflect.Slice || a.Cap() > b.Cap())
+		})
+		v := r.pending[0]
+		r.pending = r.pending[1:]
+		addr := uint64(v.Pointer())
+		if v.Kind() == reflect.Ptr {
+			if !r.contains(addr, addr) {
+				r.objects = append(r.objects, r.value("", v.Elem(), 1))
+			}
+			continue
+		}
+		size := uint64(v.Type().Elem().Size())
+		if r.contains(addr, addr+uint64(v.Cap())*size-1) {
+			continue
+		}
+		// 切片可以一直访问到 cap，从 ptr 开始的 cap 个元素就是它能看到的底层数组
+		full := v.Slice(0, v.Cap())
+		arr := &Value{
+			Type: fmt.Sprintf("[%d]%s", v.Cap(), v.Type().Elem()),
+			Kind: reflect.Array.String(),
+			Addr: addr,
+			Size: uint64(v.Cap()) * size,
+			Len:  v.Cap(),
+		}
+		for i := 0; i < v.Cap() && i < MaxElems; i++ {
+			arr.Elems = append(arr.Elems, r.value("", full.Index(i), 1))
+		}
+		r.objects = append(r.objects, arr)
+	}
+	r.step.Objects = r.objects
+	sort.SliceStable(r.step.Objects, func(i, j int) bool { return r.step.Objects[i].Addr < r.step.Objects[j].Addr })
+}
+
+// contains 判断 first 到 last 的内存是否都在已经记录的某个值中。
+func (r *recorder) contains(first, last uint64) bool {
+	in := func(v *Value) bool { return v.Addr <= first && (last < v.Addr+v.Size || last == v.Addr) }
+	for _, v := range r.step.Vars {
+		if in(v) {
+			return true
+		}
+	}
+	for _, v := range r.objects {
+		if in(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func clip(s string) string {
+	if len(s) > maxText {
+		return s[:maxText] + "…"
+	}
+	return s
+}
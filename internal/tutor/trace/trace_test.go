@@ -0,0 +1,74 @@
+package trace
+
+import (
+	"reflect"
+	"testing"
+)
+
+// record 像 Record 一样记录一步，但不需要 STUDY_TRACE。
+func record(vars ...Var) *Step {
+	r := &recorder{step: &Step{}}
+	for _, v := range vars {
+		r.step.Vars = append(r.step.Vars, r.value(v.Name, reflect.ValueOf(v.Ptr).Elem(), 0))
+	}
+	r.pointees()
+	return r.step
+}
+
+func TestRecord(t *testing.T) {
+	arr := [4]int{1, 2, 3, 4}
+	s := arr[1:3]
+	heap := make([]int, 2, 5)
+	tail := heap[1:4]
+	n := new(int)
+	*n = 7
+	var none []int
+	step := record(V("arr", &arr), V("s", &s), V("tail", &tail), V("heap", &heap), V("n", &n), V("none", &none))
+
+	vs := step.Vars
+	if vs[0].Kind != "array" || len(vs[0].Elems) != 4 || vs[0].Elems[3].Text != "4" {
+		t.Errorf("arr = %+v", vs[0])
+	}
+	// 指向数组变量中间的切片不另外记录底层数组
+	if vs[1].Ptr != vs[0].Elems[1].Addr || vs[1].Len != 2 || vs[1].Cap != 3 {
+		t.Errorf("s = %+v, want ptr %#x", vs[1], vs[0].Elems[1].Addr)
+	}
+	if !vs[5].Nil {
+		t.Errorf("none = %+v", vs[5])
+	}
+	// heap 和 tail 共用一个底层数组，只记录从 heap 开始的 5 个元素；new(int) 另记一个
+	if len(step.Objects) != 2 {
+		t.Fatalf("objects = %+v", step.Objects)
+	}
+	var backing, pointee *Value
+	for _, o := range step.Objects {
+		switch o.Addr {
+		case vs[3].Ptr:
+			backing = o
+		case vs[4].Ptr:
+			pointee = o
+		}
+	}
+	if backing == nil || backing.Type != "[5]int" || len(backing.Elems) != 5 || vs[2].Ptr != backing.Elems[1].Addr {
+		t.Errorf("backing array = %+v", backing)
+	}
+	if pointee == nil || pointee.Text != "7" {
+		t.Errorf("*n = %+v", pointee)
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type point struct {
+		x, y int
+		name string
+	}
+	p := point{1, 2, "p"}
+	q := &p
+	step := record(V("p", &p), V("q", &q))
+	if f := step.Vars[0].Elems; len(f) != 3 || f[1].Name != "y" || f[1].Text != "2" || f[2].Text != `"p"` {
+		t.Errorf("fields = %+v", f)
+	}
+	if step.Vars[1].Ptr != step.Vars[0].Addr || len(step.Objects) != 0 {
+		t.Errorf("q = %+v, objects %+v", step.Vars[1], step.Objects)
+	}
+}
@@ -0,0 +1,57 @@
+// Package tutor 一步一步地展示示例运行时内存的变化（类似 Python Tutor）：
+// 改写示例的语法树，在每条语句后面记录局部变量的值和地址、切片的 ptr/len/cap
+// 以及它们指向的数组（见 trace 包），再把记录画成可以前后翻看的方框和箭头图。
+package tutor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"study/internal/course"
+	"study/internal/runner"
+	"study/internal/tutor/trace"
+)
+
+// Run 编译并运行改写后的 l，返回记录和运行结果。
+func Run(ctx context.Context, root string, l *course.Lesson, opt runner.Options) (*trace.Trace, *runner.Result, error) {
+	if l.Name == course.MainLesson {
+		return nil, nil, fmt.Errorf("tutor: %s is a main program, only Test lessons can be traced", l.Package.Dir)
+	}
+	src, err := Instrument(root, l)
+	if err != nil {
+		return nil, nil, err
+	}
+	bin, cleanup, err := runner.BuildWith(ctx, root, l.Package.Dir, false, map[string][]byte{l.File: src})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	f, err := os.CreateTemp("", "study-trace-*.json")
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	opt.Env = append(append([]string(nil), opt.Env...), trace.Env+"="+f.Name())
+	res, err := runner.Exec(ctx, filepath.Join(root, l.Package.Dir), bin, runner.Args(l.Name), opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) == 0 {
+		return nil, res, fmt.Errorf("tutor: %s did not finish (exit status %d, timed out %v)\n%s", l.Path(), res.ExitCode, res.TimedOut, res.Stderr)
+	}
+	var t trace.Trace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, res, fmt.Errorf("tutor: bad trace: %v", err)
+	}
+	return &t, res, nil
+}
@@ -0,0 +1,142 @@
+package tutor
+
+import (
+	"bytes"
+	"context"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+	"study/internal/runner"
+	"study/internal/tutor/trace"
+)
+
+func TestInstrument(t *testing.T) {
+	c := coursetest.Load(t)
+	p8, err := c.Lesson("c3/3.pointer", "TestP8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Instrument(c.Root, p8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0); err != nil {
+		t.Fatalf("instrumented source does not parse: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"defer tutortrace.Done()",
+		"tutortrace.Record(129)\n", // 开始时，t 不记录
+		`tutortrace.Record(132, tutortrace.V("a", &a), tutortrace.V("ptr", &ptr))`,
+		`tutortrace.Record(141, tutortrace.V("a", &a), tutortrace.V("ptr", &ptr), tutortrace.V("pptr", &pptr))`,
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("instrumented TestP8 has no %s", want)
+		}
+	}
+	if bytes.Count(src, []byte("tutortrace.Done()")) != 1 {
+		t.Error("other lessons in the file are instrumented too")
+	}
+
+	// continue 后面不能插入语句；内层循环的 j 只在循环里面记录
+	c5, err := c.Lesson("c3/2.control", "TestC5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src, err = Instrument(c.Root, c5); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(src), "continue LABEL1\n\t\t\t\ttutortrace.Record") {
+		t.Error("Record inserted after continue")
+	}
+	if !strings.Contains(string(src), `tutortrace.Record(236, tutortrace.V("i", &i), tutortrace.V("j", &j))`) ||
+		!strings.Contains(string(src), `tutortrace.Record(235, tutortrace.V("i", &i))`) {
+		t.Errorf("loop variables not recorded in their scopes:\n%s", src)
+	}
+}
+
+func TestDiagrams(t *testing.T) {
+	v := func(name, typ, kind string, addr, size uint64, text string) *trace.Value {
+		return &trace.Value{Name: name, Type: typ, Kind: kind, Addr: addr, Size: size, Text: text}
+	}
+	arr := func(n int) *trace.Value {
+		a := v("data", "[3]int", "array", 0x100, 24, "")
+		a.Len = 3
+		for i := 0; i < 3; i++ {
+			a.Elems = append(a.Elems, v("", "int", "int", 0x100+uint64(i)*8, 8, string(rune('0'+n+i))))
+		}
+		return a
+	}
+	s := v("s", "[]int", "slice", 0x200, 24, "")
+	s.Ptr, s.Len, s.Cap = 0x108, 1, 2
+	p := v("p", "*[3]int", "ptr", 0x220, 8, "")
+	p.Ptr = 0x100
+	tr := &trace.Trace{
+		Output: "ab",
+		Steps: []*trace.Step{
+			{Line: 1, Out: 1, Vars: []*trace.Value{arr(0), s, p}},
+			{Line: 2, Out: 2, Vars: []*trace.Value{arr(1), s, p}},
+		},
+	}
+	ds := Diagrams(tr)
+	if len(ds) != 2 || ds[0].Output != "a" || ds[1].Output != "ab" {
+		t.Fatalf("diagrams = %+v", ds)
+	}
+	d := ds[1]
+	// 切片的 ptr 指向 data[1]，指向整个数组的指针指向方框
+	want := []Arrow{
+		{From: "v-s-0x200-ptr", To: "v-data-0x100-1"},
+		{From: "v-p-0x220-value", To: "v-data-0x100"},
+	}
+	if len(d.Arrows) != len(want) || *d.Arrows[0] != want[0] || *d.Arrows[1] != want[1] {
+		t.Errorf("arrows = %+v %+v", d.Arrows[0], d.Arrows[1])
+	}
+	if cells := d.Vars[0].Cells; !cells[0].Changed || cells[0].Text != "1" || len(d.Vars[1].Cells) != 3 || d.Vars[1].Cells[0].Changed {
+		t.Errorf("cells = %+v, slice %+v", cells, d.Vars[1].Cells)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the lesson package")
+	}
+	c := coursetest.Load(t)
+	l, err := c.Lesson("c3/5.slice", "Test_S4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, res, err := Run(context.Background(), c.Root, l, runner.Options{Timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Stdout) != "[102 203]\n[0 1 102 203 4 5]\n" || tr.Output != string(res.Stdout) {
+		t.Errorf("stdout %q, trace output %q", res.Stdout, tr.Output)
+	}
+	ds := Diagrams(tr)
+	last := ds[len(ds)-1]
+	if last.Line != l.EndLine-1 || len(last.Vars) != 2 || len(last.Heap) != 0 {
+		t.Fatalf("last step = %+v", last)
+	}
+	data, s := last.Vars[0], last.Vars[1]
+	var texts []string
+	for _, c := range data.Cells {
+		texts = append(texts, c.Text)
+	}
+	// s := data[2:4] 指向 data 中的格子，s[0] += 100 改的就是 data[2]
+	if strings.Join(texts, " ") != "0 1 102 203 4 5" || len(last.Arrows) != 1 ||
+		last.Arrows[0].From != s.Cells[0].ID || last.Arrows[0].To != data.Cells[2].ID {
+		t.Errorf("data = %v, arrows %+v", texts, last.Arrows)
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, c.Root, l, tr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `id="L93"`) || !strings.Contains(b.String(), data.Cells[2].ID) {
+		t.Error("page is missing the source lines or the diagrams")
+	}
+}
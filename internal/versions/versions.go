@@ -0,0 +1,390 @@
+// Package versions 比较示例在不同 Go 语言版本下的行为。
+//
+// go.mod 中的 go 指令决定语言版本：go 1.22 起 for 循环的变量每次迭代都是新的变量，
+// 之前整个循环共用一个变量，闭包在循环结束后才读取时看到的都是最后一个值（例如 TestF8）。
+// Check 不运行就找出可能受影响的示例；Compare 把课程包复制到 go 指令不同的临时模块中，
+// 用本地的工具链分别运行，比较输出。编译时设置 GOTOOLCHAIN=local，go 指令比本地工具链新时
+// 直接报错（见 Supported），不会去下载别的工具链。
+package versions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"study/internal/course"
+	"study/internal/runner"
+)
+
+// Loopvar 循环变量改为每次迭代一个新变量的语言版本。
+const Loopvar = "1.22"
+
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\S+)\s*$`)
+
+// GoVersion 返回 root 下 go.mod 中 go 指令的版本，例如 1.17。
+func GoVersion(root string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	m := goDirective.FindSubmatch(data)
+	if m == nil {
+		return "", fmt.Errorf("versions: go.mod has no go directive")
+	}
+	return string(m[1]), nil
+}
+
+var versionPattern = regexp.MustCompile(`^1\.\d+(\.\d+)?$`)
+
+// Supported 检查能否用本地的工具链编译 go 指令为 version 的模块：version 必须形如 1.22，
+// 且不比 runtime.Version() 新。
+func Supported(version string) error {
+	if !versionPattern.MatchString(version) {
+		return fmt.Errorf("versions: bad go version %q, want a version like %s", version, Loopvar)
+	}
+	local := runtime.Version()
+	if strings.HasPrefix(local, "go1.") && compareVersions(version, strings.TrimPrefix(local, "go")) > 0 {
+		return fmt.Errorf("versions: go %s is newer than the local toolchain %s", version, local)
+	}
+	return nil
+}
+
+// compareVersions 比较 1.22.3 形式的版本号，忽略 rc1 这样的后缀。
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n := 0
+		for _, r := range s {
+			if r < '0' || r > '9' {
+				break
+			}
+			n = n*10 + int(r-'0')
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// Finding 示例中行为取决于语言版本的一处代码。
+type Finding struct {
+	Lesson *course.Lesson
+	File   string // 相对仓库根目录
+	Line   int
+	Reason string // closure captures loop variable i (line 110)
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Reason)
+}
+
+// Check 找出 p 中闭包捕获或者取地址的循环变量。示例直接调用的函数中的也算在示例上。
+// 这样的示例不一定真的输出不同（例如闭包在同一次迭代中就执行完了），用 Compare 确认。
+func Check(root string, p *course.Package) ([]*Finding, error) {
+	fset := token.NewFileSet()
+	files := map[string]*ast.File{}
+	var list []*ast.File
+	for _, name := range p.Files {
+		f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(name)), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = f
+		list = append(list, f)
+	}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}, Uses: map[*ast.Ident]types.Object{}}
+	// 只需要找到标识符对应的变量，导入失败等错误不影响
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	conf.Check(p.Name, fset, list, info)
+
+	funcs := map[string]*ast.FuncDecl{}
+	for _, f := range list {
+		for _, d := range f.Decls {
+			if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Body != nil {
+				funcs[fn.Name.Name] = fn
+			}
+		}
+	}
+	var out []*Finding
+	for _, l := range p.Lessons {
+		fn := funcs[l.Name]
+		if files[l.File] == nil || fn == nil {
+			continue
+		}
+		seen := map[*ast.FuncDecl]bool{fn: true}
+		decls := []*ast.FuncDecl{fn}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if id, ok := call.Fun.(*ast.Ident); ok && funcs[id.Name] != nil && !seen[funcs[id.Name]] {
+					seen[funcs[id.Name]] = true
+					decls = append(decls, funcs[id.Name])
+				}
+			}
+			return true
+		})
+		for _, d := range decls {
+			for _, f := range loopVars(fset, info, d.Body) {
+				f.Lesson = l
+				f.File = relPath(root, f.File)
+				out = append(out, f)
+			}
+		}
+	}
+	return out, nil
+}
+
+func relPath(root, name string) string {
+	if rel, err := filepath.Rel(root, name); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return name
+}
+
+// loopVars 返回 body 中被闭包捕获或者被取地址的循环变量，每个循环变量只报告一次。
+// 闭包捕获的变量在迭代结束后才读取时，两种语义的结果才会不同。
+func loopVars(fset *token.FileSet, info *types.Info, body *ast.BlockStmt) []*Finding {
+	var out []*Finding
+	ast.Inspect(body, func(n ast.Node) bool {
+		var (
+			idents []ast.Expr
+			loop   *ast.BlockStmt
+		)
+		switch n := n.(type) {
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				idents, loop = []ast.Expr{n.Key, n.Value}, n.Body
+			}
+		case *ast.ForStmt:
+			if as, ok := n.Init.(*ast.AssignStmt); ok && as.Tok == token.DEFINE {
+				idents, loop = as.Lhs, n.Body
+			}
+		}
+		vars := map[types.Object]bool{}
+		for _, e := range idents {
+			if id, ok := e.(*ast.Ident); ok && id.Name != "_" && info.Defs[id] != nil {
+				vars[info.Defs[id]] = true
+			}
+		}
+		if len(vars) == 0 {
+			return true
+		}
+		loopLine := fset.Position(n.Pos()).Line
+		reported := map[types.Object]bool{}
+		report := func(at token.Pos, obj types.Object, what string) {
+			if reported[obj] {
+				return
+			}
+			reported[obj] = true
+			pos := fset.Position(at)
+			out = append(out, &Finding{File: pos.Filename, Line: pos.Line,
+				Reason: fmt.Sprintf("%s loop variable %s (loop at line %d)", what, obj.Name(), loopLine)})
+		}
+		// 直接调用的函数字面量在本次迭代中就执行完了，不算捕获；go 和 defer 的除外
+		immediate := map[*ast.FuncLit]bool{}
+		ast.Inspect(loop, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GoStmt, *ast.DeferStmt:
+				return false
+			case *ast.CallExpr:
+				if lit, ok := n.Fun.(*ast.FuncLit); ok {
+					immediate[lit] = true
+				}
+			}
+			return true
+		})
+		ast.Inspect(loop, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				if immediate[n] {
+					return true
+				}
+				ast.Inspect(n.Body, func(m ast.Node) bool {
+					if id, ok := m.(*ast.Ident); ok && vars[info.Uses[id]] {
+						report(n.Pos(), info.Uses[id], "closure captures")
+					}
+					return true
+				})
+				return false
+			case *ast.UnaryExpr:
+				if id, ok := n.X.(*ast.Ident); ok && n.Op == token.AND && vars[info.Uses[id]] {
+					report(n.Pos(), info.Uses[id], "takes the address of")
+				}
+			}
+			return true
+		})
+		return true
+	})
+	return out
+}
+
+// Module 把课程包 p 和它依赖的仓库中的包复制到一个临时模块中，go.mod 的 go 指令为 version。
+// 返回临时模块的根目录，模块路径和包的目录与仓库相同。
+func Module(ctx context.Context, root string, p *course.Package, version string) (dir string, cleanup func(), err error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-deps", "-test", "-f", `{{if not .Standard}}{{.ImportPath}}{{"\t"}}{{.Dir}}{{end}}`, "./"+p.Dir)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("versions: go list %s: %v\n%s", p.Dir, err, stderr.Bytes())
+	}
+	dirs := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) != 2 || strings.Contains(f[0], " ") || strings.HasSuffix(f[0], ".test") {
+			continue // 测试用的变体
+		}
+		if rel, err := filepath.Rel(root, f[1]); err == nil && !strings.HasPrefix(rel, "..") {
+			dirs[rel] = true
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "study-versions-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+	mod := fmt.Sprintf("module study\n\ngo %s\n", version)
+	if err := os.WriteFile(filepath.Join(tmp, "go.mod"), []byte(mod), 0o644); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	pkgDir := filepath.FromSlash(p.Dir)
+	for rel := range dirs {
+		// 课程包的目录整个复制，示例可能读取其中的 1.txt、testdata 等文件
+		if err := copyDir(filepath.Join(root, rel), filepath.Join(tmp, rel), rel == pkgDir); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	return tmp, cleanup, nil
+}
+
+// copyDir 复制 src 中的文件，all 为 false 时只复制 .go 文件、不进入子目录。
+func copyDir(src, dst string, all bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			if path != src && !all {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if !all && filepath.Ext(path) != ".go" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+}
+
+// Comparison 一个示例在两个语言版本下的运行结果。
+type Comparison struct {
+	Lesson   *course.Lesson
+	Old, New *runner.Result
+}
+
+// Differs 两次运行的输出或退出状态是否不同。
+func (c *Comparison) Differs() bool {
+	return !bytes.Equal(c.Old.Stdout, c.New.Stdout) || !bytes.Equal(c.Old.Stderr, c.New.Stderr) ||
+		c.Old.ExitCode != c.New.ExitCode || c.Old.TimedOut != c.New.TimedOut
+}
+
+// Compare 分别在 go 指令为 oldVersion 和 newVersion 的临时模块中编译课程包 p，运行 lessons。
+func Compare(ctx context.Context, root string, p *course.Package, lessons []*course.Lesson, oldVersion, newVersion string, opt runner.Options) ([]*Comparison, error) {
+	for _, v := range []string{oldVersion, newVersion} {
+		if err := Supported(v); err != nil {
+			return nil, err
+		}
+	}
+	cs := make([]*Comparison, len(lessons))
+	for i, l := range lessons {
+		cs[i] = &Comparison{Lesson: l}
+	}
+	for _, v := range []string{oldVersion, newVersion} {
+		results, err := runAll(ctx, root, p, lessons, v, opt)
+		if err != nil {
+			return nil, err
+		}
+		for i, res := range results {
+			if v == oldVersion {
+				cs[i].Old = res
+			} else {
+				cs[i].New = res
+			}
+		}
+	}
+	return cs, nil
+}
+
+func runAll(ctx context.Context, root string, p *course.Package, lessons []*course.Lesson, version string, opt runner.Options) ([]*runner.Result, error) {
+	mod, cleanup, err := Module(ctx, root, p, version)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	main := len(lessons) > 0 && lessons[0].Name == course.MainLesson
+	bin, cleanBin, err := runner.Build(ctx, mod, p.Dir, main)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanBin()
+	results := make([]*runner.Result, len(lessons))
+	for i, l := range lessons {
+		if results[i], err = runner.Exec(ctx, filepath.Join(mod, filepath.FromSlash(p.Dir)), bin, runner.Args(l.Name), opt); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Lessons 返回 findings 中涉及的示例，不重复。
+func Lessons(findings []*Finding) []*course.Lesson {
+	seen := map[*course.Lesson]bool{}
+	var ls []*course.Lesson
+	for _, f := range findings {
+		if !seen[f.Lesson] {
+			seen[f.Lesson] = true
+			ls = append(ls, f.Lesson)
+		}
+	}
+	return ls
+}
@@ -0,0 +1,139 @@
+package versions
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+	"time"
+
+	"study/internal/course"
+	"study/internal/course/coursetest"
+	_ "study/internal/lesson/all"
+	"study/internal/runner"
+)
+
+func TestLoopVars(t *testing.T) {
+	src := `package p
+
+func f(xs []int) {
+	var fs []func()
+	for i, x := range xs {
+		fs = append(fs, func() { println(i) })
+		_ = x
+	}
+	for i := 0; i < 3; i++ {
+		p := &i
+		_ = p
+	}
+	for _, x := range xs {
+		func() {
+			for j := 0; j < x; j++ {
+				println(j)
+			}
+		}()
+		y := x
+		fs = append(fs, func() { println(y) })
+	}
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}, Uses: map[*ast.Ident]types.Object{}}
+	if _, err := (&types.Config{}).Check("p", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range loopVars(fset, info, f.Decls[0].(*ast.FuncDecl).Body) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"p.go:6: closure captures loop variable i (loop at line 5)",
+		"p.go:10: takes the address of loop variable i (loop at line 9)",
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("loopVars = %q\nwant %q", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := coursetest.Load(t)
+	if v, err := GoVersion(c.Root); err != nil || v != "1.17" {
+		t.Errorf("GoVersion = %q, %v", v, err)
+	}
+	var flagged []string
+	for _, l := range c.Lessons() {
+		findings, err := Check(c.Root, l.Package)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range findings {
+			if f.Lesson == l {
+				flagged = append(flagged, f.Lesson.Path()+" "+f.String())
+			}
+		}
+	}
+	// c6/2.channel 的 TestC5 中的循环变量都声明在 goroutine 里面，没有被闭包捕获，
+	// 两个版本的输出相同（见 TestCompare）
+	want := "c4/1.function/TestF8 c4/1.function/func_test.go:112: closure captures loop variable i (loop at line 110)"
+	if len(flagged) != 1 || flagged[0] != want {
+		t.Errorf("flagged %q, want only %q", flagged, want)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		ok      bool
+	}{
+		{"1.17", true},
+		{Loopvar, true},
+		{"1.22.0", true},
+		{"1.999", false},
+		{"2.0", false},
+		{"go1.22", false},
+		{"latest", false},
+	} {
+		if err := Supported(tt.version); (err == nil) != tt.ok {
+			t.Errorf("Supported(%q) = %v, want ok %v", tt.version, err, tt.ok)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds two temporary modules")
+	}
+	c := coursetest.Load(t)
+	ctx := context.Background()
+	opt := runner.Options{Timeout: time.Minute}
+
+	f8, err := c.Lesson("c4/1.function", "TestF8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := Compare(ctx, c.Root, f8.Package, []*course.Lesson{f8}, "1.17", Loopvar, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old, new := string(cs[0].Old.Stdout), string(cs[0].New.Stdout); old != "4\n4\n4\n4\n4\n4\n3\n2\n1\n0\n" || new != "4\n3\n2\n1\n0\n4\n3\n2\n1\n0\n" {
+		t.Errorf("TestF8 output:\ngo 1.17:\n%s\ngo %s:\n%s", old, Loopvar, new)
+	}
+
+	c5, err := c.Lesson("c6/2.channel", "TestC5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err = Compare(ctx, c.Root, c5.Package, []*course.Lesson{c5}, "1.17", Loopvar, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs[0].Differs() || len(cs[0].Old.Stdout) == 0 {
+		t.Errorf("TestC5 output differs:\n%s\n%s", cs[0].Old.Stdout, cs[0].New.Stdout)
+	}
+}
@@ -0,0 +1,86 @@
+// Package watch 定时检查目录中文件的修改时间和大小来发现改动，
+// 不依赖操作系统的文件通知，也不需要第三方库。
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot 某一时刻目录中各文件的状态，键是文件路径。
+type Snapshot map[string]stamp
+
+type stamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Scan 记录 dirs 下（不含子目录）的普通文件。隐藏文件和编辑器的临时文件
+// （.x.swp、x~、#x#）不算。
+func Scan(dirs ...string) (Snapshot, error) {
+	s := Snapshot{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || strings.HasPrefix(name, "#") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue // 读目录之后被删除了
+			}
+			s[filepath.Join(dir, name)] = stamp{info.Size(), info.ModTime()}
+		}
+	}
+	return s, nil
+}
+
+// Changed 返回 s 到 t 之间新增、删除或修改过的文件，按名字排序。
+func (s Snapshot) Changed(t Snapshot) []string {
+	var names []string
+	for name, st := range t {
+		if old, ok := s[name]; !ok || old.size != st.size || !old.modTime.Equal(st.modTime) {
+			names = append(names, name)
+		}
+	}
+	for name := range s {
+		if _, ok := t[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Wait 每隔 interval 检查一次 dirs，直到和 prev 相比有文件改动，返回新的状态和改动的文件。
+// 编辑器保存时可能分几步写文件，发现改动后要等到连续两次检查结果相同才返回。
+func Wait(ctx context.Context, dirs []string, prev Snapshot, interval time.Duration) (Snapshot, []string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var changed Snapshot
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+		cur, err := Scan(dirs...)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case changed != nil && len(changed.Changed(cur)) == 0:
+			return cur, prev.Changed(cur), nil
+		case changed != nil || len(prev.Changed(cur)) > 0:
+			changed = cur
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a_test.go")
+	if err := os.WriteFile(a, []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prev, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		os.WriteFile(filepath.Join(dir, ".a_test.go.swp"), []byte("x"), 0o644)
+		os.WriteFile(a, []byte("package a\n\n// 改动\n"), 0o644)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cur, changed, err := Wait(ctx, []string{dir}, prev, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{a}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+
+	// 没有改动时一直等到 ctx 结束
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := Wait(ctx, []string{dir}, cur, 10*time.Millisecond); err != context.DeadlineExceeded {
+		t.Errorf("Wait without changes = %v, want %v", err, context.DeadlineExceeded)
+	}
+}